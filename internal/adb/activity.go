@@ -0,0 +1,25 @@
+package adb
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// resumedActivityRegex matches the resumed activity in dumpsys activity output,
+// e.g. "mResumedActivity: ActivityRecord{e3f1a2 u0 com.example/.MainActivity t123}"
+// or, on newer releases, "topResumedActivity=ActivityRecord{...}"
+var resumedActivityRegex = regexp.MustCompile(`ResumedActivity[:=] ?ActivityRecord\{\S+ u\d+ ([^\s}]+)`)
+
+// ResumedActivity returns the component of the activity in the foreground,
+// or "" when no activity is resumed, e.g. while the screen is off
+func ResumedActivity(deviceSerial string) (string, error) {
+	output, err := Command(shellArgs(deviceSerial, "shell", "dumpsys", "activity", "activities")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read resumed activity: %w", err)
+	}
+	match := resumedActivityRegex.FindSubmatch(output)
+	if match == nil {
+		return "", nil
+	}
+	return string(match[1]), nil
+}
@@ -0,0 +1,68 @@
+package adb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClockSkew returns how far the device clock is ahead of the host clock.
+// The host time is taken as the midpoint of the adb round trip.
+func ClockSkew(deviceSerial string) (time.Duration, error) {
+	args := []string{}
+	if deviceSerial != "" {
+		args = append(args, "-s", deviceSerial)
+	}
+	args = append(args, "shell", "date", "+%s%3N")
+
+	before := time.Now()
+	output, err := Command(args...).Output()
+	after := time.Now()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read device time: %w", err)
+	}
+
+	deviceTime, err := parseDeviceTime(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, err
+	}
+
+	hostTime := before.Add(after.Sub(before) / 2)
+	return deviceTime.Sub(hostTime), nil
+}
+
+// DeviceTime returns the current time on the device clock.
+func DeviceTime(deviceSerial string) (time.Time, error) {
+	args := []string{}
+	if deviceSerial != "" {
+		args = append(args, "-s", deviceSerial)
+	}
+	args = append(args, "shell", "date", "+%s%3N")
+
+	output, err := Command(args...).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read device time: %w", err)
+	}
+	return parseDeviceTime(strings.TrimSpace(string(output)))
+}
+
+// parseDeviceTime parses epoch milliseconds, falling back to seconds on devices
+// whose date does not support %N.
+func parseDeviceTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil && len(value) >= 13 {
+		return time.UnixMilli(ms), nil
+	}
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return time.Time{}, fmt.Errorf("unexpected device time %q", value)
+	}
+	secs, err := strconv.ParseInt(value[:end], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected device time %q", value)
+	}
+	return time.Unix(secs, 0), nil
+}
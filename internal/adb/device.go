@@ -1,29 +1,72 @@
 package adb
 
 import (
-	"fmt"
-	"os/exec"
 	"strings"
+	"sync"
 )
 
 // Device represents an ADB device
 type Device struct {
-	Serial string
-	Model  string
-	Status string
+	Serial  string
+	Model   string
+	Status  string
+	AVDName string
+}
+
+// IsEmulator reports whether the device is an emulator instance
+func (d Device) IsEmulator() bool {
+	return strings.HasPrefix(d.Serial, "emulator-")
+}
+
+// DisplayName returns a human-friendly name: the AVD name for emulators, otherwise the model
+func (d Device) DisplayName() string {
+	if d.AVDName != "" {
+		return d.AVDName
+	}
+	return d.Model
+}
+
+// avdNames caches emulator AVD names by serial, since GetDevices is polled frequently
+var (
+	avdNames   = make(map[string]string)
+	avdNamesMu sync.Mutex
+)
+
+// emulatorAVDName queries the emulator console for its AVD name
+func emulatorAVDName(serial string) string {
+	avdNamesMu.Lock()
+	name, ok := avdNames[serial]
+	avdNamesMu.Unlock()
+	if ok {
+		return name
+	}
+
+	output, err := Command("-s", serial, "emu", "avd", "name").Output()
+	if err == nil {
+		// Output is the AVD name followed by an "OK" line
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		if len(lines) > 0 && strings.TrimSpace(lines[0]) != "OK" {
+			name = strings.TrimSpace(lines[0])
+		}
+	}
+
+	avdNamesMu.Lock()
+	avdNames[serial] = name
+	avdNamesMu.Unlock()
+	return name
 }
 
 // GetDevices returns a list of connected ADB devices
 func GetDevices() ([]Device, error) {
-	cmd := exec.Command("adb", "devices", "-l")
+	cmd := Command("devices", "-l")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("adb command failed - is Android SDK installed?")
+		return nil, ErrAdbMissing
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	if len(lines) <= 1 {
-		return nil, fmt.Errorf("no devices/emulators found")
+		return nil, ErrNoDevices
 	}
 
 	var devices []Device
@@ -55,6 +98,9 @@ func GetDevices() ([]Device, error) {
 		if device.Model == "" {
 			device.Model = "Unknown"
 		}
+		if device.IsEmulator() && device.Status == "device" {
+			device.AVDName = emulatorAVDName(device.Serial)
+		}
 
 		devices = append(devices, device)
 	}
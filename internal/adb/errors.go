@@ -0,0 +1,69 @@
+package adb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by this package, so callers can offer targeted recovery
+// instead of a generic message. Returned errors wrap these with details.
+var (
+	ErrAdbMissing      = errors.New("adb command failed - is Android SDK installed?")
+	ErrNoDevices       = errors.New("no devices/emulators found")
+	ErrMultipleDevices = errors.New("multiple devices connected - select a device")
+	ErrDeviceOffline   = errors.New("device not online")
+	ErrAppNotRunning   = errors.New("app not running or package name not found")
+)
+
+// Hint returns a short recovery suggestion for errors from this package, or "".
+func Hint(err error) string {
+	switch {
+	case errors.Is(err, ErrAdbMissing):
+		return "install Android SDK Platform-Tools and make sure adb is in your PATH or ANDROID_HOME is set"
+	case errors.Is(err, ErrNoDevices):
+		return "connect a device with USB debugging enabled or start an emulator"
+	case errors.Is(err, ErrMultipleDevices):
+		return "pick one with --device, --usb or --emulator"
+	case errors.Is(err, ErrDeviceOffline):
+		return "accept the USB debugging prompt on the device, or reconnect it"
+	case errors.Is(err, ErrAppNotRunning):
+		return "start the app, or check the application ID"
+	}
+	return ""
+}
+
+// RequireDevice checks that the device with the given serial is connected and
+// online. With no serial, exactly one online device must be connected.
+func RequireDevice(deviceSerial string) error {
+	devices, err := GetDevices()
+	if err != nil {
+		return err
+	}
+
+	if deviceSerial != "" {
+		for _, device := range devices {
+			if device.Serial != deviceSerial {
+				continue
+			}
+			if device.Status != "device" {
+				return fmt.Errorf("%w: %s (status: %s)", ErrDeviceOffline, device.Serial, device.Status)
+			}
+			return nil
+		}
+		return fmt.Errorf("%w: %s is not connected", ErrDeviceOffline, deviceSerial)
+	}
+
+	onlineCount := 0
+	for _, device := range devices {
+		if device.Status == "device" {
+			onlineCount++
+		}
+	}
+	if onlineCount == 0 {
+		return fmt.Errorf("%w: none online", ErrNoDevices)
+	}
+	if onlineCount > 1 {
+		return ErrMultipleDevices
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package adb
+
+import (
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// HostLogcatProcesses counts the adb logcat clients running on this machine
+// for the device, or for any device when serial is empty. Each one is a
+// separate stream from the device, so duplicates cost bandwidth and CPU.
+// It returns 0 where ps is unavailable, e.g. on Windows.
+func HostLogcatProcesses(serial string) int {
+	if runtime.GOOS == "windows" {
+		return 0
+	}
+	output, err := exec.Command("ps", "-eo", "args").Output()
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !isAdbExecutable(fields[0]) || !slices.Contains(fields, "logcat") {
+			continue
+		}
+		if serial != "" {
+			i := slices.Index(fields, "-s")
+			if i < 0 || i+1 >= len(fields) || fields[i+1] != serial {
+				continue
+			}
+		}
+		count++
+	}
+	return count
+}
+
+// IsLogcatProcess reports whether the process with pid is an adb logcat client.
+// It returns false where ps is unavailable, e.g. on Windows.
+func IsLogcatProcess(pid int) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	output, err := exec.Command("ps", "-o", "args=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(output))
+	return len(fields) >= 2 && isAdbExecutable(fields[0]) && slices.Contains(fields, "logcat")
+}
@@ -0,0 +1,42 @@
+package adb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// InstrumentationRunner returns the test runner component that targets appID,
+// e.g. "com.example.test/androidx.test.runner.AndroidJUnitRunner"
+func InstrumentationRunner(deviceSerial, appID string) (string, error) {
+	output, err := Command(shellArgs(deviceSerial, "shell", "pm", "list", "instrumentation")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list test runners: %w", err)
+	}
+	// pm prints lines like "instrumentation:com.example.test/androidx.test.runner.AndroidJUnitRunner (target=com.example)"
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		runner, target, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "instrumentation:"), " (target=")
+		if ok && strings.TrimSuffix(target, ")") == appID {
+			return runner, nil
+		}
+	}
+	return "", fmt.Errorf("no test runner installed for %s (install the androidTest APK first)", appID)
+}
+
+// StartInstrumentation starts `am instrument -r -w` for the runner and returns
+// the command and its raw status output
+func StartInstrumentation(deviceSerial, runner string) (*exec.Cmd, io.Reader, error) {
+	cmd := Command(shellArgs(deviceSerial, "shell", "am", "instrument", "-r", "-w", runner)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start instrumentation: %w", err)
+	}
+	return cmd, stdout, nil
+}
@@ -0,0 +1,87 @@
+package adb
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SerialEnv is the environment variable adb reads the default device serial from
+const SerialEnv = "ANDROID_SERIAL"
+
+// DeviceMatch preselects devices from the command line, mirroring adb's -s/-d/-e
+type DeviceMatch struct {
+	// Query is an exact serial or a case-insensitive substring of the model or AVD name
+	Query string
+	// Serial is an exact serial taken from $ANDROID_SERIAL
+	Serial   string
+	USB      bool
+	Emulator bool
+}
+
+// IsZero reports whether no preselection was requested
+func (m DeviceMatch) IsZero() bool {
+	return m.Query == "" && m.Serial == "" && !m.USB && !m.Emulator
+}
+
+// WithEnvDefault returns the match with Serial set from $ANDROID_SERIAL when no
+// preselection was given on the command line, like adb itself.
+func (m DeviceMatch) WithEnvDefault() DeviceMatch {
+	if m.IsZero() {
+		m.Serial = strings.TrimSpace(os.Getenv(SerialEnv))
+	}
+	return m
+}
+
+// String describes the match the way it was given on the command line
+func (m DeviceMatch) String() string {
+	switch {
+	case m.Query != "":
+		return fmt.Sprintf("--device %q", m.Query)
+	case m.Serial != "":
+		return fmt.Sprintf("$%s=%s", SerialEnv, m.Serial)
+	case m.USB:
+		return "--usb"
+	case m.Emulator:
+		return "--emulator"
+	}
+	return ""
+}
+
+// Filter returns the online devices selected by the match. An exact serial
+// match wins over substring matches, so a serial is never ambiguous.
+func (m DeviceMatch) Filter(devices []Device) ([]Device, error) {
+	if m.IsZero() {
+		return devices, nil
+	}
+
+	var matched []Device
+	for _, device := range devices {
+		if device.Status != "device" {
+			continue
+		}
+		if m.USB && device.IsEmulator() || m.Emulator && !device.IsEmulator() {
+			continue
+		}
+		if m.Serial != "" && device.Serial != m.Serial {
+			continue
+		}
+		if m.Query != "" {
+			if device.Serial == m.Query {
+				return []Device{device}, nil
+			}
+			query := strings.ToLower(m.Query)
+			if !strings.Contains(strings.ToLower(device.Model), query) &&
+				!strings.Contains(strings.ToLower(device.AVDName), query) &&
+				!strings.Contains(strings.ToLower(device.Serial), query) {
+				continue
+			}
+		}
+		matched = append(matched, device)
+	}
+
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("%w: no online device matches %s", ErrNoDevices, m)
+	}
+	return matched, nil
+}
@@ -0,0 +1,48 @@
+package adb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMonkeyEvents is the number of events a monkey run injects unless configured
+const DefaultMonkeyEvents = 500
+
+// monkeyProcess is the process name of monkey on the device
+const monkeyProcess = "com.android.commands.monkey"
+
+// RunMonkey runs monkey against appID and waits for it to finish. It returns a
+// one-line summary: the crash or ANR monkey stopped on, or the events injected.
+func RunMonkey(deviceSerial, appID string, events int, seed int64) (string, error) {
+	args := shellArgs(deviceSerial, "shell", "monkey", "-p", appID, "-s", strconv.FormatInt(seed, 10), "-v", strconv.Itoa(events))
+	output, err := Command(args...).CombinedOutput()
+
+	summary := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "// CRASH:"), strings.HasPrefix(line, "// NOT RESPONDING:"):
+			// The first crash or ANR is what stopped the run
+			if !strings.HasPrefix(summary, "//") {
+				summary = line
+			}
+		case strings.HasPrefix(line, "Events injected:") && summary == "":
+			summary = strings.ToLower(line[:1]) + line[1:]
+		case strings.HasPrefix(line, "** No activities found to run"):
+			summary = "no launchable activities in " + appID
+		}
+	}
+	if err != nil && summary == "" {
+		return "", fmt.Errorf("monkey failed: %w", err)
+	}
+	return strings.TrimPrefix(summary, "// "), nil
+}
+
+// StopMonkey kills monkey on the device; killing adb alone would leave it running
+func StopMonkey(deviceSerial string) error {
+	if err := Command(shellArgs(deviceSerial, "shell", "pkill", "-f", monkeyProcess)...).Run(); err != nil {
+		return fmt.Errorf("failed to stop monkey: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package adb
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	pathOnce sync.Once
+	adbPath  string
+)
+
+// Path returns the adb executable to run. adb on the PATH wins; otherwise the
+// SDK from ANDROID_HOME or ANDROID_SDK_ROOT, then the SDK location Android
+// Studio installs to, which on Windows is rarely on the PATH. It falls back to
+// plain "adb" so failures still report a missing command.
+func Path() string {
+	pathOnce.Do(func() {
+		adbPath = resolvePath(os.Getenv, exec.LookPath)
+	})
+	return adbPath
+}
+
+// Command returns a command running adb with args
+func Command(args ...string) *exec.Cmd {
+	return exec.Command(Path(), args...)
+}
+
+func resolvePath(getenv func(string) string, lookPath func(string) (string, error)) string {
+	if path, err := lookPath("adb"); err == nil {
+		return path
+	}
+	name := "adb"
+	if runtime.GOOS == "windows" {
+		name = "adb.exe"
+	}
+	for _, sdk := range sdkDirs(getenv) {
+		path := filepath.Join(sdk, "platform-tools", name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return "adb"
+}
+
+// sdkDirs returns the directories the Android SDK may be installed in, most
+// specific first.
+func sdkDirs(getenv func(string) string) []string {
+	var dirs []string
+	for _, key := range []string{"ANDROID_HOME", "ANDROID_SDK_ROOT"} {
+		if dir := getenv(key); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	switch runtime.GOOS {
+	case "windows":
+		if dir := getenv("LOCALAPPDATA"); dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "Android", "Sdk"))
+		}
+	case "darwin":
+		if home := getenv("HOME"); home != "" {
+			dirs = append(dirs, filepath.Join(home, "Library", "Android", "sdk"))
+		}
+	default:
+		if home := getenv("HOME"); home != "" {
+			dirs = append(dirs, filepath.Join(home, "Android", "Sdk"))
+		}
+	}
+	return dirs
+}
+
+// isAdbExecutable reports whether path names an adb executable, with or
+// without the .exe suffix Windows adds.
+func isAdbExecutable(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return name == "adb" || name == "adb.exe"
+}
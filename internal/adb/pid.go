@@ -2,103 +2,87 @@ package adb
 
 import (
 	"fmt"
-	"os/exec"
+	"slices"
 	"strings"
 	"time"
 )
 
-// GetPID gets the PID for an app package name on the specified device
-func GetPID(deviceSerial, appID string) (string, error) {
-	devices, err := GetDevices()
-	if err != nil {
-		return "", err
+// GetPIDs gets the PIDs of an app package name on the specified device.
+// Multi-process apps (e.g. with a ":remote" service) have one PID per process.
+// mainPID is the process named exactly appID, or "" while only secondary
+// processes, named "<appID>:<process>", are running.
+func GetPIDs(deviceSerial, appID string) (pids []string, mainPID string, err error) {
+	if err := RequireDevice(deviceSerial); err != nil {
+		return nil, "", err
 	}
-	if deviceSerial != "" {
-		var target *Device
-		for i := range devices {
-			if devices[i].Serial == deviceSerial {
-				target = &devices[i]
-				break
-			}
+
+	pids, mainPID = psPIDs(deviceSerial, appID)
+	if len(pids) == 0 {
+		return nil, "", fmt.Errorf("%w - is '%s' installed and running?", ErrAppNotRunning, appID)
+	}
+	return pids, mainPID, nil
+}
+
+// psPIDs finds the app's PIDs and its main process in ps output, by process
+// name. ps lists every process only with -A from Android 8 on, while the older
+// toolbox ps lists them all by default and reads -A as a name to match.
+func psPIDs(deviceSerial, appID string) (pids []string, mainPID string) {
+	for _, psArgs := range [][]string{{"ps", "-A"}, {"ps"}} {
+		args := []string{}
+		if deviceSerial != "" {
+			args = append(args, "-s", deviceSerial)
 		}
-		if target == nil {
-			return "", fmt.Errorf("device %s not found", deviceSerial)
+		args = append(args, "shell")
+		args = append(args, psArgs...)
+		output, err := Command(args...).Output()
+		if err != nil {
+			continue
 		}
-		if target.Status != "device" {
-			return "", fmt.Errorf("device %s not online (status: %s)", target.Serial, target.Status)
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		if len(lines) <= 1 {
+			// Only the header: this ps needs the other form
+			continue
 		}
-	} else {
-		onlineCount := 0
-		for _, device := range devices {
-			if device.Status == "device" {
-				onlineCount++
+		for _, line := range lines[1:] {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || !isNumeric(fields[1]) {
+				continue
+			}
+			name := fields[len(fields)-1]
+			if name == appID {
+				mainPID = fields[1]
+				pids = append(pids, fields[1])
+			} else if strings.HasPrefix(name, appID+":") {
+				pids = append(pids, fields[1])
 			}
 		}
-		if onlineCount == 0 {
-			return "", fmt.Errorf("no online devices found - connect a device or start an emulator")
-		}
-		if onlineCount > 1 {
-			return "", fmt.Errorf("multiple devices connected - select a device")
-		}
+		return pids, mainPID
 	}
-
-	// Get PID
-	args := []string{}
-	if deviceSerial != "" {
-		args = append(args, "-s", deviceSerial)
-	}
-	args = append(args, "shell", "pidof", appID)
-	cmd := exec.Command("adb", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("app not running or package name not found - is '%s' installed and running?", appID)
-	}
-
-	pid := strings.TrimSpace(string(output))
-	if pid == "" {
-		return "", fmt.Errorf("app not running or package name not found - is '%s' installed and running?", appID)
-	}
-
-	return pid, nil
+	return nil, ""
 }
 
-// IsPIDRunning checks if a PID is still running on the specified device
-func IsPIDRunning(deviceSerial, pid string) bool {
-	args := []string{}
-	if deviceSerial != "" {
-		args = append(args, "-s", deviceSerial)
-	}
-	args = append(args, "shell", "ps", "-p", pid)
-	cmd := exec.Command("adb", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-	// If ps returns output with the PID, the process is running
-	return strings.Contains(string(output), pid)
-}
-
-// WaitForPID polls for a PID to appear, returning when found or context cancelled
-// Returns the PID when found, or empty string if cancelled
-func WaitForPID(deviceSerial, appID string, pollInterval time.Duration, stopChan <-chan struct{}) string {
+// WaitForPIDs polls for the app's PIDs to appear, returning when found or cancelled
+// Returns the PIDs and the main process when found, or nil if cancelled
+func WaitForPIDs(deviceSerial, appID string, pollInterval time.Duration, stopChan <-chan struct{}) (pids []string, mainPID string) {
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-stopChan:
-			return ""
+			return nil, ""
 		case <-ticker.C:
-			pid, err := GetPID(deviceSerial, appID)
-			if err == nil && pid != "" {
-				return pid
+			pids, mainPID, err := GetPIDs(deviceSerial, appID)
+			if err == nil && len(pids) > 0 {
+				return pids, mainPID
 			}
 		}
 	}
 }
 
-// MonitorPID monitors a PID and returns when it stops running
-func MonitorPID(deviceSerial, pid string, checkInterval time.Duration, stopChan <-chan struct{}) {
+// MonitorPIDs polls the app's processes and returns when they differ from
+// pids: one of them exited, or a new one such as a ":sync" service started.
+func MonitorPIDs(deviceSerial, appID string, pids []string, checkInterval time.Duration, stopChan <-chan struct{}) {
 	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
@@ -107,9 +91,35 @@ func MonitorPID(deviceSerial, pid string, checkInterval time.Duration, stopChan
 		case <-stopChan:
 			return
 		case <-ticker.C:
-			if !IsPIDRunning(deviceSerial, pid) {
+			current, _ := psPIDs(deviceSerial, appID)
+			if !samePIDs(current, pids) {
 				return
 			}
 		}
 	}
 }
+
+// samePIDs reports whether a and b hold the same PIDs in any order.
+func samePIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, pid := range a {
+		if !slices.Contains(b, pid) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package adb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinPIDFilterAPILevel is the first Android API level whose logcat supports --pid
+const MinPIDFilterAPILevel = 24
+
+// APILevel returns the Android API level of the device, from ro.build.version.sdk
+func APILevel(deviceSerial string) (int, error) {
+	args := []string{}
+	if deviceSerial != "" {
+		args = append(args, "-s", deviceSerial)
+	}
+	args = append(args, "shell", "getprop", "ro.build.version.sdk")
+
+	output, err := Command(args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read API level: %w", err)
+	}
+	level, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected API level %q", strings.TrimSpace(string(output)))
+	}
+	return level, nil
+}
@@ -0,0 +1,95 @@
+package adb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// reportProps are the getprop keys summarized in bug report bundles
+var reportProps = []string{
+	"ro.product.manufacturer",
+	"ro.product.model",
+	"ro.product.device",
+	"ro.build.version.release",
+	"ro.build.version.sdk",
+	"ro.build.fingerprint",
+	"ro.build.type",
+	"ro.product.cpu.abi",
+	"persist.sys.locale",
+	"persist.sys.timezone",
+}
+
+func shellArgs(deviceSerial string, args ...string) []string {
+	out := []string{}
+	if deviceSerial != "" {
+		out = append(out, "-s", deviceSerial)
+	}
+	return append(out, args...)
+}
+
+// DeviceInfo returns a "key: value" summary of the device's build properties
+func DeviceInfo(deviceSerial string) (string, error) {
+	output, err := Command(shellArgs(deviceSerial, "shell", "getprop")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read device properties: %w", err)
+	}
+
+	// getprop prints lines like "[ro.product.model]: [Pixel 8]"
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "]: [")
+		if !ok {
+			continue
+		}
+		props[strings.TrimPrefix(key, "[")] = strings.TrimSuffix(value, "]")
+	}
+
+	var b strings.Builder
+	if deviceSerial != "" {
+		fmt.Fprintf(&b, "serial: %s\n", deviceSerial)
+	}
+	for _, key := range reportProps {
+		if value, ok := props[key]; ok {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+	return b.String(), nil
+}
+
+// PackageVersion returns the version and install lines of dumpsys package for appID
+func PackageVersion(deviceSerial, appID string) (string, error) {
+	output, err := Command(shellArgs(deviceSerial, "shell", "dumpsys", "package", appID)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read package info: %w", err)
+	}
+
+	var b strings.Builder
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		for _, key := range []string{"versionCode=", "versionName=", "firstInstallTime=", "lastUpdateTime="} {
+			// dumpsys repeats some fields per user; keep the first occurrence
+			if strings.HasPrefix(line, key) && !seen[key] {
+				seen[key] = true
+				b.WriteString(line + "\n")
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("package '%s' not installed", appID)
+	}
+	return b.String(), nil
+}
+
+// Screenshot captures the device screen as PNG
+func Screenshot(deviceSerial string) ([]byte, error) {
+	output, err := Command(shellArgs(deviceSerial, "exec-out", "screencap", "-p")...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+	return output, nil
+}
@@ -6,30 +6,103 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 )
 
 // FilterPreference captures a single filter setting for persistence.
 type FilterPreference struct {
 	IsTag   bool   `json:"isTag"`
+	Field   string `json:"field,omitempty"`
+	Exclude bool   `json:"exclude,omitempty"`
 	Pattern string `json:"pattern"`
 }
 
+// SinkPreference forwards matching entries to a file, unix socket or HTTP endpoint.
+// Target uses the form "file:<path>", "unix:<path>" or an http(s) URL.
+type SinkPreference struct {
+	Pattern  string `json:"pattern"`
+	MinLevel string `json:"minLevel,omitempty"`
+	Target   string `json:"target"`
+}
+
+// TriggerPreference saves the entries around each entry matching Pattern to a
+// file: Before entries preceding it and After entries following it.
+type TriggerPreference struct {
+	Pattern string `json:"pattern"`
+	Before  int    `json:"before,omitempty"`
+	After   int    `json:"after,omitempty"`
+	// Name prefixes the file name, e.g. "crash" for crash-20250102-150405.log
+	Name string `json:"name,omitempty"`
+	Dir  string `json:"dir,omitempty"`
+}
+
+// StylePreference overrides the style of a log level's messages.
+// Colors are hex ("#ff8800") or ANSI ("208") values.
+type StylePreference struct {
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+	Bold       bool   `json:"bold,omitempty"`
+	Italic     bool   `json:"italic,omitempty"`
+	Underline  bool   `json:"underline,omitempty"`
+	Faint      bool   `json:"faint,omitempty"`
+}
+
 // DefaultTailSize is the fallback tail size when preferences are missing or invalid.
 const DefaultTailSize = 1000
 
 // Preferences holds persisted UI preferences.
 type Preferences struct {
-	Filters            []FilterPreference `json:"filters"`
-	MinLogLevel        string             `json:"minLogLevel"`
-	ShowTimestamp      bool               `json:"showTimestamp"`
-	TagColumnWidth     int                `json:"tagColumnWidth"`
-	TailSize           int                `json:"tailSize"`
-	WrapLines          bool               `json:"wrapLines"`
-	LogLevelBackground *bool              `json:"logLevelBackground,omitempty"`
-	ColoredMessages    *bool              `json:"coloredMessages,omitempty"`
+	Filters            []FilterPreference         `json:"filters"`
+	MinLogLevel        string                     `json:"minLogLevel"`
+	Levels             []string                   `json:"levels,omitempty"`
+	HideUnparsed       bool                       `json:"hideUnparsed,omitempty"`
+	NarrowWidth        int                        `json:"narrowWidth,omitempty"`
+	ContextLines       int                        `json:"contextLines,omitempty"`
+	PauseOnError       bool                       `json:"pauseOnError,omitempty"`
+	ExportFormat       string                     `json:"exportFormat,omitempty"`
+	ThreadtimeExport   bool                       `json:"threadtimeExport,omitempty"` // read as exportFormat "logcat"
+	BookmarkFatal      bool                       `json:"bookmarkFatal,omitempty"`
+	BookmarkErrors     bool                       `json:"bookmarkErrors,omitempty"`
+	LowPower           bool                       `json:"lowPower,omitempty"`
+	RenderIntervalMs   int                        `json:"renderIntervalMs,omitempty"`
+	ReadIntervalMs     int                        `json:"readIntervalMs,omitempty"`
+	SynchronizedOutput *bool                      `json:"synchronizedOutput,omitempty"`
+	FreezeOnError      bool                       `json:"freezeOnError,omitempty"`
+	PIDCheckIntervalMs int                        `json:"pidCheckIntervalMs,omitempty"`
+	PIDPollIntervalMs  int                        `json:"pidPollIntervalMs,omitempty"`
+	ShowTimestamp      bool                       `json:"showTimestamp"`
+	TimestampFormat    string                     `json:"timestampFormat,omitempty"`
+	MaxLineLength      int                        `json:"maxLineLength,omitempty"`
+	HostTime           bool                       `json:"hostTime,omitempty"`
+	ZoneTime           bool                       `json:"zoneTime,omitempty"`
+	TimeZone           string                     `json:"timeZone,omitempty"`
+	Redact             bool                       `json:"redact,omitempty"`
+	Redactions         []string                   `json:"redactions,omitempty"`
+	GistToken          string                     `json:"gistToken,omitempty"`
+	SearchURL          string                     `json:"searchURL,omitempty"`
+	DeviceAliases      map[string]string          `json:"deviceAliases,omitempty"`
+	TagBudgets         map[string]int             `json:"tagBudgets,omitempty"`
+	ColorTheme         string                     `json:"colorTheme,omitempty"`
+	TagColors          map[string]string          `json:"tagColors,omitempty"`
+	PriorityStyles     map[string]StylePreference `json:"priorityStyles,omitempty"`
+	MonkeyEvents       int                        `json:"monkeyEvents,omitempty"`
+	MonkeySeed         int64                      `json:"monkeySeed,omitempty"`
+	AutosaveOnSignal   bool                       `json:"autosaveOnSignal,omitempty"`
+	TagColumnWidth     int                        `json:"tagColumnWidth"`
+	TailSize           int                        `json:"tailSize"`
+	WrapLines          bool                       `json:"wrapLines"`
+	LogLevelBackground *bool                      `json:"logLevelBackground,omitempty"`
+	ColoredMessages    *bool                      `json:"coloredMessages,omitempty"`
+	StickyHeader       *bool                      `json:"stickyHeader,omitempty"`
+	Sinks              []SinkPreference           `json:"sinks,omitempty"`
+	Triggers           []TriggerPreference        `json:"triggers,omitempty"`
+	Extractors         []string                   `json:"extractors,omitempty"`
+	Watches            []string                   `json:"watches,omitempty"`
+	ReorderWindowMs    int                        `json:"reorderWindowMs,omitempty"`
+	MemoryLimitMB      int                        `json:"memoryLimitMB,omitempty"`
 }
 
-// Load reads preferences from ~/.config/logdog/config.json.
+// Load reads preferences from the config file.
 func Load() (Preferences, bool, error) {
 	path, err := configFilePath()
 	if err != nil {
@@ -55,7 +128,7 @@ func Load() (Preferences, bool, error) {
 	return prefs, true, nil
 }
 
-// Save writes preferences to ~/.config/logdog/config.json.
+// Save writes preferences to the config file.
 func Save(prefs Preferences) error {
 	path, err := configFilePath()
 	if err != nil {
@@ -115,10 +188,28 @@ func DefaultPreferences() Preferences {
 	}
 }
 
+// configFilePath returns ~/.config/logdog/config.json, or
+// %APPDATA%\logdog\config.json on Windows. A Windows config saved under the
+// home directory by earlier versions is kept in use until it is moved.
 func configFilePath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("resolve home dir: %w", err)
 	}
-	return filepath.Join(home, ".config", "logdog", "config.json"), nil
+	legacy := filepath.Join(home, ".config", "logdog", "config.json")
+	if runtime.GOOS != "windows" {
+		return legacy, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve config dir: %w", err)
+	}
+	path := filepath.Join(dir, "logdog", "config.json")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy, nil
+		}
+	}
+	return path, nil
 }
@@ -0,0 +1,82 @@
+package gist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	apiURL         = "https://api.github.com/gists"
+	requestTimeout = 15 * time.Second
+)
+
+// Token returns the GitHub token from GITHUB_TOKEN or GH_TOKEN, falling back to configured.
+func Token(configured string) string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	if token := os.Getenv("GH_TOKEN"); token != "" {
+		return token
+	}
+	return configured
+}
+
+type file struct {
+	Content string `json:"content"`
+}
+
+type request struct {
+	Description string          `json:"description"`
+	Public      bool            `json:"public"`
+	Files       map[string]file `json:"files"`
+}
+
+type response struct {
+	HTMLURL string `json:"html_url"`
+	Message string `json:"message"`
+}
+
+// Create uploads content as a secret gist and returns its URL.
+func Create(token, filename, description, content string) (string, error) {
+	if token == "" {
+		return "", fmt.Errorf("no GitHub token - set GITHUB_TOKEN or gistToken in config")
+	}
+
+	body, err := json.Marshal(request{
+		Description: description,
+		Public:      false,
+		Files:       map[string]file{filename: {Content: content}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode gist: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create gist request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: requestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload gist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result response
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode gist response: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("upload gist: %s (%s)", resp.Status, result.Message)
+	}
+
+	return result.HTMLURL, nil
+}
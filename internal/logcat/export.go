@@ -0,0 +1,209 @@
+package logcat
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Exporter writes entries in one export format. Copying, sharing, the pager
+// and report bundles all export through the registered exporters, so every
+// format is available everywhere.
+type Exporter interface {
+	// Name identifies the format in the config and the export picker
+	Name() string
+	// Extension is the file extension for the format, without the dot
+	Extension() string
+	// Write writes the entries in order. notes holds extra lines per entry ID,
+	// such as flags and annotations; formats that can't carry them skip them.
+	Write(w io.Writer, entries []*Entry, notes map[uint64][]string) error
+}
+
+var exporters []Exporter
+
+// RegisterExporter adds an exporter to the registry. The export picker lists
+// exporters in registration order.
+func RegisterExporter(e Exporter) {
+	exporters = append(exporters, e)
+}
+
+// Exporters returns the registered exporters in registration order.
+func Exporters() []Exporter {
+	return append([]Exporter(nil), exporters...)
+}
+
+// ExporterNames returns the names of the registered exporters in registration order.
+func ExporterNames() []string {
+	names := make([]string, len(exporters))
+	for i, e := range exporters {
+		names[i] = e.Name()
+	}
+	return names
+}
+
+// ExporterNamed returns the registered exporter with the given name.
+func ExporterNamed(name string) (Exporter, error) {
+	for _, e := range exporters {
+		if e.Name() == name {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown export format %q (known: %s)", name, strings.Join(ExporterNames(), ", "))
+}
+
+// lineExporter writes one or more text lines per entry.
+type lineExporter struct {
+	name      string
+	extension string
+	format    func(*Entry) string
+	// withNotes writes notes as indented "# " comment lines after their entry
+	withNotes bool
+}
+
+func (e lineExporter) Name() string      { return e.name }
+func (e lineExporter) Extension() string { return e.extension }
+
+func (e lineExporter) Write(w io.Writer, entries []*Entry, notes map[uint64][]string) error {
+	bw := bufio.NewWriter(w)
+	for _, entry := range entries {
+		bw.WriteString(e.format(entry))
+		bw.WriteByte('\n')
+		if !e.withNotes {
+			continue
+		}
+		for _, note := range notes[entry.ID] {
+			bw.WriteString("    # " + note + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// markdownExporter wraps plain lines in a fenced code block, ready to paste
+// into an issue or chat.
+type markdownExporter struct{}
+
+func (markdownExporter) Name() string      { return "markdown" }
+func (markdownExporter) Extension() string { return "md" }
+
+func (markdownExporter) Write(w io.Writer, entries []*Entry, notes map[uint64][]string) error {
+	var body strings.Builder
+	if err := plainExporter.Write(&body, entries, notes); err != nil {
+		return err
+	}
+	// The fence must be longer than any backtick run in the log itself
+	fence := strings.Repeat("`", max(3, longestRun(body.String(), '`')+1))
+	_, err := fmt.Fprintf(w, "%s\n%s%s\n", fence, body.String(), fence)
+	return err
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// jsonEntry is an entry in JSON exports. The keys are ones the json import
+// format reads, so an export can be loaded again with --import.
+type jsonEntry struct {
+	Timestamp string            `json:"timestamp,omitempty"`
+	PID       string            `json:"pid,omitempty"`
+	TID       string            `json:"tid,omitempty"`
+	Priority  string            `json:"priority"`
+	Tag       string            `json:"tag"`
+	Message   string            `json:"message"`
+	Raw       string            `json:"raw"`
+	Source    string            `json:"source,omitempty"`
+	Unparsed  bool              `json:"unparsed,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Notes     []string          `json:"notes,omitempty"`
+}
+
+// jsonExporter writes one JSON object per line (JSON Lines), for tools that
+// consume captured sessions.
+type jsonExporter struct{}
+
+func (jsonExporter) Name() string      { return "json" }
+func (jsonExporter) Extension() string { return "jsonl" }
+
+func (jsonExporter) Write(w io.Writer, entries []*Entry, notes map[uint64][]string) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	// Messages are logs, not HTML
+	encoder.SetEscapeHTML(false)
+	for _, entry := range entries {
+		err := encoder.Encode(jsonEntry{
+			Timestamp: entry.Timestamp,
+			PID:       entry.PID,
+			TID:       entry.TID,
+			Priority:  strings.ToLower(entry.Priority.Name()),
+			Tag:       strings.TrimRight(entry.Tag, " "),
+			Message:   entry.Message,
+			Raw:       entry.Raw,
+			Source:    entry.Source,
+			Unparsed:  entry.Unparsed,
+			Fields:    entry.Fields,
+			Notes:     notes[entry.ID],
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// csvHeader names the columns of CSV exports.
+var csvHeader = []string{"timestamp", "source", "pid", "tid", "level", "tag", "message", "notes"}
+
+// csvExporter writes a header row and one row per entry, for opening logs in
+// a spreadsheet. Fields with commas, quotes or newlines are quoted.
+type csvExporter struct{}
+
+func (csvExporter) Name() string      { return "csv" }
+func (csvExporter) Extension() string { return "csv" }
+
+func (csvExporter) Write(w io.Writer, entries []*Entry, notes map[uint64][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err := cw.Write([]string{
+			entry.Timestamp,
+			entry.Source,
+			entry.PID,
+			entry.TID,
+			entry.Priority.Name(),
+			strings.TrimRight(entry.Tag, " "),
+			entry.Message,
+			strings.Join(notes[entry.ID], "\n"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var plainExporter = lineExporter{name: "plain", extension: "log", format: (*Entry).FormatPlain, withNotes: true}
+
+func init() {
+	RegisterExporter(plainExporter)
+	// Comment lines would not be valid logcat output
+	RegisterExporter(lineExporter{name: "logcat", extension: "log", format: (*Entry).FormatThreadtime})
+	RegisterExporter(lineExporter{name: "raw", extension: "log", format: func(e *Entry) string { return e.Raw }})
+	RegisterExporter(markdownExporter{})
+	RegisterExporter(jsonExporter{})
+	RegisterExporter(csvExporter{})
+}
@@ -0,0 +1,78 @@
+package logcat
+
+import (
+	"strings"
+	"testing"
+)
+
+func exportString(t *testing.T, name string, entries []*Entry, notes map[uint64][]string) string {
+	t.Helper()
+	exporter, err := ExporterNamed(name)
+	if err != nil {
+		t.Fatalf("ExporterNamed(%q) returned error: %v", name, err)
+	}
+	var b strings.Builder
+	if err := exporter.Write(&b, entries, notes); err != nil {
+		t.Fatalf("%s export returned error: %v", name, err)
+	}
+	return b.String()
+}
+
+func TestPlainExportWritesNotesAfterTheirEntry(t *testing.T) {
+	entries := []*Entry{
+		{ID: 1, Raw: "first", Message: "first"},
+		{ID: 2, Raw: "second", Message: "second"},
+	}
+	notes := map[uint64][]string{1: {"note: look here"}}
+
+	got := exportString(t, "raw", entries, notes)
+	if want := "first\nsecond\n"; got != want {
+		t.Fatalf("expected raw export %q, got %q", want, got)
+	}
+
+	got = exportString(t, "plain", entries, notes)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 || lines[1] != "    # note: look here" {
+		t.Fatalf("expected the note after the first entry, got %q", got)
+	}
+}
+
+func TestMarkdownExportFenceOutgrowsBackticks(t *testing.T) {
+	entries := []*Entry{{ID: 1, Message: "query ```select```"}}
+
+	got := exportString(t, "markdown", entries, nil)
+	if !strings.HasPrefix(got, "````\n") || !strings.HasSuffix(got, "\n````\n") {
+		t.Fatalf("expected a four-backtick fence, got %q", got)
+	}
+}
+
+func TestExporterNamedRejectsUnknownFormats(t *testing.T) {
+	if _, err := ExporterNamed("docx"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestJSONExportLoadsBackWithImport(t *testing.T) {
+	entries := []*Entry{{ID: 7, Timestamp: "01-02 10:00:00.000", PID: "100", TID: "101", Priority: Warn, Tag: "Net", Message: `retry "a" <b>`, Raw: "raw line"}}
+
+	got := exportString(t, "json", entries, map[uint64][]string{7: {"note: flaky"}})
+	if !strings.Contains(got, `"message":"retry \"a\" <b>"`) || !strings.Contains(got, `"notes":["note: flaky"]`) {
+		t.Fatalf("unexpected JSON export %q", got)
+	}
+
+	entry := parseJSONLine(strings.TrimSpace(got))
+	if entry.Priority != Warn || entry.Tag != "Net" || entry.PID != "100" || entry.Message != `retry "a" <b>` {
+		t.Fatalf("expected the export to load back, got %+v", entry)
+	}
+}
+
+func TestCSVExportQuotesMessages(t *testing.T) {
+	entries := []*Entry{{ID: 1, Timestamp: "01-02 10:00:00.000", PID: "100", TID: "101", Priority: Error, Tag: "Net     ", Message: "failed, \"retrying\"\nsecond line"}}
+
+	got := exportString(t, "csv", entries, map[uint64][]string{1: {"note: flaky"}})
+	want := "timestamp,source,pid,tid,level,tag,message,notes\n" +
+		"01-02 10:00:00.000,,100,101,Error,Net,\"failed, \"\"retrying\"\"\nsecond line\",note: flaky\n"
+	if got != want {
+		t.Fatalf("expected CSV export %q, got %q", want, got)
+	}
+}
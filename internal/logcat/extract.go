@@ -0,0 +1,66 @@
+package logcat
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Extractor pulls structured values out of messages using a regex with named groups.
+// Each named group becomes a field on the entry.
+type Extractor struct {
+	regex *regexp.Regexp
+	names []string
+}
+
+// NewExtractor compiles the pattern and validates that it has at least one named group.
+func NewExtractor(pattern string) (*Extractor, error) {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extractor pattern: %w", err)
+	}
+
+	var names []string
+	for _, name := range regex.SubexpNames() {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("extractor pattern %q has no named groups", pattern)
+	}
+
+	return &Extractor{regex: regex, names: names}, nil
+}
+
+// Names returns the field names produced by this extractor.
+func (x *Extractor) Names() []string {
+	return x.names
+}
+
+// Apply stores all matched named groups in the entry's fields.
+func (x *Extractor) Apply(e *Entry) {
+	match := x.regex.FindStringSubmatch(e.Message)
+	if match == nil {
+		return
+	}
+	for i, name := range x.regex.SubexpNames() {
+		if name == "" || match[i] == "" {
+			continue
+		}
+		e.SetField(name, match[i])
+	}
+}
+
+// SetField stores a structured value on the entry.
+func (e *Entry) SetField(name, value string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	e.Fields[name] = value
+}
+
+// Field returns a structured value extracted from the entry, if present.
+func (e *Entry) Field(name string) (string, bool) {
+	value, ok := e.Fields[name]
+	return value, ok
+}
@@ -0,0 +1,75 @@
+package logcat
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// fileSampleLines is how many non-blank lines are sampled to detect a file's format.
+const fileSampleLines = 20
+
+// NewFileManager creates a manager that reads a saved logcat dump instead of
+// a device. Lines go through the same reader as a live stream, and reading
+// ends at the end of the file.
+func NewFileManager(path string) *Manager {
+	m := NewManager("", TailAll)
+	m.filePath = path
+	return m
+}
+
+// FilePath returns the file this manager reads, or "" for a device stream
+func (m *Manager) FilePath() string {
+	return m.filePath
+}
+
+// FileFormat returns the log format detected for the file once Start has
+// returned. Files in no known format are read as threadtime.
+func (m *Manager) FileFormat() Format {
+	return m.fileFormat
+}
+
+// startFile opens the file, detects its format and starts reading it.
+func (m *Manager) startFile() error {
+	file, err := os.Open(m.filePath)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	m.fileFormat = detectFileFormat(file)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("read log file: %w", err)
+	}
+	m.cmdMu.Lock()
+	m.file = file
+	m.cmdMu.Unlock()
+
+	m.setScanner(newScanner(file))
+	return nil
+}
+
+// closeFile closes the file once reading has stopped.
+func (m *Manager) closeFile() {
+	m.cmdMu.Lock()
+	file := m.file
+	m.file = nil
+	m.cmdMu.Unlock()
+
+	if file != nil {
+		_ = file.Close()
+	}
+}
+
+// detectFileFormat picks the format of the first non-blank lines of r.
+func detectFileFormat(r io.Reader) Format {
+	var sample []string
+	scanner := newScanner(r)
+	for len(sample) < fileSampleLines && scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			sample = append(sample, line)
+		}
+	}
+	format, _ := DetectFormat(sample)
+	return format
+}
@@ -0,0 +1,65 @@
+package logcat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileManagerReadsEveryLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.log")
+	content := "01-02 10:00:00.000  100  100 I Foo     : first\n" +
+		"01-02 10:00:00.001  100  100 E Foo     : second\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write capture: %v", err)
+	}
+
+	manager := NewFileManager(path)
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	lines := make(chan string, 10)
+	manager.ReadLines(lines)
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	if got := len(lines); got != 2 {
+		t.Fatalf("expected 2 lines, got %d", got)
+	}
+	if entry, _ := ParseLine(<-lines); entry.Message != "first" {
+		t.Fatalf("expected the first line first, got %q", entry.Message)
+	}
+}
+
+func TestFileManagerReportsMissingFile(t *testing.T) {
+	manager := NewFileManager(filepath.Join(t.TempDir(), "missing.log"))
+	if err := manager.Start(); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestFileManagerDetectsFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "brief.log")
+	content := "I/ActivityManager(  512): Start proc\nW/MyTag( 1234): careful\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write capture: %v", err)
+	}
+
+	manager := NewFileManager(path)
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	lines := make(chan string, 10)
+	manager.ReadLines(lines)
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	if got := manager.FileFormat().Name; got != "brief" {
+		t.Fatalf("expected brief, got %s", got)
+	}
+	if got := len(lines); got != 2 {
+		t.Fatalf("expected the sampled lines to be read again, got %d lines", got)
+	}
+}
@@ -0,0 +1,430 @@
+package logcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parser turns the lines of one log format into entries. Parsers may keep
+// state between lines, like the long format's header, so each stream needs
+// its own.
+type Parser interface {
+	// Parse returns the entry for a line, or nil for lines that only carry
+	// state, such as headers and separators.
+	Parse(line string) *Entry
+}
+
+// ParserFunc adapts a stateless function to a Parser.
+type ParserFunc func(line string) *Entry
+
+// Parse calls f.
+func (f ParserFunc) Parse(line string) *Entry {
+	return f(line)
+}
+
+// Format is a log format in the registry.
+type Format struct {
+	Name string
+	// Detect reports whether a line is in this format
+	Detect func(line string) bool
+	// NewParser returns a parser for one stream
+	NewParser func() Parser
+}
+
+var formats []Format
+
+// RegisterFormat adds a format to the registry. Detection prefers formats
+// registered earlier when several match equally well.
+func RegisterFormat(f Format) {
+	formats = append(formats, f)
+}
+
+// FormatNames returns the names of the registered formats in registration order.
+func FormatNames() []string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// FormatNamed returns the registered format with the given name.
+func FormatNamed(name string) (Format, error) {
+	for _, f := range formats {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return Format{}, fmt.Errorf("unknown log format %q (known: %s)", name, strings.Join(FormatNames(), ", "))
+}
+
+// DetectFormat picks the format that recognizes the most lines of a sample.
+// It returns threadtime, and false, when no format recognizes any line.
+func DetectFormat(sample []string) (Format, bool) {
+	best, bestCount := -1, 0
+	for i, f := range formats {
+		count := 0
+		for _, line := range sample {
+			if strings.TrimSpace(line) != "" && f.Detect(line) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = i, count
+		}
+	}
+	if best < 0 {
+		f, _ := FormatNamed("threadtime")
+		return f, false
+	}
+	return formats[best], true
+}
+
+func init() {
+	RegisterFormat(Format{
+		Name: "threadtime",
+		Detect: func(line string) bool {
+			entry, err := ParseLine(line)
+			return err == nil && !entry.Unparsed
+		},
+		NewParser: func() Parser {
+			return ParserFunc(func(line string) *Entry {
+				entry, _ := ParseLine(line)
+				return entry
+			})
+		},
+	})
+	RegisterFormat(Format{
+		Name:      "brief",
+		Detect:    briefPattern.MatchString,
+		NewParser: func() Parser { return ParserFunc(parseBrief) },
+	})
+	RegisterFormat(Format{
+		Name:      "long",
+		Detect:    longHeaderPattern.MatchString,
+		NewParser: func() Parser { return &longParser{} },
+	})
+	RegisterFormat(Format{
+		Name: "studio",
+		Detect: func(line string) bool {
+			return studioPattern.MatchString(line) || studioLegacyPattern.MatchString(line)
+		},
+		NewParser: func() Parser { return ParserFunc(parseStudio) },
+	})
+	RegisterFormat(Format{
+		Name:      "dmesg",
+		Detect:    dmesgPattern.MatchString,
+		NewParser: func() Parser { return ParserFunc(parseDmesg) },
+	})
+	RegisterFormat(Format{
+		Name: "json",
+		Detect: func(line string) bool {
+			_, ok := jsonObject(line)
+			return ok
+		},
+		NewParser: func() Parser { return ParserFunc(parseJSONLine) },
+	})
+}
+
+// unparsedEntry keeps a line no format recognized, like ParseLine does.
+func unparsedEntry(line string) *Entry {
+	if line == "" {
+		return nil
+	}
+	return &Entry{Raw: line, Priority: Unknown, Unparsed: true, Message: sanitizeText(line)}
+}
+
+// briefPattern matches "I/Tag( 1234): message".
+var briefPattern = regexp.MustCompile(`^([VDIWEFA])/(.*?)\(\s*(\d+)\): ?(.*)$`)
+
+func parseBrief(line string) *Entry {
+	m := briefPattern.FindStringSubmatch(line)
+	if m == nil {
+		return unparsedEntry(line)
+	}
+	return &Entry{
+		Raw:      line,
+		Priority: PriorityFromChar(rune(m[1][0])),
+		Tag:      intern(sanitizeText(strings.TrimSpace(m[2]))),
+		PID:      intern(m[3]),
+		Message:  sanitizeText(m[4]),
+	}
+}
+
+// longHeaderPattern matches "[ 01-02 15:04:05.000  1234: 5678 I/Tag ]". The
+// message follows on its own lines, ended by a blank line.
+var longHeaderPattern = regexp.MustCompile(`^\[ (\d\d-\d\d \d\d:\d\d:\d\d\.\d{3})\s+(\d+):\s*(\w+) ([VDIWEFA])/(.*?)\s*\]$`)
+
+type longParser struct {
+	header *Entry
+}
+
+func (p *longParser) Parse(line string) *Entry {
+	if m := longHeaderPattern.FindStringSubmatch(line); m != nil {
+		p.header = &Entry{
+			Timestamp: m[1],
+			Time:      ParseTimestamp(m[1]),
+			PID:       intern(m[2]),
+			TID:       intern(m[3]),
+			Priority:  PriorityFromChar(rune(m[4][0])),
+			Tag:       intern(sanitizeText(m[5])),
+		}
+		return nil
+	}
+	if line == "" {
+		return nil
+	}
+	if p.header == nil {
+		return unparsedEntry(line)
+	}
+	entry := *p.header
+	entry.Raw = line
+	entry.Message = sanitizeText(line)
+	return &entry
+}
+
+// studioPattern matches Android Studio's logcat copy format,
+// "2024-01-02 15:04:05.000  1234-5678  Tag  com.example.app  I  message", and
+// studioLegacyPattern the older "2024-01-02 15:04:05.000 1234-5678/com.example.app I/Tag: message".
+var (
+	studioPattern       = regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d{3})\s+(\d+)-(\d+)\s+(\S+)\s+(\S+)\s+([VDIWEFA])\s+(.*)$`)
+	studioLegacyPattern = regexp.MustCompile(`^(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\.\d{3})\s+(\d+)-(\d+)/(\S*) ([VDIWEFA])/(.*?): ?(.*)$`)
+)
+
+func parseStudio(line string) *Entry {
+	var stamp, pid, tid, tag, pkg, priority, message string
+	if m := studioPattern.FindStringSubmatch(line); m != nil {
+		stamp, pid, tid, tag, pkg, priority, message = m[1], m[2], m[3], m[4], m[5], m[6], m[7]
+	} else if m := studioLegacyPattern.FindStringSubmatch(line); m != nil {
+		stamp, pid, tid, pkg, priority, tag, message = m[1], m[2], m[3], m[4], m[5], m[6], m[7]
+	} else {
+		return unparsedEntry(line)
+	}
+
+	entry := &Entry{
+		Raw:      line,
+		PID:      intern(pid),
+		TID:      intern(tid),
+		Priority: PriorityFromChar(rune(priority[0])),
+		Tag:      intern(sanitizeText(tag)),
+		Message:  sanitizeText(message),
+	}
+	// Studio prints the year, which threadtime timestamps leave out
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05.000", stamp, time.Local); err == nil {
+		entry.Time = t
+		entry.Timestamp = t.Format(timestampLayout)
+	}
+	if pkg != "" && pkg != "?" {
+		entry.SetField("package", pkg)
+	}
+	return entry
+}
+
+// dmesgPattern matches kernel log lines, "<6>[  123.456789] message", with
+// the syslog level prefix optional.
+var dmesgPattern = regexp.MustCompile(`^(?:<(\d)>)?\[\s*(\d+\.\d+)\] ?(.*)$`)
+
+func parseDmesg(line string) *Entry {
+	m := dmesgPattern.FindStringSubmatch(line)
+	if m == nil {
+		return unparsedEntry(line)
+	}
+	priority := Info
+	if m[1] != "" {
+		switch level := m[1][0] - '0'; {
+		case level <= 3:
+			priority = Error
+		case level == 4:
+			priority = Warn
+		case level == 7:
+			priority = Debug
+		}
+	}
+	// Uptime seconds stand in for the timestamp; there is no wall clock time
+	return &Entry{
+		Raw:       line,
+		Timestamp: m[2],
+		Priority:  priority,
+		Tag:       "kernel",
+		Message:   sanitizeText(m[3]),
+	}
+}
+
+// jsonObject decodes a line that is a whole JSON object.
+func jsonObject(line string) (map[string]any, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") {
+		return nil, false
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &obj); err != nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+func parseJSONLine(line string) *Entry {
+	obj, ok := jsonObject(line)
+	if !ok {
+		return unparsedEntry(line)
+	}
+	entry := &Entry{Raw: line, Priority: Info}
+	applyJSON(entry, obj, false)
+	return entry
+}
+
+// ApplyJSONMessage extracts the level, tag and fields of a message that is a
+// whole JSON object, as logged by structured loggers. The message becomes the
+// object's message when it has one. The logcat timestamp, PID and TID are kept
+// since the device's are authoritative; the replaced tag is kept as the
+// "logcatTag" field. It reports whether the message was JSON.
+func ApplyJSONMessage(e *Entry) bool {
+	if e.Unparsed {
+		return false
+	}
+	obj, ok := jsonObject(e.Message)
+	if !ok {
+		return false
+	}
+	applyJSON(e, obj, true)
+	return true
+}
+
+// JSON keys recognized as entry columns, in order of preference. Other keys
+// become fields.
+var (
+	jsonMessageKeys = []string{"message", "msg"}
+	jsonLevelKeys   = []string{"level", "severity", "priority", "lvl"}
+	jsonTagKeys     = []string{"tag", "logger", "logger_name"}
+	jsonTimeKeys    = []string{"time", "timestamp", "ts", "@timestamp"}
+	jsonPIDKeys     = []string{"pid"}
+	jsonTIDKeys     = []string{"tid", "thread"}
+)
+
+// applyJSON fills the entry's columns from the recognized keys of a JSON
+// object and stores the remaining keys as fields, nested objects under dotted
+// keys. With keepDevice set, time, PID and TID keys are fields too.
+func applyJSON(e *Entry, obj map[string]any, keepDevice bool) {
+	used := make(map[string]bool)
+	take := func(keys []string) (any, bool) {
+		for _, want := range keys {
+			for key := range obj {
+				if strings.EqualFold(key, want) && !used[key] {
+					used[key] = true
+					return obj[key], true
+				}
+			}
+		}
+		return nil, false
+	}
+
+	if v, ok := take(jsonMessageKeys); ok {
+		e.Message = sanitizeText(jsonString(v))
+	}
+	if v, ok := take(jsonLevelKeys); ok {
+		if p, ok := priorityFromName(jsonString(v)); ok {
+			e.Priority = p
+		} else {
+			e.SetField("level", jsonString(v))
+		}
+	}
+	if v, ok := take(jsonTagKeys); ok {
+		if keepDevice && e.Tag != "" {
+			e.SetField("logcatTag", e.Tag)
+		}
+		e.Tag = intern(sanitizeText(jsonString(v)))
+	}
+	if keepDevice {
+		setJSONFields(e, "", obj, used)
+		return
+	}
+	if v, ok := take(jsonTimeKeys); ok {
+		if t, ok := jsonTime(v); ok {
+			e.Time = t
+			e.Timestamp = t.Local().Format(timestampLayout)
+		}
+	}
+	if v, ok := take(jsonPIDKeys); ok {
+		e.PID = intern(jsonString(v))
+	}
+	if v, ok := take(jsonTIDKeys); ok {
+		e.TID = intern(jsonString(v))
+	}
+	setJSONFields(e, "", obj, used)
+}
+
+// setJSONFields stores the unused keys of a JSON object as fields, flattening
+// nested objects into "parent.child" keys so they can be filtered on.
+func setJSONFields(e *Entry, prefix string, obj map[string]any, used map[string]bool) {
+	for key, value := range obj {
+		if used[key] {
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			setJSONFields(e, prefix+key+".", nested, nil)
+			continue
+		}
+		e.SetField(prefix+key, jsonString(value))
+	}
+}
+
+// jsonString formats a JSON value as a field value: strings as is, numbers
+// without exponents, nested values as compact JSON.
+func jsonString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// jsonTime reads an RFC 3339 string or Unix time in seconds or milliseconds.
+func jsonTime(v any) (time.Time, bool) {
+	switch v := v.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, true
+		}
+	case float64:
+		if v > 1e12 {
+			return time.UnixMilli(int64(v)), true
+		}
+		sec := int64(v)
+		return time.Unix(sec, int64((v-float64(sec))*1e9)), true
+	}
+	return time.Time{}, false
+}
+
+// priorityFromName reads a level name as used by logging libraries, a logcat
+// priority letter, or an Android priority number (2 = verbose to 7 = assert).
+func priorityFromName(name string) (Priority, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "v", "verbose", "trace", "2":
+		return Verbose, true
+	case "d", "debug", "3":
+		return Debug, true
+	case "i", "info", "information", "4":
+		return Info, true
+	case "w", "warn", "warning", "5":
+		return Warn, true
+	case "e", "error", "err", "6":
+		return Error, true
+	case "f", "fatal", "critical", "crit":
+		return Fatal, true
+	case "a", "assert", "wtf", "7":
+		return Assert, true
+	}
+	return Unknown, false
+}
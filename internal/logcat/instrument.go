@@ -0,0 +1,103 @@
+package logcat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Status codes of `am instrument -r` test status blocks.
+const (
+	TestStarted          = 1
+	TestPassed           = 0
+	TestErrored          = -1
+	TestFailed           = -2
+	TestIgnored          = -3
+	TestAssumptionFailed = -4
+)
+
+// InstrumentStatus is a test status block from `am instrument -r` output.
+type InstrumentStatus struct {
+	Code  int
+	Class string
+	Test  string
+	// Current is the test's 1-based position among Total tests
+	Current int
+	Total   int
+	// Stack is the failure's stack trace
+	Stack string
+}
+
+// Name returns the test as "Class#test".
+func (s InstrumentStatus) Name() string {
+	return s.Class + "#" + s.Test
+}
+
+// ScanInstrumentation reads `am instrument -r` output and calls emit for each
+// test status block as it completes. It returns an error when the run itself
+// failed, e.g. because the runner is missing or the test process crashed.
+func ScanInstrumentation(r io.Reader, emit func(InstrumentStatus)) error {
+	const (
+		statusPrefix     = "INSTRUMENTATION_STATUS: "
+		statusCodePrefix = "INSTRUMENTATION_STATUS_CODE: "
+		resultPrefix     = "INSTRUMENTATION_RESULT: "
+		failedPrefix     = "INSTRUMENTATION_FAILED: "
+	)
+	values := map[string]string{}
+	result := map[string]string{}
+	// key is the key of the value being read; values such as stack span lines
+	key := ""
+	target := values
+	failed := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.HasPrefix(line, statusCodePrefix):
+			code, err := strconv.Atoi(strings.TrimSpace(line[len(statusCodePrefix):]))
+			if err == nil && values["test"] != "" {
+				status := InstrumentStatus{
+					Code:  code,
+					Class: values["class"],
+					Test:  values["test"],
+					Stack: strings.TrimSpace(values["stack"]),
+				}
+				status.Current, _ = strconv.Atoi(values["current"])
+				status.Total, _ = strconv.Atoi(values["numtests"])
+				emit(status)
+			}
+			values = map[string]string{}
+			key = ""
+		case strings.HasPrefix(line, statusPrefix), strings.HasPrefix(line, resultPrefix):
+			target = values
+			if strings.HasPrefix(line, resultPrefix) {
+				target = result
+			}
+			var value string
+			key, value, _ = strings.Cut(line[strings.Index(line, ": ")+2:], "=")
+			target[key] = value
+		case strings.HasPrefix(line, failedPrefix):
+			failed = line[len(failedPrefix):]
+			key = ""
+		case strings.HasPrefix(line, "INSTRUMENTATION_"):
+			key = ""
+		case key != "":
+			target[key] += "\n" + line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if failed != "" {
+		return fmt.Errorf("instrumentation failed: %s", failed)
+	}
+	if msg := result["shortMsg"]; msg != "" {
+		return fmt.Errorf("test run aborted: %s", strings.TrimSpace(msg))
+	}
+	return nil
+}
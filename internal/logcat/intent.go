@@ -0,0 +1,88 @@
+package logcat
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Intent is an intent logged by ActivityManager or the app, in the
+// Intent.toString form "{act=... dat=... cmp=...}".
+type Intent struct {
+	// Kind is "start" for activity starts, "broadcast" for broadcasts and
+	// "intent" for anything else
+	Kind       string
+	Action     string
+	Data       string
+	Categories string
+	Component  string
+	Package    string
+	Flags      string
+	// Extras is the logged extras bundle, or "yes" when only "(has extras)" is logged
+	Extras string
+}
+
+// intentRegex matches the braces of Intent.toString and the text before them.
+var intentRegex = regexp.MustCompile(`\{ ?((?:act|cat|dat|typ|flg|cmp|pkg)=.*)\}`)
+
+// ParseIntent reads an intent from a message like
+// "START u0 {act=android.intent.action.VIEW dat=https://example.com/... flg=0x10000000 cmp=com.example/.MainActivity (has extras)} from uid 10123".
+func ParseIntent(e *Entry) (Intent, bool) {
+	message := e.Message
+	// Skip the regex for the vast majority of lines
+	if !strings.Contains(message, "act=") && !strings.Contains(message, "cmp=") {
+		return Intent{}, false
+	}
+	match := intentRegex.FindStringSubmatchIndex(message)
+	if match == nil {
+		return Intent{}, false
+	}
+	intent := Intent{Kind: "intent"}
+	prefix := strings.ToLower(message[:match[0]])
+	switch {
+	case strings.Contains(prefix, "start u"), strings.Contains(prefix, "starting activity"):
+		intent.Kind = "start"
+	case strings.Contains(prefix, "broadcast"):
+		intent.Kind = "broadcast"
+	}
+
+	body := message[match[2]:match[3]]
+	// The intent ends at its closing brace; anything after it belongs to the message
+	if end := strings.Index(body, "}"); end >= 0 && !strings.Contains(body[:end], "{") {
+		body = body[:end]
+	}
+	if i := strings.Index(body, "(has extras)"); i >= 0 {
+		intent.Extras = "yes"
+		body = body[:i] + body[i+len("(has extras)"):]
+	}
+	for _, token := range strings.Fields(body) {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "act":
+			intent.Action = value
+		case "dat":
+			intent.Data = value
+		case "cat":
+			intent.Categories = strings.Trim(value, "[]")
+		case "cmp":
+			intent.Component = value
+		case "pkg":
+			intent.Package = value
+		case "flg":
+			intent.Flags = value
+		case "extras":
+			intent.Extras = value
+		}
+	}
+	if intent.Action == "" && intent.Component == "" {
+		return Intent{}, false
+	}
+	return intent, true
+}
+
+// Involves reports whether the intent targets or names the package.
+func (i Intent) Involves(pkg string) bool {
+	return strings.HasPrefix(i.Component, pkg+"/") || i.Package == pkg || strings.Contains(i.Data, pkg)
+}
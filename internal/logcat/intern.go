@@ -0,0 +1,13 @@
+package logcat
+
+import "unique"
+
+// intern returns the canonical copy of s. Tags and PIDs repeat across
+// thousands of entries; interned, equal values share one backing array, so
+// comparisons and map lookups keyed on them short-circuit on the pointer.
+func intern(s string) string {
+	if s == "" {
+		return s
+	}
+	return unique.Make(s).Value()
+}
@@ -0,0 +1,122 @@
+package logcat
+
+import (
+	"regexp"
+	"strings"
+)
+
+// JobState is a background job's state after a transition.
+type JobState int
+
+const (
+	JobScheduled JobState = iota + 1
+	JobStarted
+	JobSucceeded
+	JobFailed
+	// JobRetried is a WorkManager worker that returned Result.retry()
+	JobRetried
+	JobStopped
+)
+
+// String returns the state as shown in the jobs timeline.
+func (s JobState) String() string {
+	switch s {
+	case JobScheduled:
+		return "scheduled"
+	case JobStarted:
+		return "started"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobRetried:
+		return "retry"
+	case JobStopped:
+		return "stopped"
+	}
+	return "?"
+}
+
+// JobEvent is a WorkManager or JobScheduler job transition parsed from a log line.
+type JobEvent struct {
+	State JobState
+	// ID identifies the job across lines: the WorkSpec ID for WorkManager
+	// and "#uid/jobId" for JobScheduler
+	ID string
+	// Name is the worker's tags or the job service, when logged
+	Name string
+}
+
+// workID matches a WorkSpec ID, optionally inside WorkGenerationalId(workSpecId=...)
+const workID = `(?:WorkGenerationalId\(workSpecId=)?([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`
+
+var (
+	// WM-WorkerWrapper: Worker result SUCCESS for Work [ id=..., tags={ com.example.SyncWorker } ]
+	workResultRegex = regexp.MustCompile(`Worker result (SUCCESS|FAILURE|RETRY) for Work \[ id=([0-9a-f-]+), tags=\{ ([^}]*?) \}`)
+	// WM-SystemJobScheduler: Scheduling work ID ...Job ID 12
+	workScheduleRegex = regexp.MustCompile(`(?:Scheduling work ID|Scheduling work with workSpecId) ` + workID)
+	// WM-SystemJobService: onStartJob for ..., WM-GreedyScheduler: Starting work for ...
+	workStartRegex = regexp.MustCompile(`(?:onStartJob for|Starting work for) ` + workID)
+	// WM-SystemJobService: onStopJob for ..., WM-GreedyScheduler: Stopping work for ...
+	workStopRegex = regexp.MustCompile(`(?:onStopJob for|Stopping work for|Cancelling work ID) ` + workID)
+	// JobScheduler: ... JobStatus{1a2b3c #u0a123/42 com.example/.SyncJobService ...}
+	jobStatusRegex = regexp.MustCompile(`JobStatus\{\w+ (#u\w+/-?\d+) (\S+)`)
+)
+
+// ParseJobEvent reads a job transition from WorkManager's WM-* tags and from
+// JobScheduler lines that log a JobStatus.
+func ParseJobEvent(e *Entry) (JobEvent, bool) {
+	message := e.Message
+	if strings.HasPrefix(e.Tag, "WM-") {
+		if match := workResultRegex.FindStringSubmatch(message); match != nil {
+			state := JobSucceeded
+			switch match[1] {
+			case "FAILURE":
+				state = JobFailed
+			case "RETRY":
+				state = JobRetried
+			}
+			return JobEvent{State: state, ID: match[2], Name: match[3]}, true
+		}
+		for _, transition := range []struct {
+			regex *regexp.Regexp
+			state JobState
+		}{
+			{workScheduleRegex, JobScheduled},
+			{workStartRegex, JobStarted},
+			{workStopRegex, JobStopped},
+		} {
+			if match := transition.regex.FindStringSubmatch(message); match != nil {
+				return JobEvent{State: transition.state, ID: match[1]}, true
+			}
+		}
+		return JobEvent{}, false
+	}
+
+	if !strings.HasPrefix(e.Tag, "JobScheduler") && e.Tag != "JobServiceContext" {
+		return JobEvent{}, false
+	}
+	match := jobStatusRegex.FindStringSubmatch(message)
+	if match == nil {
+		return JobEvent{}, false
+	}
+	// The platform logs JobStatus in many debug lines; the state is read
+	// from the words around it
+	lower := strings.ToLower(message[:strings.Index(message, "JobStatus{")])
+	var state JobState
+	switch {
+	case strings.Contains(lower, "time-out"), strings.Contains(lower, "timed out"), strings.Contains(lower, "fail"):
+		state = JobFailed
+	case strings.Contains(lower, "cancel"), strings.Contains(lower, "stop"):
+		state = JobStopped
+	case strings.Contains(lower, "finish"), strings.Contains(lower, "complete"):
+		state = JobSucceeded
+	case strings.Contains(lower, "start"), strings.Contains(lower, "running"), strings.Contains(lower, "execut"):
+		state = JobStarted
+	case strings.Contains(lower, "schedul"), strings.Contains(lower, "enqueue"):
+		state = JobScheduled
+	default:
+		return JobEvent{}, false
+	}
+	return JobEvent{State: state, ID: match[1], Name: match[2]}, true
+}
@@ -4,11 +4,14 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
+	"unsafe"
 
 	"github.com/mikaelreiersolmoen/logdog/internal/adb"
 )
@@ -23,18 +26,27 @@ const (
 	Warn
 	Error
 	Fatal
+	Assert
 	Unknown
 )
 
 // Entry represents a parsed logcat entry
 type Entry struct {
+	// ID is assigned by the consumer in arrival order, starting at 1. Unlike the
+	// pointer, it identifies the entry across copies and re-parses.
+	ID        uint64
 	Timestamp string
+	Time      time.Time
 	PID       string
 	TID       string
 	Priority  Priority
 	Tag       string
 	Message   string
 	Raw       string
+	Fields    map[string]string
+	Source    string
+	// Unparsed is set for lines that are not in threadtime format
+	Unparsed bool
 }
 
 // PriorityFromChar converts a logcat priority character to Priority
@@ -52,6 +64,8 @@ func PriorityFromChar(c rune) Priority {
 		return Error
 	case 'F':
 		return Fatal
+	case 'A':
+		return Assert
 	default:
 		return Unknown
 	}
@@ -72,6 +86,8 @@ func (p Priority) String() string {
 		return "E"
 	case Fatal:
 		return "F"
+	case Assert:
+		return "A"
 	default:
 		return "?"
 	}
@@ -92,11 +108,61 @@ func (p Priority) Name() string {
 		return "Error"
 	case Fatal:
 		return "Fatal"
+	case Assert:
+		return "Assert"
 	default:
 		return "Unknown"
 	}
 }
 
+// AttachTo makes an unparsed entry a continuation of prev by inheriting its
+// metadata, so it groups, filters and sorts with the entry it belongs to.
+func (e *Entry) AttachTo(prev *Entry) {
+	e.Timestamp = prev.Timestamp
+	e.Time = prev.Time
+	e.PID = prev.PID
+	e.TID = prev.TID
+	e.Priority = prev.Priority
+	e.Tag = prev.Tag
+}
+
+// entryOverhead approximates the memory an entry costs beyond its strings: the
+// struct itself and the pointer the log keeps to it.
+const entryOverhead = int(unsafe.Sizeof(Entry{})) + int(unsafe.Sizeof(&Entry{}))
+
+// Size approximates the bytes the entry holds, counting the raw line and the
+// parsed fields separately even where they share memory. It is meant for
+// budgeting the log buffer, not for exact accounting.
+func (e *Entry) Size() int {
+	size := entryOverhead + len(e.Timestamp) + len(e.PID) + len(e.TID) + len(e.Tag) + len(e.Message) + len(e.Raw) + len(e.Source)
+	for key, value := range e.Fields {
+		// Map buckets cost about as much again as the key and value headers
+		size += len(key) + len(value) + 64
+	}
+	return size
+}
+
+// UnparsedReason explains why ParseLine can't read a line as threadtime, or
+// returns "" when it can. Buffer markers like "--------- beginning of main"
+// are part of logcat's output and are not reported either.
+func UnparsedReason(line string) string {
+	if strings.HasPrefix(line, "--------- ") {
+		return ""
+	}
+	parts := strings.Fields(line)
+	switch {
+	case len(parts) == 0:
+		return "blank line"
+	case len(parts) < 6:
+		return fmt.Sprintf("%d fields, threadtime has at least 6", len(parts))
+	case !isNumeric(parts[2]) || !isNumeric(parts[3]):
+		return "PID or TID is not a number"
+	case len(parts[4]) != 1:
+		return fmt.Sprintf("priority %q is not a single letter", parts[4])
+	}
+	return ""
+}
+
 // ParseLine parses a logcat line in threadtime format
 // Format: MM-DD HH:MM:SS.mmm PID TID P TAG: MESSAGE
 func ParseLine(line string) (*Entry, error) {
@@ -112,25 +178,28 @@ func ParseLine(line string) (*Entry, error) {
 	if len(parts) < 6 {
 		// Malformed line, return as-is with Unknown priority
 		entry.Priority = Unknown
+		entry.Unparsed = true
 		entry.Message = sanitizeText(line)
 		return entry, nil
 	}
 	if !isNumeric(parts[2]) || !isNumeric(parts[3]) || len(parts[4]) != 1 {
 		// Not threadtime format, return as-is with Unknown priority
 		entry.Priority = Unknown
+		entry.Unparsed = true
 		entry.Message = sanitizeText(line)
 		return entry, nil
 	}
 
 	// Parse timestamp (MM-DD HH:MM:SS.mmm)
 	if len(parts) >= 2 {
-		entry.Timestamp = parts[0] + " " + parts[1]
+		entry.Timestamp = timestampField(line, parts[0], parts[1])
+		entry.Time = ParseTimestamp(entry.Timestamp)
 	}
 
 	// Parse PID, TID
 	if len(parts) >= 4 {
-		entry.PID = parts[2]
-		entry.TID = parts[3]
+		entry.PID = intern(parts[2])
+		entry.TID = intern(parts[3])
 	}
 
 	// Parse priority
@@ -152,7 +221,7 @@ func ParseLine(line string) (*Entry, error) {
 		colonIdx := strings.Index(trimmedRemainder, ":")
 		if colonIdx >= 0 {
 			tag := strings.TrimSpace(trimmedRemainder[:colonIdx])
-			entry.Tag = sanitizeText(tag)
+			entry.Tag = intern(sanitizeText(tag))
 			if colonIdx+1 < len(trimmedRemainder) {
 				message := trimmedRemainder[colonIdx+1:]
 				if len(message) > 0 && message[0] == ' ' {
@@ -168,6 +237,36 @@ func ParseLine(line string) (*Entry, error) {
 	return entry, nil
 }
 
+// timestampField returns the "date time" timestamp, sliced from the line when
+// the two fields are separated by a single space to save an allocation.
+func timestampField(line, date, clock string) string {
+	start := strings.Index(line, date)
+	end := start + len(date) + 1 + len(clock)
+	if start >= 0 && end <= len(line) && line[start+len(date)] == ' ' && line[start+len(date)+1:end] == clock {
+		return line[start:end]
+	}
+	return date + " " + clock
+}
+
+// timestampLayout is the threadtime timestamp format, which omits the year
+const timestampLayout = "01-02 15:04:05.000"
+
+// ParseTimestamp parses a threadtime timestamp in local time, assuming the current year.
+// Returns the zero time when the timestamp is malformed.
+func ParseTimestamp(timestamp string) time.Time {
+	t, err := time.ParseInLocation(timestampLayout, timestamp, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	now := time.Now()
+	year := now.Year()
+	// Entries from late December read in early January belong to the previous year
+	if t.Month() > now.Month()+1 {
+		year--
+	}
+	return t.AddDate(year, 0, 0)
+}
+
 func isNumeric(s string) bool {
 	if s == "" {
 		return false
@@ -210,23 +309,138 @@ func (e *Entry) FormatPlain() string {
 	)
 }
 
+// FormatThreadtime returns the entry as logcat threadtime lines, so exports can
+// be fed to other logcat tools. Fields the source line lacked are filled in with
+// a zero timestamp, PID and TID and info priority. A multi-line message becomes
+// one line per message line, as logcat prints it.
+func (e *Entry) FormatThreadtime() string {
+	timestamp := e.Timestamp
+	if timestamp == "" {
+		timestamp = "01-01 00:00:00.000"
+	}
+	pid, tid := e.PID, e.TID
+	if pid == "" {
+		pid = "0"
+	}
+	if tid == "" {
+		tid = "0"
+	}
+	priority := e.Priority
+	if priority == Unknown {
+		priority = Info
+	}
+	prefix := fmt.Sprintf("%s %5s %5s %s %-8s: ", timestamp, pid, tid, priority, strings.TrimRight(e.Tag, " "))
+
+	lines := strings.Split(strings.TrimRight(e.Message, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + strings.TrimRight(line, "\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Manager manages the logcat process
 type Manager struct {
 	cmd              *exec.Cmd
+	filePath         string
+	file             *os.File
+	fileFormat       Format
 	appID            string
 	deviceSerial     string
 	stopChan         chan struct{}
 	monitorStopChan  chan struct{}
 	tailSize         int
-	currentPID       string
-	statusChan       chan string
-	deviceStatusChan chan string
+	currentPIDs      []string
+	mainPID          string
+	pidFilter        map[string]bool
+	clientPIDFilter  bool
+	statusChan       chan StatusUpdate
+	deviceStatusChan chan StatusUpdate
 	lineChan         chan<- string
 	scanner          *bufio.Scanner
 	readStop         chan struct{}
 	readDone         chan struct{}
 	readMu           sync.Mutex
 	cmdMu            sync.Mutex
+	stopOnce         sync.Once
+	stopErr          error
+	session          *SessionLock
+	otherInstance    int
+	hostLogcats      int
+	scanErr          error
+}
+
+// State is the state of the filtered app or of the device
+type State int
+
+// App states are reported on StatusChan, device states on DeviceStatusChan
+const (
+	StateUnknown State = iota
+	StateRunning
+	StateStopped
+	StateReconnecting
+	StateWaiting
+	StateError
+	StateConnected
+	StateDisconnected
+)
+
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "running"
+	case StateStopped:
+		return "stopped"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateWaiting:
+		return "waiting"
+	case StateError:
+		return "error"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	}
+	return "unknown"
+}
+
+// StatusUpdate is a state transition reported by the manager, stamped with when it happened
+type StatusUpdate struct {
+	State State
+	At    time.Time
+	// PID is the app's process IDs, set on StateRunning updates
+	PID string
+	// MainPID is the app's main process, set on StateRunning updates while it runs
+	MainPID string
+	// Err is why the manager stopped following the app, set on StateError updates
+	Err error
+}
+
+// statusBufferSize is how many updates wait for the UI before the oldest are dropped
+const statusBufferSize = 10
+
+// Default intervals used to watch the filtered app's process
+const (
+	DefaultPIDCheckInterval = 2 * time.Second
+	DefaultPIDPollInterval  = 1 * time.Second
+)
+
+var (
+	pidCheckInterval = DefaultPIDCheckInterval
+	pidPollInterval  = DefaultPIDPollInterval
+)
+
+// SetPIDMonitorIntervals sets how often a running app's PID is checked and how
+// often a stopped app is polled for a new PID. Non-positive values keep the defaults.
+func SetPIDMonitorIntervals(check, poll time.Duration) {
+	pidCheckInterval = DefaultPIDCheckInterval
+	if check > 0 {
+		pidCheckInterval = check
+	}
+	pidPollInterval = DefaultPIDPollInterval
+	if poll > 0 {
+		pidPollInterval = poll
+	}
 }
 
 // TailAll indicates that all available log entries should be loaded.
@@ -236,9 +450,32 @@ const (
 	scannerBufferSize    = 64 * 1024
 	maxScannerBufferSize = 1024 * 1024
 	readBatchSize        = 100
-	readTickInterval     = 33 * time.Millisecond
+	// finalFlushTimeout bounds how long a stopped reader waits to hand on its
+	// last lines, in case nothing reads them anymore
+	finalFlushTimeout = time.Second
 )
 
+// DefaultReadInterval is how often readers hand batched lines on, ~30 FPS
+const DefaultReadInterval = 33 * time.Millisecond
+
+var readInterval atomic.Int64
+
+// SetReadInterval sets how often readers hand batched lines on, including
+// readers already running. Non-positive values restore the default.
+func SetReadInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReadInterval
+	}
+	readInterval.Store(int64(interval))
+}
+
+func currentReadInterval() time.Duration {
+	if interval := time.Duration(readInterval.Load()); interval > 0 {
+		return interval
+	}
+	return DefaultReadInterval
+}
+
 // NewManager creates a new logcat manager
 func NewManager(appID string, tailSize int) *Manager {
 	if tailSize < TailAll {
@@ -249,8 +486,8 @@ func NewManager(appID string, tailSize int) *Manager {
 		stopChan:         make(chan struct{}),
 		monitorStopChan:  make(chan struct{}),
 		tailSize:         tailSize,
-		statusChan:       make(chan string, 10),
-		deviceStatusChan: make(chan string, 10),
+		statusChan:       make(chan StatusUpdate, statusBufferSize),
+		deviceStatusChan: make(chan StatusUpdate, statusBufferSize),
 	}
 }
 
@@ -261,37 +498,17 @@ func (m *Manager) SetDevice(serial string) {
 
 // Start starts the logcat process
 func (m *Manager) Start() error {
-	devices, err := adb.GetDevices()
-	if err != nil {
-		return err
+	if m.filePath != "" {
+		return m.startFile()
 	}
-	if m.deviceSerial != "" {
-		var target *adb.Device
-		for i := range devices {
-			if devices[i].Serial == m.deviceSerial {
-				target = &devices[i]
-				break
-			}
-		}
-		if target == nil {
-			return fmt.Errorf("device %s not found", m.deviceSerial)
-		}
-		if target.Status != "device" {
-			return fmt.Errorf("device %s not online (status: %s)", target.Serial, target.Status)
-		}
-	} else {
-		hasOnline := false
-		for _, device := range devices {
-			if device.Status == "device" {
-				hasOnline = true
-				break
-			}
-		}
-		if !hasOnline {
-			return fmt.Errorf("no online devices found")
-		}
+	if err := adb.RequireDevice(m.deviceSerial); err != nil {
+		return err
 	}
 
+	// A second logdog on the same stream still works, it is only reported
+	m.session, m.otherInstance, _ = AcquireSession(m.deviceSerial, m.appID)
+	m.hostLogcats = adb.HostLogcatProcesses(m.deviceSerial)
+
 	// Build logcat command with app ID filter
 	args := []string{}
 	if m.deviceSerial != "" {
@@ -301,21 +518,26 @@ func (m *Manager) Start() error {
 	if m.tailSize > 0 {
 		args = append(args, "-T", fmt.Sprintf("%d", m.tailSize))
 	} else if m.tailSize == 0 {
-		args = append(args, "-T", "0")
+		args = append(args, m.sinceNowArgs()...)
 	}
 	if m.appID != "" {
-		pid, err := m.getPID()
+		// Old logcat ignores or rejects --pid, so filter by the PID column ourselves
+		if level, err := adb.APILevel(m.deviceSerial); err == nil && level < adb.MinPIDFilterAPILevel {
+			m.clientPIDFilter = true
+		}
+		pids, mainPID, err := m.getPIDs()
 		if err != nil {
 			return err
 		}
-		if pid != "" {
-			m.currentPID = pid
-			args = append(args, "--pid="+pid)
-			m.statusChan <- "running"
+		if len(pids) > 0 {
+			m.currentPIDs, m.mainPID = pids, mainPID
+			args = append(args, m.pidArgs()...)
+			m.sendStatus(StateRunning, nil)
 		}
 	}
 
-	cmd := exec.Command("adb", args...)
+	cmd := adb.Command(args...)
+	setProcessGroup(cmd)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to get stdout pipe: %w", err)
@@ -324,6 +546,7 @@ func (m *Manager) Start() error {
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start logcat: %w", err)
 	}
+	trackChild(cmd.Process.Pid)
 
 	scanner := newScanner(stdout)
 
@@ -334,55 +557,168 @@ func (m *Manager) Start() error {
 	m.setScanner(scanner)
 
 	// Start PID monitoring if filtering by app
-	if m.appID != "" && m.currentPID != "" {
+	if m.appID != "" && len(m.currentPIDs) > 0 {
 		go m.monitorPID()
 	}
 	if m.deviceSerial != "" {
-		m.sendDeviceStatus("connected")
+		m.sendDeviceStatus(StateConnected)
 		go m.monitorDevice()
 	}
 
 	return nil
 }
 
-// getPID gets the PID for the app package name
-func (m *Manager) getPID() (string, error) {
-	return adb.GetPID(m.deviceSerial, m.appID)
+// getPIDs gets the PIDs for the app package name and its main process
+func (m *Manager) getPIDs() ([]string, string, error) {
+	return adb.GetPIDs(m.deviceSerial, m.appID)
+}
+
+// pidArgs returns the logcat arguments that restrict output to the app's processes.
+// logcat --pid accepts a single PID and needs API 24, so with several processes or
+// on older devices the PID column is filtered client-side instead.
+func (m *Manager) pidArgs() []string {
+	var args []string
+	var filter map[string]bool
+	if len(m.currentPIDs) == 1 && !m.clientPIDFilter {
+		args = append(args, "--pid="+m.currentPIDs[0])
+	} else if len(m.currentPIDs) > 0 {
+		filter = make(map[string]bool, len(m.currentPIDs))
+		for _, pid := range m.currentPIDs {
+			filter[pid] = true
+		}
+	}
+
+	m.readMu.Lock()
+	m.pidFilter = filter
+	m.readMu.Unlock()
+	return args
+}
+
+// linePID returns the PID column of a threadtime line without a full parse.
+func linePID(line string) string {
+	field := 0
+	for i := 0; i < len(line); {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(line) && line[i] != ' ' {
+			i++
+		}
+		if field == 2 {
+			return line[start:i]
+		}
+		field++
+	}
+	return ""
 }
 
 // monitorPID monitors the current PID and restarts logcat when the app restarts
 func (m *Manager) monitorPID() {
-	checkInterval := 2 * time.Second
-	pollInterval := 1 * time.Second
+	checkInterval := pidCheckInterval
+	pollInterval := pidPollInterval
 
 	for {
 		// Monitor until PID stops
-		adb.MonitorPID(m.deviceSerial, m.currentPID, checkInterval, m.monitorStopChan)
+		adb.MonitorPIDs(m.deviceSerial, m.appID, m.currentPIDs, checkInterval, m.monitorStopChan)
 
 		select {
 		case <-m.monitorStopChan:
 			return
 		default:
+			// One of the app's processes exited or a new one started; follow
+			// the ones running now
+			if pids, mainPID, err := m.getPIDs(); err == nil && len(pids) > 0 {
+				m.currentPIDs, m.mainPID = pids, mainPID
+				if err := m.restart(); err != nil {
+					m.sendStatus(StateError, err)
+					return
+				}
+				m.sendStatus(StateRunning, nil)
+				continue
+			}
+
 			// App has stopped
-			m.statusChan <- "stopped"
-			m.statusChan <- "reconnecting"
+			m.sendStatus(StateStopped, nil)
+			m.sendStatus(StateReconnecting, nil)
 
 			// Wait for app to restart
-			newPID := adb.WaitForPID(m.deviceSerial, m.appID, pollInterval, m.monitorStopChan)
-			if newPID == "" {
+			newPIDs, mainPID := adb.WaitForPIDs(m.deviceSerial, m.appID, pollInterval, m.monitorStopChan)
+			if len(newPIDs) == 0 {
 				// Monitoring stopped
 				return
 			}
 
-			// App has restarted with new PID
-			m.currentPID = newPID
+			// App has restarted with new PIDs
+			m.currentPIDs, m.mainPID = newPIDs, mainPID
 			if err := m.restart(); err != nil {
-				m.statusChan <- "error"
+				m.sendStatus(StateError, err)
 				return
 			}
-			m.statusChan <- "running"
+			m.sendStatus(StateRunning, nil)
+		}
+	}
+}
+
+// WaitForApp blocks until the filtered app is running. It returns false if the
+// manager is stopped first.
+func (m *Manager) WaitForApp() bool {
+	pids, _ := adb.WaitForPIDs(m.deviceSerial, m.appID, pidPollInterval, m.monitorStopChan)
+	return len(pids) > 0
+}
+
+// sinceNowArgs returns logcat arguments that skip the backlog and only print
+// lines from now on, using the device clock as an epoch timestamp.
+func (m *Manager) sinceNowArgs() []string {
+	now, err := adb.DeviceTime(m.deviceSerial)
+	if err != nil {
+		return []string{"-T", "0"}
+	}
+	return []string{"-T", fmt.Sprintf("%d.%03d", now.Unix(), now.Nanosecond()/int(time.Millisecond))}
+}
+
+// Backlog dumps the most recent count lines of the log buffer, or all of it
+// for TailAll, restricted to the app's processes when filtering by app. It
+// runs a separate logcat that exits after the dump, so the stream keeps going.
+// With client-side PID filtering, count includes other processes' lines.
+func (m *Manager) Backlog(count int) ([]string, error) {
+	pids := m.currentPIDs
+	if m.appID != "" && len(pids) == 0 {
+		return nil, adb.ErrAppNotRunning
+	}
+
+	args := []string{}
+	if m.deviceSerial != "" {
+		args = append(args, "-s", m.deviceSerial)
+	}
+	args = append(args, "logcat", "-v", "threadtime", "-d")
+	if count > 0 {
+		args = append(args, "-t", fmt.Sprintf("%d", count))
+	}
+	var filter map[string]bool
+	if len(pids) == 1 && !m.clientPIDFilter {
+		args = append(args, "--pid="+pids[0])
+	} else if len(pids) > 0 {
+		filter = make(map[string]bool, len(pids))
+		for _, pid := range pids {
+			filter[pid] = true
 		}
 	}
+
+	out, err := adb.Command(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log backlog: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" || filter != nil && !filter[linePID(line)] {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
 }
 
 // restart stops the current logcat process and starts a new one with the current PID
@@ -395,12 +731,12 @@ func (m *Manager) restart() error {
 	if m.deviceSerial != "" {
 		args = append(args, "-s", m.deviceSerial)
 	}
-	args = append(args, "logcat", "-v", "threadtime", "-T", "0") // Use -T 0 for restarts to avoid duplicates
-	if m.currentPID != "" {
-		args = append(args, "--pid="+m.currentPID)
-	}
+	args = append(args, "logcat", "-v", "threadtime")
+	args = append(args, m.sinceNowArgs()...) // Skip the backlog on restarts to avoid duplicates
+	args = append(args, m.pidArgs()...)
 
-	cmd := exec.Command("adb", args...)
+	cmd := adb.Command(args...)
+	setProcessGroup(cmd)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to get stdout pipe: %w", err)
@@ -409,6 +745,7 @@ func (m *Manager) restart() error {
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start logcat: %w", err)
 	}
+	trackChild(cmd.Process.Pid)
 
 	scanner := newScanner(stdout)
 
@@ -421,20 +758,95 @@ func (m *Manager) restart() error {
 	return nil
 }
 
+// DeviceSerial returns the serial of the device this manager reads from, or "" for the default device
+func (m *Manager) DeviceSerial() string {
+	return m.deviceSerial
+}
+
+// ClockSkew returns how far the device clock is ahead of the host clock
+func (m *Manager) ClockSkew() (time.Duration, error) {
+	return adb.ClockSkew(m.deviceSerial)
+}
+
+// ResumedActivity returns the component of the device's foreground activity
+func (m *Manager) ResumedActivity() (string, error) {
+	return adb.ResumedActivity(m.deviceSerial)
+}
+
+// DeviceInfo returns the device's model, build and other properties for bug reports
+func (m *Manager) DeviceInfo() (string, error) {
+	return adb.DeviceInfo(m.deviceSerial)
+}
+
+// PackageVersion returns the installed version of appID on the device
+func (m *Manager) PackageVersion(appID string) (string, error) {
+	return adb.PackageVersion(m.deviceSerial, appID)
+}
+
+// Screenshot returns a PNG screenshot of the device
+func (m *Manager) Screenshot() ([]byte, error) {
+	return adb.Screenshot(m.deviceSerial)
+}
+
+// DefaultMonkeyEvents is the number of events a monkey run sends unless told otherwise
+const DefaultMonkeyEvents = adb.DefaultMonkeyEvents
+
+// RunMonkey sends events random events to appID on the device and returns the run's summary
+func (m *Manager) RunMonkey(appID string, events int, seed int64) (string, error) {
+	return adb.RunMonkey(m.deviceSerial, appID, events, seed)
+}
+
+// StopMonkey stops the monkey running on the device
+func (m *Manager) StopMonkey() error {
+	return adb.StopMonkey(m.deviceSerial)
+}
+
+// InstrumentationRunner returns the instrumentation runner installed for appID's tests
+func (m *Manager) InstrumentationRunner(appID string) (string, error) {
+	return adb.InstrumentationRunner(m.deviceSerial, appID)
+}
+
+// StartInstrumentation starts runner on the device and returns the command and its raw output
+func (m *Manager) StartInstrumentation(runner string) (*exec.Cmd, io.Reader, error) {
+	return adb.StartInstrumentation(m.deviceSerial, runner)
+}
+
 // StatusChan returns the channel for receiving status updates
-func (m *Manager) StatusChan() <-chan string {
+func (m *Manager) StatusChan() <-chan StatusUpdate {
 	return m.statusChan
 }
 
 // DeviceStatusChan returns the channel for receiving device connection updates.
-func (m *Manager) DeviceStatusChan() <-chan string {
+func (m *Manager) DeviceStatusChan() <-chan StatusUpdate {
 	return m.deviceStatusChan
 }
 
-func (m *Manager) sendDeviceStatus(status string) {
-	select {
-	case m.deviceStatusChan <- status:
-	default:
+func (m *Manager) sendStatus(state State, err error) {
+	update := StatusUpdate{State: state, At: time.Now(), Err: err}
+	if state == StateRunning {
+		update.PID = strings.Join(m.currentPIDs, ",")
+		update.MainPID = m.mainPID
+	}
+	publish(m.statusChan, update)
+}
+
+func (m *Manager) sendDeviceStatus(state State) {
+	publish(m.deviceStatusChan, StatusUpdate{State: state, At: time.Now()})
+}
+
+// publish sends update without blocking. When the UI has fallen behind, the
+// oldest pending update is dropped so the latest state always gets through.
+func publish(ch chan StatusUpdate, update StatusUpdate) {
+	for {
+		select {
+		case ch <- update:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
 	}
 }
 
@@ -442,7 +854,7 @@ func (m *Manager) monitorDevice() {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
-	lastStatus := ""
+	lastState := StateUnknown
 
 	for {
 		select {
@@ -451,27 +863,27 @@ func (m *Manager) monitorDevice() {
 		default:
 		}
 
-		status := "disconnected"
+		state := StateDisconnected
 		devices, err := adb.GetDevices()
 		if err == nil {
 			for _, device := range devices {
 				if device.Serial == m.deviceSerial {
 					if device.Status == "device" {
-						status = "connected"
+						state = StateConnected
 					}
 					break
 				}
 			}
 		}
 
-		if status != lastStatus {
-			m.sendDeviceStatus(status)
-			if status == "disconnected" {
+		if state != lastState {
+			m.sendDeviceStatus(state)
+			if state == StateDisconnected {
 				_ = m.stopProcess()
-			} else if status == "connected" && lastStatus == "disconnected" && m.appID == "" {
+			} else if state == StateConnected && lastState == StateDisconnected && m.appID == "" {
 				_ = m.restart()
 			}
-			lastStatus = status
+			lastState = state
 		}
 
 		select {
@@ -506,6 +918,10 @@ func (m *Manager) readLinesInternal(scanner *bufio.Scanner, lineChan chan<- stri
 	rawLines := make(chan string, readBatchSize*2)
 	errChan := make(chan error, 1)
 
+	m.readMu.Lock()
+	pidFilter := m.pidFilter
+	m.readMu.Unlock()
+
 	go func() {
 		defer close(rawLines)
 		defer func() {
@@ -516,6 +932,9 @@ func (m *Manager) readLinesInternal(scanner *bufio.Scanner, lineChan chan<- stri
 		}()
 		for scanner.Scan() {
 			line := scanner.Text()
+			if pidFilter != nil && !pidFilter[linePID(line)] {
+				continue
+			}
 			select {
 			case rawLines <- line:
 			case <-readStop:
@@ -528,19 +947,22 @@ func (m *Manager) readLinesInternal(scanner *bufio.Scanner, lineChan chan<- stri
 
 	// Use a buffer to batch lines
 	batch := make([]string, 0, readBatchSize)
-	ticker := time.NewTicker(readTickInterval) // ~30 FPS
+	interval := currentReadInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	flush := func() bool {
 		if len(batch) == 0 {
 			return true
 		}
-		for _, line := range batch {
+		for i, line := range batch {
 			select {
 			case lineChan <- line:
 			case <-readStop:
+				batch = batch[i:]
 				return false
 			case <-m.stopChan:
+				batch = batch[i:]
 				return false
 			}
 		}
@@ -548,19 +970,51 @@ func (m *Manager) readLinesInternal(scanner *bufio.Scanner, lineChan chan<- stri
 		return true
 	}
 
+	// finalFlush hands on the pending lines once reading stops. The stop
+	// channels are closed by then, so it waits for lineChan alone.
+	finalFlush := func() {
+	drain:
+		for {
+			select {
+			case line, ok := <-rawLines:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, line)
+			default:
+				break drain
+			}
+		}
+		timeout := time.NewTimer(finalFlushTimeout)
+		defer timeout.Stop()
+		for _, line := range batch {
+			select {
+			case lineChan <- line:
+			case <-timeout.C:
+				return
+			}
+		}
+		batch = batch[:0]
+	}
+
 	for {
 		select {
 		case <-m.stopChan:
-			_ = flush()
+			finalFlush()
 			return
 		case <-readStop:
-			_ = flush()
+			finalFlush()
 			return
 		case line, ok := <-rawLines:
 			if !ok {
-				_ = flush()
+				finalFlush()
 				select {
-				case <-errChan:
+				case err := <-errChan:
+					if err != nil {
+						m.readMu.Lock()
+						m.scanErr = err
+						m.readMu.Unlock()
+					}
 				default:
 				}
 				return
@@ -568,29 +1022,61 @@ func (m *Manager) readLinesInternal(scanner *bufio.Scanner, lineChan chan<- stri
 			batch = append(batch, line)
 			if len(batch) >= readBatchSize {
 				if !flush() {
+					finalFlush()
 					return
 				}
 			}
 		case <-ticker.C:
 			if !flush() {
+				finalFlush()
 				return
 			}
+			if next := currentReadInterval(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
 		}
 	}
 }
 
-// Stop stops the logcat process and monitoring
+// Stop stops the logcat process and monitoring and waits for the reader to
+// deliver its last lines, for up to a second if nothing reads them. Stopping
+// again does nothing
 func (m *Manager) Stop() error {
+	m.stopOnce.Do(func() {
+		m.readMu.Lock()
+		done := m.readDone
+		if m.readStop != nil {
+			close(m.readStop)
+			m.readStop = nil
+		}
+		m.readMu.Unlock()
+
+		close(m.stopChan)
+		close(m.monitorStopChan)
+		m.session.Release()
+		m.stopErr = m.stopProcess()
+		if done != nil {
+			<-done
+		}
+		m.closeFile()
+	})
+	return m.stopErr
+}
+
+// ScanError returns the error that stopped reading the last logcat stream,
+// such as a line over the 1MB scanner limit, or nil.
+func (m *Manager) ScanError() error {
 	m.readMu.Lock()
-	if m.readStop != nil {
-		close(m.readStop)
-		m.readStop = nil
-	}
-	m.readMu.Unlock()
+	defer m.readMu.Unlock()
+	return m.scanErr
+}
 
-	close(m.stopChan)
-	close(m.monitorStopChan)
-	return m.stopProcess()
+// Duplicates reports the PID of another logdog already streaming the same
+// device and app, and how many adb logcat clients for the device were running
+// before Start. Both are zero when the stream is not duplicated.
+func (m *Manager) Duplicates() (otherInstance, hostLogcats int) {
+	return m.otherInstance, m.hostLogcats
 }
 
 func newScanner(r io.Reader) *bufio.Scanner {
@@ -642,14 +1128,19 @@ func (m *Manager) startReader(scanner *bufio.Scanner) <-chan struct{} {
 }
 
 func (m *Manager) stopProcess() error {
+	// Forget the process, so its group is never killed again after its PID is reused
 	m.cmdMu.Lock()
 	cmd := m.cmd
+	m.cmd = nil
 	m.cmdMu.Unlock()
 
 	if cmd == nil || cmd.Process == nil {
 		return nil
 	}
 
-	_ = cmd.Process.Kill()
-	return cmd.Wait()
+	// Kill the whole group, so nothing adb spawned outlives it
+	_ = killProcessGroup(cmd.Process.Pid)
+	err := cmd.Wait()
+	untrackChild(cmd.Process.Pid)
+	return err
 }
@@ -1,6 +1,13 @@
 package logcat
 
-import "testing"
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestParseLinePreservesLeadingIndentation(t *testing.T) {
 	line := "12-14 15:31:12.345  1234  5678 D MyTag:     Indented message"
@@ -16,6 +23,22 @@ func TestParseLinePreservesLeadingIndentation(t *testing.T) {
 	}
 }
 
+func TestEntrySizeCountsRawAndParsedText(t *testing.T) {
+	short, _ := ParseLine("12-14 15:31:12.345  1234  5678 D MyTag: hi")
+	long, _ := ParseLine("12-14 15:31:12.345  1234  5678 D MyTag: " + strings.Repeat("x", 1000))
+
+	// The message is counted once parsed and once in the raw line
+	if grown := long.Size() - short.Size(); grown < 2*998 {
+		t.Fatalf("expected a 998 character longer message to add at least %d bytes, got %d", 2*998, grown)
+	}
+
+	before := long.Size()
+	long.Fields = map[string]string{"userId": "42"}
+	if long.Size() <= before {
+		t.Fatal("expected fields to add to the size")
+	}
+}
+
 func TestParseLineTrimsLogcatPaddingOnly(t *testing.T) {
 	line := "12-14 15:31:12.345  1234  5678 D MyTag: Normal message"
 
@@ -29,3 +52,363 @@ func TestParseLineTrimsLogcatPaddingOnly(t *testing.T) {
 		t.Fatalf("expected message %q, got %q", want, entry.Message)
 	}
 }
+
+func TestExtractorStoresNamedGroups(t *testing.T) {
+	extractor, err := NewExtractor(`requestId=(?P<requestId>\w+).*took (?P<durationMs>\d+)ms`)
+	if err != nil {
+		t.Fatalf("NewExtractor returned error: %v", err)
+	}
+
+	entry := &Entry{Message: "done requestId=abc123 took 42ms"}
+	extractor.Apply(entry)
+
+	if got, _ := entry.Field("requestId"); got != "abc123" {
+		t.Fatalf("expected requestId %q, got %q", "abc123", got)
+	}
+	if got, _ := entry.Field("durationMs"); got != "42" {
+		t.Fatalf("expected durationMs %q, got %q", "42", got)
+	}
+}
+
+func TestReordererReleasesInTimestampOrder(t *testing.T) {
+	later, _ := ParseLine("12-14 15:31:12.500  1234  5678 D MyTag: later")
+	earlier, _ := ParseLine("12-14 15:31:12.345  4321  8765 D MyTag: earlier")
+
+	r := NewReorderer(200 * time.Millisecond)
+	start := time.Now()
+	r.Push(later, start)
+	r.Push(earlier, start.Add(50*time.Millisecond))
+
+	if released := r.Release(start.Add(100 * time.Millisecond)); len(released) != 0 {
+		t.Fatalf("expected no entries before window elapsed, got %d", len(released))
+	}
+
+	released := r.Release(start.Add(time.Second))
+	if len(released) != 2 || released[0] != earlier || released[1] != later {
+		t.Fatalf("expected entries in timestamp order, got %v", released)
+	}
+}
+
+func TestRedactorMasksPresetsAndPatterns(t *testing.T) {
+	redactor, err := NewRedactor([]string{"email", `secret=\w+`})
+	if err != nil {
+		t.Fatalf("NewRedactor returned error: %v", err)
+	}
+
+	got := redactor.Redact("user jane@example.com logged in with secret=hunter2")
+	want := "user [REDACTED] logged in with [REDACTED]"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestAttachToInheritsMetadata(t *testing.T) {
+	prev, _ := ParseLine("12-14 15:31:12.345  1234  5678 E MyTag: request failed")
+	line, _ := ParseLine("    with a wrapped detail")
+	if !line.Unparsed || line.Priority != Unknown {
+		t.Fatalf("expected unparsed entry with Unknown priority, got %+v", line)
+	}
+
+	line.AttachTo(prev)
+	if line.Priority != Error || line.Tag != "MyTag" || line.PID != "1234" || line.Timestamp != prev.Timestamp {
+		t.Fatalf("expected metadata from previous entry, got %+v", line)
+	}
+	if !line.Unparsed {
+		t.Fatal("expected attached entry to stay marked as unparsed")
+	}
+}
+
+func TestParseLineAssertPriority(t *testing.T) {
+	entry, err := ParseLine("12-14 15:31:12.345  1234  5678 A libc: abort message")
+	if err != nil {
+		t.Fatalf("ParseLine returned error: %v", err)
+	}
+	if entry.Priority != Assert || entry.Priority <= Fatal {
+		t.Fatalf("expected Assert priority above Fatal, got %v", entry.Priority)
+	}
+	if entry.Priority.String() != "A" || entry.Priority.Name() != "Assert" {
+		t.Fatalf("unexpected priority labels %q/%q", entry.Priority.String(), entry.Priority.Name())
+	}
+}
+
+func TestLinePID(t *testing.T) {
+	if got := linePID("12-14 15:31:12.345  1234  5678 D MyTag: message"); got != "1234" {
+		t.Fatalf("expected PID %q, got %q", "1234", got)
+	}
+	if got := linePID("--------- beginning of main"); got != "of" {
+		t.Fatalf("expected third field, got %q", got)
+	}
+	if got := linePID("short"); got != "" {
+		t.Fatalf("expected no PID, got %q", got)
+	}
+}
+
+func TestPublishDropsOldestWhenFull(t *testing.T) {
+	ch := make(chan StatusUpdate, 2)
+	for _, state := range []State{StateStopped, StateReconnecting, StateRunning} {
+		publish(ch, StatusUpdate{State: state})
+	}
+	if got := (<-ch).State; got != StateReconnecting {
+		t.Fatalf("expected oldest kept update %v, got %v", StateReconnecting, got)
+	}
+	if got := (<-ch).State; got != StateRunning {
+		t.Fatalf("expected latest update %v, got %v", StateRunning, got)
+	}
+}
+
+func TestFormatThreadtimeRoundTrips(t *testing.T) {
+	line := "12-14 15:31:12.345  1234  5678 W MyTag   :     Indented message"
+	entry, err := ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine returned error: %v", err)
+	}
+	if got := entry.FormatThreadtime(); got != line {
+		t.Fatalf("expected %q, got %q", line, got)
+	}
+
+	unparsed, _ := ParseLine("Fatal Exception: boom")
+	got, _ := ParseLine(unparsed.FormatThreadtime())
+	if got.Unparsed || got.Priority != Info || got.PID != "0" || got.Message != "Fatal Exception: boom" {
+		t.Fatalf("unexpected reparse of filled-in line: %+v", got)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	samples := map[string][]string{
+		"threadtime": {"12-14 15:31:12.345  1234  5678 D MyTag: message"},
+		"brief":      {"I/ActivityManager(  512): Start proc", "W/MyTag( 1234): careful"},
+		"long":       {"[ 12-14 15:31:12.345  1234: 5678 I/MyTag ]", "message", ""},
+		"studio":     {"2024-12-14 15:31:12.345  1234-5678  MyTag  com.example.app  E  failed"},
+		"dmesg":      {"<6>[  123.456789] usb 1-1: new device"},
+		"json":       {`{"level":"warn","tag":"Net","msg":"retrying","attempt":2}`},
+	}
+	for want, sample := range samples {
+		f, ok := DetectFormat(sample)
+		if !ok || f.Name != want {
+			t.Errorf("expected %s, got %s (detected %v)", want, f.Name, ok)
+		}
+	}
+}
+
+func TestFormatParsers(t *testing.T) {
+	parse := func(name string, lines ...string) []*Entry {
+		f, err := FormatNamed(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser := f.NewParser()
+		var entries []*Entry
+		for _, line := range lines {
+			if entry := parser.Parse(line); entry != nil {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	long := parse("long", "[ 12-14 15:31:12.345  1234: 5678 W/MyTag ]", "first", "second", "")
+	if len(long) != 2 || long[1].Tag != "MyTag" || long[1].Priority != Warn || long[1].Message != "second" {
+		t.Fatalf("unexpected long entries: %+v", long)
+	}
+
+	studio := parse("studio", "2024-12-14 15:31:12.345  1234-5678  MyTag  com.example.app  E  failed")
+	if e := studio[0]; e.Timestamp != "12-14 15:31:12.345" || e.TID != "5678" || e.Priority != Error || e.Message != "failed" || e.Fields["package"] != "com.example.app" {
+		t.Fatalf("unexpected studio entry: %+v", e)
+	}
+
+	js := parse("json", `{"level":"warn","tag":"Net","msg":"retrying","attempt":2}`)
+	if e := js[0]; e.Priority != Warn || e.Tag != "Net" || e.Message != "retrying" || e.Fields["attempt"] != "2" {
+		t.Fatalf("unexpected json entry: %+v", e)
+	}
+}
+
+func TestApplyJSONMessage(t *testing.T) {
+	entry, _ := ParseLine(`12-14 15:31:12.345  1234  5678 D Timber: {"level":"error","tag":"Checkout","message":"payment failed","user":{"id":42}}`)
+	if !ApplyJSONMessage(entry) {
+		t.Fatal("expected the message to be detected as JSON")
+	}
+	if entry.Priority != Error || entry.Tag != "Checkout" || entry.Message != "payment failed" {
+		t.Fatalf("unexpected columns: %s %s %q", entry.Priority, entry.Tag, entry.Message)
+	}
+	if got, _ := entry.Field("user.id"); got != "42" {
+		t.Fatalf("expected user.id field 42, got %q", got)
+	}
+	if got, _ := entry.Field("logcatTag"); got != "Timber" {
+		t.Fatalf("expected logcatTag field Timber, got %q", got)
+	}
+	if entry.PID != "1234" || entry.Timestamp != "12-14 15:31:12.345" {
+		t.Fatalf("device columns changed: %s %s", entry.PID, entry.Timestamp)
+	}
+}
+
+func TestUnparsedReason(t *testing.T) {
+	cases := map[string]string{
+		"12-14 15:31:12.345  1234  5678 D MyTag: message": "",
+		"--------- beginning of main":                     "",
+		"12-14 15:31:12.345 D MyTag":                      "4 fields, threadtime has at least 6",
+		"12-14 15:31:12.345  main  5678 D MyTag: message": "PID or TID is not a number",
+		"12-14 15:31:12.345  1234  5678 DEBUG MyTag: msg": `priority "DEBUG" is not a single letter`,
+	}
+	for line, want := range cases {
+		if got := UnparsedReason(line); got != want {
+			t.Errorf("UnparsedReason(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestParsePowerEvent(t *testing.T) {
+	cases := []struct {
+		message string
+		want    PowerEvent
+	}{
+		{`acquireWakeLockInternal: lock=189111009, flags=0x1, tag="MyApp:sync", ws=null, uid=10123, pid=4321`,
+			PowerEvent{Kind: WakeLockAcquired, Lock: "189111009", Name: "MyApp:sync", PID: "4321"}},
+		{`releaseWakeLockInternal: lock=189111009 [MyApp:sync], flags=0x0`,
+			PowerEvent{Kind: WakeLockReleased, Lock: "189111009", Name: "MyApp:sync"}},
+		{`WakeLock finalized while still held: MyApp:sync`,
+			PowerEvent{Kind: WakeLockLeaked, Name: "MyApp:sync", PID: "4321"}},
+		{`battery l=57 v=3862 t=28.0 h=2 st=3 chg=u`, PowerEvent{Kind: BatteryLevel, Level: 57}},
+	}
+	for _, c := range cases {
+		got, ok := ParsePowerEvent(&Entry{Message: c.message, PID: "4321"})
+		if !ok || got != c.want {
+			t.Errorf("ParsePowerEvent(%q) = %+v, %v, want %+v", c.message, got, ok, c.want)
+		}
+	}
+	if _, ok := ParsePowerEvent(&Entry{Message: "Skipped 30 frames!"}); ok {
+		t.Error("expected no power event")
+	}
+}
+
+func TestParseIntent(t *testing.T) {
+	cases := []struct {
+		message string
+		want    Intent
+	}{
+		{"START u0 {act=android.intent.action.VIEW dat=https://example.com/item/42 flg=0x10000000 cmp=com.example/.MainActivity (has extras)} from uid 10123",
+			Intent{Kind: "start", Action: "android.intent.action.VIEW", Data: "https://example.com/item/42", Flags: "0x10000000", Component: "com.example/.MainActivity", Extras: "yes"}},
+		{"Received intent: Intent { act=android.intent.action.BATTERY_CHANGED flg=0x60000010 }",
+			Intent{Kind: "intent", Action: "android.intent.action.BATTERY_CHANGED", Flags: "0x60000010"}},
+		{"Sending broadcast {act=com.example.SYNC_DONE cat=[android.intent.category.DEFAULT] pkg=com.example}",
+			Intent{Kind: "broadcast", Action: "com.example.SYNC_DONE", Categories: "android.intent.category.DEFAULT", Package: "com.example"}},
+	}
+	for _, c := range cases {
+		got, ok := ParseIntent(&Entry{Message: c.message})
+		if !ok || got != c.want {
+			t.Errorf("ParseIntent(%q) = %+v, %v, want %+v", c.message, got, ok, c.want)
+		}
+	}
+	if !cases[0].want.Involves("com.example") || cases[1].want.Involves("com.example") {
+		t.Error("Involves matched the wrong intents")
+	}
+}
+
+func TestParseJobEvent(t *testing.T) {
+	const id = "5f1c2b7e-8d3a-4c6f-9b1e-2a7d4e8f0c13"
+	cases := []struct {
+		tag     string
+		message string
+		want    JobEvent
+	}{
+		{"WM-SystemJobScheduler", "Scheduling work ID " + id + "Job ID 12",
+			JobEvent{State: JobScheduled, ID: id}},
+		{"WM-SystemJobService", "onStartJob for WorkGenerationalId(workSpecId=" + id + ", generation=0)",
+			JobEvent{State: JobStarted, ID: id}},
+		{"WM-WorkerWrapper", "Worker result FAILURE for Work [ id=" + id + ", tags={ com.example.SyncWorker } ]",
+			JobEvent{State: JobFailed, ID: id, Name: "com.example.SyncWorker"}},
+		{"JobScheduler", "Timed out while job was executing: JobStatus{3f2a1b #u0a123/42 com.example/.SyncJobService u=0 s=10123}",
+			JobEvent{State: JobFailed, ID: "#u0a123/42", Name: "com.example/.SyncJobService"}},
+	}
+	for _, c := range cases {
+		got, ok := ParseJobEvent(&Entry{Tag: c.tag, Message: c.message})
+		if !ok || got != c.want {
+			t.Errorf("ParseJobEvent(%q) = %+v, %v, want %+v", c.message, got, ok, c.want)
+		}
+	}
+	if _, ok := ParseJobEvent(&Entry{Tag: "MyApp", Message: "Scheduling work ID " + id}); ok {
+		t.Error("expected no job event outside WorkManager and JobScheduler tags")
+	}
+}
+
+func TestScanInstrumentation(t *testing.T) {
+	output := `INSTRUMENTATION_STATUS: class=com.example.LoginTest
+INSTRUMENTATION_STATUS: current=1
+INSTRUMENTATION_STATUS: numtests=2
+INSTRUMENTATION_STATUS: test=validPassword
+INSTRUMENTATION_STATUS_CODE: 1
+INSTRUMENTATION_STATUS: class=com.example.LoginTest
+INSTRUMENTATION_STATUS: current=1
+INSTRUMENTATION_STATUS: numtests=2
+INSTRUMENTATION_STATUS: stack=java.lang.AssertionError: expected:<true> but was:<false>
+	at org.junit.Assert.fail(Assert.java:89)
+
+INSTRUMENTATION_STATUS: test=validPassword
+INSTRUMENTATION_STATUS_CODE: -2
+INSTRUMENTATION_RESULT: shortMsg=Process crashed.
+INSTRUMENTATION_CODE: 0
+`
+	var got []InstrumentStatus
+	err := ScanInstrumentation(strings.NewReader(output), func(s InstrumentStatus) { got = append(got, s) })
+	if err == nil || !strings.Contains(err.Error(), "Process crashed.") {
+		t.Errorf("expected the crash as error, got %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 statuses, got %+v", got)
+	}
+	want := InstrumentStatus{
+		Code:    TestFailed,
+		Class:   "com.example.LoginTest",
+		Test:    "validPassword",
+		Current: 1,
+		Total:   2,
+		Stack:   "java.lang.AssertionError: expected:<true> but was:<false>\n\tat org.junit.Assert.fail(Assert.java:89)",
+	}
+	if got[0].Code != TestStarted || got[1] != want {
+		t.Errorf("got %+v, want start then %+v", got, want)
+	}
+}
+
+func TestRedactorSkipsInvalidRules(t *testing.T) {
+	redactor, err := NewRedactor([]string{`secret=(\w+`, `token=\w+`})
+	if err == nil {
+		t.Fatal("NewRedactor returned no error for an invalid rule")
+	}
+
+	got := redactor.Redact("secret=abc token=def")
+	if want := "secret=abc " + RedactedText; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestStopDeliversPendingLines(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	m := NewManager("", TailAll)
+	m.setScanner(newScanner(reader))
+	lineChan := make(chan string)
+	go m.ReadLines(lineChan)
+
+	want := []string{"first", "second", "third"}
+	for _, line := range want {
+		fmt.Fprintln(writer, line)
+	}
+	// Let the reader batch the lines and block handing them on
+	time.Sleep(3 * currentReadInterval())
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- m.Stop() }()
+	time.Sleep(50 * time.Millisecond)
+	var got []string
+	for range want {
+		select {
+		case line := <-lineChan:
+			got = append(got, line)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %v after Stop, got %v", want, got)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	<-stopped
+}
@@ -0,0 +1,87 @@
+package logcat
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/adb"
+)
+
+// Orphan is an adb logcat process left running by a logdog that died without
+// stopping it, e.g. after a crash or kill -9.
+type Orphan struct {
+	PID int
+	// Owner is the PID of the logdog that started it
+	Owner int
+}
+
+// childrenDir holds a file per adb logcat process started by a logdog, named
+// after the process's PID and containing the PID of the logdog.
+func childrenDir() string {
+	return filepath.Join(os.TempDir(), "logdog", "children")
+}
+
+// trackChild records a logcat process started by this logdog.
+func trackChild(pid int) {
+	dir := childrenDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(dir, strconv.Itoa(pid)+".pid"), []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644)
+}
+
+// untrackChild removes the record of a logcat process once it has exited.
+func untrackChild(pid int) {
+	_ = os.Remove(filepath.Join(childrenDir(), strconv.Itoa(pid)+".pid"))
+}
+
+// FindOrphans returns the adb logcat processes whose logdog has exited.
+// Records of processes that are gone are removed.
+func FindOrphans() []Orphan {
+	entries, err := os.ReadDir(childrenDir())
+	if err != nil {
+		return nil
+	}
+	var orphans []Orphan
+	for _, entry := range entries {
+		path := filepath.Join(childrenDir(), entry.Name())
+		pid, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".pid"))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		owner, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			_ = os.Remove(path)
+			continue
+		}
+		if owner != os.Getpid() && processAlive(owner) {
+			continue
+		}
+		// The PID may have been reused since, so only a process that is still adb logcat counts
+		if !adb.IsLogcatProcess(pid) {
+			_ = os.Remove(path)
+			continue
+		}
+		orphans = append(orphans, Orphan{PID: pid, Owner: owner})
+	}
+	return orphans
+}
+
+// KillOrphans kills the orphaned processes and removes their records.
+func KillOrphans(orphans []Orphan) error {
+	var firstErr error
+	for _, orphan := range orphans {
+		if err := killProcessGroup(orphan.PID); err != nil && firstErr == nil {
+			firstErr = err
+			continue
+		}
+		untrackChild(orphan.PID)
+	}
+	return firstErr
+}
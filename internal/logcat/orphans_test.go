@@ -0,0 +1,43 @@
+package logcat
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestFindOrphansDropsRecordsOfOtherProcesses(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	if err := os.MkdirAll(childrenDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The test process is alive but not adb logcat, as if its PID was reused
+	path := filepath.Join(childrenDir(), strconv.Itoa(os.Getpid())+".pid")
+	if err := os.WriteFile(path, []byte("999999999"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if orphans := FindOrphans(); len(orphans) != 0 {
+		t.Fatalf("expected no orphans, got %v", orphans)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the record to be removed, got %v", err)
+	}
+}
+
+func TestTrackChildRecordsOwner(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	trackChild(42)
+	data, err := os.ReadFile(filepath.Join(childrenDir(), "42.pid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != strconv.Itoa(os.Getpid())+"\n" {
+		t.Fatalf("expected owner %d, got %q", os.Getpid(), got)
+	}
+	untrackChild(42)
+	if _, err := os.Stat(filepath.Join(childrenDir(), "42.pid")); !os.IsNotExist(err) {
+		t.Fatalf("expected the record to be removed, got %v", err)
+	}
+}
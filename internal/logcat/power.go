@@ -0,0 +1,72 @@
+package logcat
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PowerEventKind says what a power event reports.
+type PowerEventKind int
+
+const (
+	WakeLockAcquired PowerEventKind = iota + 1
+	WakeLockReleased
+	// WakeLockLeaked is a wakelock garbage collected while still held
+	WakeLockLeaked
+	BatteryLevel
+)
+
+// PowerEvent is a wakelock or battery event parsed from a log line.
+type PowerEvent struct {
+	Kind PowerEventKind
+	// Lock identifies the wakelock across acquire and release lines
+	Lock string
+	// Name is the wakelock's tag, e.g. "*job*/com.example/.SyncJob"
+	Name string
+	// PID is the process holding the wakelock, when logged
+	PID string
+	// Level is the battery level in percent
+	Level int
+}
+
+var (
+	// PowerManagerService: acquireWakeLockInternal: lock=189111009, flags=0x1, tag="*alarm*", ws=null, uid=1000, pid=1234
+	wakeLockAcquireRegex = regexp.MustCompile(`acquireWakeLockInternal: lock=(\w+), flags=\w+, tag="([^"]*)".*?\bpid=(\d+)`)
+	// PowerManagerService: releaseWakeLockInternal: lock=189111009 [*alarm*], flags=0x0
+	wakeLockReleaseRegex = regexp.MustCompile(`releaseWakeLockInternal: lock=(\w+) \[([^\]]*)\]`)
+	// PowerManager: WakeLock finalized while still held: MyApp:sync
+	wakeLockLeakRegex = regexp.MustCompile(`WakeLock finalized while still held: (.+)`)
+	// healthd: battery l=57 v=3862 t=28.0 h=2 st=3 chg=u
+	healthdBatteryRegex = regexp.MustCompile(`\bbattery l=(\d+)\b`)
+	// BatteryService: Processing new values: ... batteryLevel=57, ...
+	batteryLevelRegex = regexp.MustCompile(`\bbatteryLevel[=:] ?(\d+)\b`)
+)
+
+// ParsePowerEvent reads a wakelock acquire, release or leak, or a battery
+// level, from PowerManager, healthd and BatteryService lines.
+func ParsePowerEvent(e *Entry) (PowerEvent, bool) {
+	message := e.Message
+	// Skip the regexes for the vast majority of lines
+	if !strings.Contains(message, "WakeLock") && !strings.Contains(message, "attery") {
+		return PowerEvent{}, false
+	}
+	if match := wakeLockAcquireRegex.FindStringSubmatch(message); match != nil {
+		return PowerEvent{Kind: WakeLockAcquired, Lock: match[1], Name: match[2], PID: match[3]}, true
+	}
+	if match := wakeLockReleaseRegex.FindStringSubmatch(message); match != nil {
+		return PowerEvent{Kind: WakeLockReleased, Lock: match[1], Name: match[2]}, true
+	}
+	if match := wakeLockLeakRegex.FindStringSubmatch(message); match != nil {
+		return PowerEvent{Kind: WakeLockLeaked, Name: match[1], PID: e.PID}, true
+	}
+	for _, regex := range []*regexp.Regexp{healthdBatteryRegex, batteryLevelRegex} {
+		if match := regex.FindStringSubmatch(message); match != nil {
+			level, err := strconv.Atoi(match[1])
+			if err == nil && level <= 100 {
+				return PowerEvent{Kind: BatteryLevel, Level: level}, true
+			}
+		}
+	}
+	return PowerEvent{}, false
+}
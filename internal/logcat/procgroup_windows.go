@@ -0,0 +1,20 @@
+//go:build windows
+
+package logcat
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, where adb runs in the console's group.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the process with pid.
+func killProcessGroup(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
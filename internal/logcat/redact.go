@@ -0,0 +1,56 @@
+package logcat
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// RedactedText replaces matched sensitive values.
+const RedactedText = "[REDACTED]"
+
+// redactionPresets are built-in patterns that can be referenced by name in the config.
+var redactionPresets = map[string]string{
+	"email": `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	"uuid":  `(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`,
+	"token": `(?i)\b(bearer\s+[A-Za-z0-9._~+/-]+=*|eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+)`,
+	"ipv4":  `\b(?:\d{1,3}\.){3}\d{1,3}\b`,
+}
+
+// Redactor masks sensitive values in text that leaves logdog.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor compiles the rules; each rule is either a preset name
+// (email, uuid, token, ipv4) or a regular expression. Rules that don't
+// compile are left out and reported in the error; the redactor still applies
+// the rest.
+func NewRedactor(rules []string) (*Redactor, error) {
+	r := &Redactor{}
+	var errs []error
+	for _, rule := range rules {
+		pattern := rule
+		if preset, ok := redactionPresets[rule]; ok {
+			pattern = preset
+		}
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid redaction rule %q: %w", rule, err))
+			continue
+		}
+		r.patterns = append(r.patterns, regex)
+	}
+	return r, errors.Join(errs...)
+}
+
+// Redact returns text with every match replaced by RedactedText.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, pattern := range r.patterns {
+		text = pattern.ReplaceAllString(text, RedactedText)
+	}
+	return text
+}
@@ -0,0 +1,64 @@
+package logcat
+
+import (
+	"sort"
+	"time"
+)
+
+// Reorderer holds entries for a short window so that entries arriving out of
+// order (e.g. from merged streams) are released in timestamp order.
+type Reorderer struct {
+	window   time.Duration
+	pending  []pendingEntry
+	lastTime time.Time
+}
+
+type pendingEntry struct {
+	entry   *Entry
+	at      time.Time
+	arrived time.Time
+}
+
+// NewReorderer creates a reorderer that delays entries by up to window.
+func NewReorderer(window time.Duration) *Reorderer {
+	return &Reorderer{window: window}
+}
+
+// Push adds an entry that arrived at now. Entries without a parsed timestamp
+// inherit the timestamp of the previous entry so they stay attached to it.
+func (r *Reorderer) Push(e *Entry, now time.Time) {
+	at := e.Time
+	if at.IsZero() {
+		at = r.lastTime
+	} else {
+		r.lastTime = at
+	}
+
+	// Insert after all pending entries with the same or earlier timestamp to keep ties stable
+	idx := sort.Search(len(r.pending), func(i int) bool {
+		return r.pending[i].at.After(at)
+	})
+	r.pending = append(r.pending, pendingEntry{})
+	copy(r.pending[idx+1:], r.pending[idx:])
+	r.pending[idx] = pendingEntry{entry: e, at: at, arrived: now}
+}
+
+// Release returns, in timestamp order, the entries whose window has elapsed.
+func (r *Reorderer) Release(now time.Time) []*Entry {
+	var released []*Entry
+	for len(r.pending) > 0 && now.Sub(r.pending[0].arrived) >= r.window {
+		released = append(released, r.pending[0].entry)
+		r.pending = r.pending[1:]
+	}
+	return released
+}
+
+// Pending returns the number of entries still held back.
+func (r *Reorderer) Pending() int {
+	return len(r.pending)
+}
+
+// Window returns the reordering window.
+func (r *Reorderer) Window() time.Duration {
+	return r.window
+}
@@ -0,0 +1,81 @@
+package logcat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// SessionLock marks a device and app stream as taken by this process, so a
+// second logdog on the same stream can warn instead of silently doubling up.
+type SessionLock struct {
+	path string
+}
+
+var unsafeLockChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sessionLockPath returns the lock file for a device and app, in the temp dir
+// so it is cleaned up with it.
+func sessionLockPath(serial, appID string) string {
+	if serial == "" {
+		serial = "default"
+	}
+	if appID == "" {
+		appID = "all"
+	}
+	name := unsafeLockChars.ReplaceAllString(serial+"_"+appID, "-") + ".pid"
+	return filepath.Join(os.TempDir(), "logdog", name)
+}
+
+// AcquireSession takes the lock for a device and app. If another live logdog
+// holds it, no lock is taken and that process's PID is returned instead.
+// Locks left behind by processes that died are taken over.
+func AcquireSession(serial, appID string) (*SessionLock, int, error) {
+	path := sessionLockPath(serial, appID)
+	if data, err := os.ReadFile(path); err == nil {
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err == nil && pid != os.Getpid() && processAlive(pid) {
+			return nil, pid, nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, 0, fmt.Errorf("create session lock dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return nil, 0, fmt.Errorf("write session lock: %w", err)
+	}
+	return &SessionLock{path: path}, 0, nil
+}
+
+// Release removes the lock if this process still holds it.
+func (l *SessionLock) Release() {
+	if l == nil {
+		return
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil || strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		return
+	}
+	_ = os.Remove(l.path)
+}
+
+// processAlive reports whether a process with the PID exists. Windows has no
+// signal 0, but finding the process already fails there once it has exited.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
@@ -0,0 +1,51 @@
+package logcat
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestAcquireSessionReportsLiveHolder(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := sessionLockPath("emulator-5554", "com.example")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The parent process stands in for another logdog that is still running
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getppid())), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, pid, err := AcquireSession("emulator-5554", "com.example")
+	if err != nil {
+		t.Fatalf("AcquireSession returned error: %v", err)
+	}
+	if lock != nil || pid != os.Getppid() {
+		t.Fatalf("got lock %v, pid %d; want no lock and pid %d", lock, pid, os.Getppid())
+	}
+}
+
+func TestAcquireSessionTakesOverStaleLock(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := sessionLockPath("", "")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("999999999"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, pid, err := AcquireSession("", "")
+	if err != nil {
+		t.Fatalf("AcquireSession returned error: %v", err)
+	}
+	if lock == nil || pid != 0 {
+		t.Fatalf("got lock %v, pid %d; want the lock", lock, pid)
+	}
+	lock.Release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("lock file still exists after Release: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package logcat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sinkQueueSize   = 1024
+	sinkHTTPTimeout = 5 * time.Second
+)
+
+// SinkRule describes which entries are duplicated to a sink target.
+// Target is one of "file:<path>", "unix:<socket path>" or an http(s) URL.
+type SinkRule struct {
+	Pattern     *regexp.Regexp
+	MinPriority Priority
+	Target      string
+}
+
+// Matches reports whether the entry should be forwarded by this rule.
+// A nil pattern matches every entry at or above the minimum priority.
+// Unparsed lines have no level, so they only pass a rule without a minimum.
+func (r SinkRule) Matches(e *Entry) bool {
+	if e == nil || e.Priority < r.MinPriority {
+		return false
+	}
+	if e.Priority == Unknown && r.MinPriority > Verbose {
+		return false
+	}
+	if r.Pattern == nil {
+		return true
+	}
+	return r.Pattern.MatchString(e.Tag) || r.Pattern.MatchString(e.Message)
+}
+
+type sinkWriter interface {
+	write(line string) error
+	close() error
+}
+
+type forwardRule struct {
+	rule  SinkRule
+	sink  sinkWriter
+	lines chan string
+	done  chan struct{}
+}
+
+// Forwarder duplicates matching entries to configured sinks without blocking the caller.
+// Lines are dropped when a sink cannot keep up.
+type Forwarder struct {
+	rules     []*forwardRule
+	closeOnce sync.Once
+}
+
+// NewForwarder opens all sink targets and starts their writers. Targets that
+// fail to open are left out and reported in the error; the forwarder still
+// writes to the rest.
+func NewForwarder(rules []SinkRule) (*Forwarder, error) {
+	f := &Forwarder{}
+	var errs []error
+	for _, rule := range rules {
+		sink, err := openSink(rule.Target)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", rule.Target, err))
+			continue
+		}
+		fr := &forwardRule{
+			rule:  rule,
+			sink:  sink,
+			lines: make(chan string, sinkQueueSize),
+			done:  make(chan struct{}),
+		}
+		go fr.run()
+		f.rules = append(f.rules, fr)
+	}
+	return f, errors.Join(errs...)
+}
+
+// Forward queues the entry on every sink whose rule matches.
+func (f *Forwarder) Forward(e *Entry) {
+	if f == nil || e == nil {
+		return
+	}
+	for _, fr := range f.rules {
+		if !fr.rule.Matches(e) {
+			continue
+		}
+		select {
+		case fr.lines <- e.Raw:
+		default:
+		}
+	}
+}
+
+// Close flushes queued lines and closes all sinks.
+func (f *Forwarder) Close() {
+	if f == nil {
+		return
+	}
+	f.closeOnce.Do(func() {
+		for _, fr := range f.rules {
+			close(fr.lines)
+			<-fr.done
+			_ = fr.sink.close()
+		}
+	})
+}
+
+func (fr *forwardRule) run() {
+	defer close(fr.done)
+	for line := range fr.lines {
+		_ = fr.sink.write(line)
+	}
+}
+
+func openSink(target string) (sinkWriter, error) {
+	switch {
+	case strings.HasPrefix(target, "file:"):
+		path := strings.TrimPrefix(target, "file:")
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open sink file: %w", err)
+		}
+		return &streamSink{conn: file}, nil
+	case strings.HasPrefix(target, "unix:"):
+		conn, err := net.Dial("unix", strings.TrimPrefix(target, "unix:"))
+		if err != nil {
+			return nil, fmt.Errorf("connect sink socket: %w", err)
+		}
+		return &streamSink{conn: conn}, nil
+	case strings.HasPrefix(target, "http://"), strings.HasPrefix(target, "https://"):
+		return &httpSink{url: target, client: &http.Client{Timeout: sinkHTTPTimeout}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sink target %q", target)
+	}
+}
+
+type streamSink struct {
+	conn io.WriteCloser
+}
+
+func (s *streamSink) write(line string) error {
+	_, err := s.conn.Write([]byte(line + "\n"))
+	return err
+}
+
+func (s *streamSink) close() error {
+	return s.conn.Close()
+}
+
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSink) write(line string) error {
+	resp, err := s.client.Post(s.url, "text/plain; charset=utf-8", bytes.NewBufferString(line+"\n"))
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *httpSink) close() error {
+	return nil
+}
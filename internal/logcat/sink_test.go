@@ -0,0 +1,65 @@
+package logcat
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestForwarderWritesMatchingEntriesToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sink.log")
+	forwarder, err := NewForwarder([]SinkRule{{
+		Pattern:     regexp.MustCompile("crash"),
+		MinPriority: Error,
+		Target:      "file:" + path,
+	}})
+	if err != nil {
+		t.Fatalf("NewForwarder returned error: %v", err)
+	}
+
+	forwarder.Forward(&Entry{Priority: Error, Message: "crash here", Raw: "line 1"})
+	forwarder.Forward(&Entry{Priority: Info, Message: "crash ignored", Raw: "line 2"})
+	forwarder.Forward(&Entry{Priority: Fatal, Message: "unrelated", Raw: "line 3"})
+	forwarder.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read sink file: %v", err)
+	}
+	if got, want := string(data), "line 1\n"; got != want {
+		t.Fatalf("expected sink contents %q, got %q", want, got)
+	}
+}
+
+func TestSinkRuleLevelExcludesUnparsedLines(t *testing.T) {
+	unparsed := &Entry{Priority: Unknown, Message: "crash"}
+	if (SinkRule{MinPriority: Error}).Matches(unparsed) {
+		t.Fatal("unparsed line passed an Error minimum")
+	}
+	if !(SinkRule{MinPriority: Verbose}).Matches(unparsed) {
+		t.Fatal("unparsed line dropped by a rule without a minimum")
+	}
+}
+
+func TestForwarderKeepsSinksThatOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sink.log")
+	forwarder, err := NewForwarder([]SinkRule{
+		{Target: "file:" + filepath.Join(t.TempDir(), "missing", "sink.log")},
+		{Target: "file:" + path},
+	})
+	if err == nil {
+		t.Fatal("NewForwarder returned no error for a target that can't be opened")
+	}
+
+	forwarder.Forward(&Entry{Priority: Info, Raw: "line 1"})
+	forwarder.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read sink file: %v", err)
+	}
+	if got, want := string(data), "line 1\n"; got != want {
+		t.Fatalf("expected sink contents %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// activityPollInterval is how often the foreground activity is queried.
+// dumpsys is slow enough that polling much faster would load the device.
+const activityPollInterval = 2 * time.Second
+
+// activityMsg carries the foreground activity, "" when none is resumed.
+type activityMsg struct {
+	activity string
+	err      error
+}
+
+// pollActivity queries the foreground activity after interval, or right away
+// when it is 0.
+func pollActivity(manager *logcat.Manager, interval time.Duration) tea.Cmd {
+	query := func(time.Time) tea.Msg {
+		activity, err := manager.ResumedActivity()
+		return activityMsg{activity: activity, err: err}
+	}
+	if interval == 0 {
+		return func() tea.Msg { return query(time.Now()) }
+	}
+	return tea.Tick(interval, query)
+}
+
+// activityInterval returns how often the foreground activity is polled.
+func (m *Model) activityInterval() time.Duration {
+	if m.lowPower {
+		return lowPowerActivityInterval
+	}
+	return activityPollInterval
+}
+
+// activityText returns the foreground activity for the header, with the
+// package left out for the app's own activities.
+func (m *Model) activityText() string {
+	if m.appID != "" {
+		if class, ok := strings.CutPrefix(m.activity, m.appID+"/"); ok {
+			return class
+		}
+	}
+	return m.activity
+}
@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+const gutterWidth = 3
+
+// entryFlags holds the triage state of an entry.
+type entryFlags uint8
+
+const (
+	flagImportant entryFlags = 1 << iota
+	flagReviewed
+)
+
+// flagNames maps the names accepted by flag: filters to flags.
+var flagNames = map[string]entryFlags{
+	"important": flagImportant,
+	"reviewed":  flagReviewed,
+}
+
+func (f entryFlags) names() []string {
+	var names []string
+	if f&flagImportant != 0 {
+		names = append(names, "important")
+	}
+	if f&flagReviewed != 0 {
+		names = append(names, "reviewed")
+	}
+	return names
+}
+
+// hasGutter reports whether a marker column is rendered in front of every line.
+func (m *Model) hasGutter() bool {
+	return len(m.annotations) > 0 || len(m.flags) > 0
+}
+
+// gutterMarker returns the markers shown in the gutter for an entry, at most two.
+func (m *Model) gutterMarker(entry *logcat.Entry) string {
+	var markers []string
+	flags := m.flags[entry.ID]
+	if flags&flagImportant != 0 {
+		markers = append(markers, lipgloss.NewStyle().Foreground(GetWarnColor()).Render("★"))
+	}
+	if flags&flagReviewed != 0 {
+		markers = append(markers, lipgloss.NewStyle().Foreground(GetInfoColor()).Render("✓"))
+	}
+	if _, ok := m.annotations[entry.ID]; ok {
+		markers = append(markers, lipgloss.NewStyle().Foreground(GetAccentColor()).Render("✎"))
+	}
+	if len(markers) > 2 {
+		markers = markers[:2]
+	}
+	return strings.Join(markers, "")
+}
+
+// toggleFlag toggles a flag on the selection, or on the highlighted entry outside selection mode.
+// When entries disagree, the flag is set on all of them.
+func (m *Model) toggleFlag(flag entryFlags) {
+	var targets []uint64
+	if m.selectionMode && len(m.selectedEntries) > 0 {
+		for id := range m.selectedEntries {
+			targets = append(targets, id)
+		}
+	} else if m.highlightedEntry != nil {
+		targets = []uint64{m.highlightedEntry.ID}
+	} else {
+		m.statusMessage = "highlight or select entries to flag them"
+		return
+	}
+
+	allSet := true
+	for _, id := range targets {
+		if m.flags[id]&flag == 0 {
+			allSet = false
+			break
+		}
+	}
+	for _, id := range targets {
+		flags := m.flags[id]
+		if allSet {
+			flags &^= flag
+		} else {
+			flags |= flag
+		}
+		if flags == 0 {
+			delete(m.flags, id)
+		} else {
+			m.flags[id] = flags
+		}
+	}
+}
+
+// autoBookmark marks a new Fatal entry, or Error entry with bookmarkErrors on,
+// as important, so failures from a long unattended run can be stepped through
+// with J/K.
+func (m *Model) autoBookmark(entry *logcat.Entry) {
+	if entry.Priority < logcat.Error || entry.Priority > logcat.Assert {
+		return
+	}
+	if !m.bookmarkErrors && !(m.bookmarkFatal && entry.Priority >= logcat.Fatal) {
+		return
+	}
+	m.markImportant(entry)
+}
+
+// markImportant bookmarks an entry as it arrives.
+func (m *Model) markImportant(entry *logcat.Entry) {
+	if !m.hasGutter() {
+		// Lines rendered so far have no gutter column yet
+		m.resetRenderCache()
+	}
+	m.flags[entry.ID] |= flagImportant
+}
+
+// jumpToBookmark highlights the next visible important entry after the
+// highlight, or the previous one when step is negative, wrapping around.
+func (m *Model) jumpToBookmark(step int) {
+	visible := m.getVisibleEntries()
+	start := -1
+	if step < 0 {
+		start = len(visible)
+	}
+	for i, entry := range visible {
+		if m.isHighlighted(entry) {
+			start = i
+			break
+		}
+	}
+	for n := 1; n <= len(visible); n++ {
+		i := ((start+n*step)%len(visible) + len(visible)) % len(visible)
+		entry := visible[i]
+		if m.flags[entry.ID]&flagImportant != 0 {
+			m.autoScroll = false
+			m.highlightedEntry = entry
+			m.ensureEntryVisible(entry)
+			return
+		}
+	}
+	m.statusMessage = "no bookmarks (*: bookmark, or enable auto-bookmarks in settings)"
+}
+
+// withGutter prefixes rendered entry lines with the gutter column.
+func (m *Model) withGutter(entry *logcat.Entry, lines []string) []string {
+	if !m.hasGutter() {
+		return lines
+	}
+	blank := strings.Repeat(" ", gutterWidth)
+	marker := m.gutterMarker(entry)
+	for i := range lines {
+		if i == 0 && marker != "" {
+			lines[i] = marker + strings.Repeat(" ", gutterWidth-lipgloss.Width(marker)) + lines[i]
+			continue
+		}
+		lines[i] = blank + lines[i]
+	}
+	return lines
+}
+
+// contentWidth returns the width available to formatted entries when wrapping.
+func (m *Model) contentWidth() int {
+	if !m.wrapLines {
+		return 0
+	}
+	width := m.viewport.Width
+	if m.rawMode {
+		return width
+	}
+	if m.hasGutter() {
+		width -= gutterWidth
+	}
+	if m.multiSource() {
+		width -= sourceLabelWidth + 1
+	}
+	return width
+}
+
+// startAnnotation opens the note prompt for the highlighted entry.
+func (m *Model) startAnnotation() bool {
+	if m.highlightedEntry == nil {
+		m.statusMessage = "highlight an entry to annotate it"
+		return false
+	}
+	m.showAnnotate = true
+	m.annotateInput.SetValue(m.annotations[m.highlightedEntry.ID])
+	m.annotateInput.CursorEnd()
+	m.annotateInput.Focus()
+	return true
+}
+
+// saveAnnotation stores the prompt text on the highlighted entry; empty text removes the note.
+func (m *Model) saveAnnotation() {
+	if m.highlightedEntry == nil {
+		return
+	}
+	note := strings.TrimSpace(m.annotateInput.Value())
+	if note == "" {
+		delete(m.annotations, m.highlightedEntry.ID)
+	} else {
+		m.annotations[m.highlightedEntry.ID] = note
+	}
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// tailSizes are the tail sizes the settings overlay steps through.
+var tailSizes = []int{100, 1000, 5000, 20000, 100000, logcat.TailAll}
+
+// backfillMsg carries backlog lines read by reloadHistory.
+type backfillMsg struct {
+	lines []string
+	err   error
+}
+
+// tailSizeLabel describes a tail size the way --tail accepts it.
+func tailSizeLabel(size int) string {
+	if size == logcat.TailAll {
+		return "all"
+	}
+	return fmt.Sprintf("%d", size)
+}
+
+// stepTailSize moves the tail size to the next or previous preset.
+func (m *Model) stepTailSize(delta int) {
+	index := 0
+	for i, size := range tailSizes {
+		if size == m.tailSize {
+			index = i
+			break
+		}
+		// Sizes between presets step from the nearest one below
+		if size != logcat.TailAll && size < m.tailSize {
+			index = i
+		}
+	}
+	index = min(max(0, index+delta), len(tailSizes)-1)
+	m.tailSize = tailSizes[index]
+	m.tailSizeChanged = true
+}
+
+// reloadHistory reads the last tailSize lines of the device log, so entries
+// from before the session or an earlier smaller tail can be pulled in while
+// logcat keeps streaming.
+func (m *Model) reloadHistory() tea.Cmd {
+	switch {
+	case m.importName != "":
+		m.statusMessage = "nothing to reload for an import"
+		return nil
+	case m.multiSource():
+		m.statusMessage = "reloading history needs a single device"
+		return nil
+	case m.tailSize == 0:
+		m.statusMessage = "pick a tail size to reload (h/l in settings)"
+		return nil
+	case m.logManager == nil:
+		return nil
+	}
+	m.statusMessage = "reloading last " + tailSizeLabel(m.tailSize) + " lines..."
+	manager, size := m.logManager, m.tailSize
+	return func() tea.Msg {
+		lines, err := manager.Backlog(size)
+		return backfillMsg{lines: lines, err: err}
+	}
+}
+
+// applyBackfill prepends the backlog entries older than the oldest entry in
+// the log. Entries already shown are recognized by their raw line; when the
+// oldest one isn't in the backlog, entries are compared by time instead.
+func (m *Model) applyBackfill(lines []string) {
+	var backlog []*logcat.Entry
+	for _, line := range lines {
+		if entry, _ := logcat.ParseLine(line); entry != nil {
+			backlog = append(backlog, entry)
+		}
+	}
+
+	cut := len(backlog)
+	if len(m.parsedEntries) > 0 {
+		oldest := m.parsedEntries[0]
+		cut = 0
+		for i, entry := range backlog {
+			if entry.Raw == oldest.Raw {
+				cut = i
+				break
+			}
+			if !entry.Time.IsZero() && entry.Time.Before(oldest.Time) {
+				cut = i + 1
+			}
+		}
+	}
+
+	older := make([]*logcat.Entry, 0, cut)
+	for _, entry := range backlog[:cut] {
+		m.nextEntryID++
+		entry.ID = m.nextEntryID
+		if len(older) > 0 && entry.Priority == logcat.Unknown && isContinuationText(entry.Message) {
+			entry.AttachTo(older[len(older)-1])
+		}
+		logcat.ApplyJSONMessage(entry)
+		for _, extractor := range m.extractors {
+			extractor.Apply(entry)
+		}
+		m.autoBookmark(entry)
+		m.bufferBytes += entry.Size()
+		m.levelCounts.add(entry, 1)
+		older = append(older, entry)
+	}
+	m.parsedEntries = append(older, m.parsedEntries...)
+	m.resetFilterCounts()
+	m.statusMessage = fmt.Sprintf("loaded %d older entries", len(older))
+
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+	if m.highlightedEntry != nil && !m.autoScroll {
+		m.ensureEntryVisible(m.highlightedEntry)
+	}
+}
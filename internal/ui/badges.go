@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// followInfo renders the follow state at the start of the header.
+func (m *Model) followInfo() string {
+	followInfo := lipgloss.NewStyle().Foreground(GetInfoColor()).Bold(true).Render("FOLLOW")
+	if !m.autoScroll {
+		followInfo = lipgloss.NewStyle().Foreground(GetWarnColor()).Bold(true).Render("PAUSED")
+	}
+	if m.importName != "" {
+		// Nothing streams in, so there is nothing to follow
+		followInfo = lipgloss.NewStyle().Foreground(GetAccentColor()).Bold(true).Render("IMPORT")
+	}
+	if m.frozen {
+		followInfo = lipgloss.NewStyle().Foreground(GetErrorColor()).Bold(true).
+			Render(fmt.Sprintf("FROZEN ON ERROR (%d held, F: resume)", len(m.heldEntries)))
+	}
+	if m.contextEntry != nil {
+		followInfo = lipgloss.NewStyle().Foreground(GetAccentColor()).Bold(true).
+			Render(fmt.Sprintf("CONTEXT ±%d (z/esc: back)", m.contextLines))
+	}
+	return followInfo
+}
+
+// levelInfo renders the shown log levels for the header.
+func (m *Model) levelInfo() string {
+	levelLabel := "log level"
+	if !m.levels.threshold() {
+		levelLabel = "log levels"
+	}
+	logLevelStyle := lipgloss.NewStyle().Foreground(GetPriorityColor(m.levels.lowest()))
+	return levelLabel + ": " + logLevelStyle.Render(m.levels.label())
+}
+
+// filterBadgesPrefix precedes the filter badges in the header.
+const filterBadgesPrefix = " | filters: "
+
+// filterBadge renders a filter with the number of entries it matches. Filters
+// turned off by clicking their badge are struck through.
+func (m *Model) filterBadge(i int) string {
+	f := m.filters[i]
+	filterText := f.String()
+	style := lipgloss.NewStyle().
+		Background(FilterColor(filterText)).
+		Foreground(lipgloss.AdaptiveColor{Light: "0", Dark: "0"}).
+		Padding(0, 1)
+	if f.disabled {
+		style = style.Background(lipgloss.Color("240")).Strikethrough(true)
+	}
+	return style.Render(fmt.Sprintf("%s %d", filterText, m.filterMatches(i)))
+}
+
+// filterMatches returns how many entries in the log the filter matches.
+func (m *Model) filterMatches(i int) int {
+	f := m.filters[i]
+	if f.flag != "" {
+		// Flags change after the entries arrive, and only a few entries carry them
+		count := 0
+		for _, flags := range m.flags {
+			if flags&flagNames[f.flag] != 0 {
+				count++
+			}
+		}
+		return count
+	}
+	return f.matches
+}
+
+// countFilterMatches counts the entries matched by filters added since the
+// last count; appendEntry keeps counted filters up to date.
+func (m *Model) countFilterMatches() {
+	for i := range m.filters {
+		f := &m.filters[i]
+		if f.counted || f.flag != "" {
+			continue
+		}
+		f.matches = 0
+		for _, entry := range m.parsedEntries {
+			if m.filterHits(*f, entry) {
+				f.matches++
+			}
+		}
+		f.counted = true
+	}
+}
+
+// resetFilterCounts recounts the filter matches, e.g. after the log is cleared.
+func (m *Model) resetFilterCounts() {
+	for i := range m.filters {
+		m.filters[i].counted = false
+	}
+}
+
+// filterBadgeAt returns the index of the filter whose header badge is at the
+// screen position, or -1.
+func (m *Model) filterBadgeAt(x, y int) int {
+	if len(m.filters) == 0 || m.filtersOff || m.footerPromptActive() {
+		return -1
+	}
+	headerHeight, footerHeight := m.layoutHeights()
+	// The header's first line sits below its top border, after the padding
+	if y != m.height-headerHeight-footerHeight+1 {
+		return -1
+	}
+	left := 1 + lipgloss.Width(m.followInfo()+" | "+m.levelInfo()+filterBadgesPrefix)
+	for i := range m.filters {
+		width := lipgloss.Width(m.filterBadge(i))
+		if x >= left && x < left+width {
+			return i
+		}
+		left += width + 1
+	}
+	return -1
+}
+
+// handleBadgeClick toggles the clicked filter on a left click, opens it in the
+// filter prompt on a right click and removes it on a middle click.
+func (m *Model) handleBadgeClick(msg tea.MouseMsg, i int) {
+	if msg.Action != tea.MouseActionRelease {
+		return
+	}
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		m.filters[i].disabled = !m.filters[i].disabled
+		state := "on"
+		if m.filters[i].disabled {
+			state = "off"
+		}
+		m.recordHistory(fmt.Sprintf("filter %s %s", m.filters[i].String(), state))
+	case tea.MouseButtonRight:
+		m.editFilter(i)
+		return
+	case tea.MouseButtonMiddle:
+		m.recordHistory("filter removed: " + m.filters[i].String())
+		m.filters = append(m.filters[:i], m.filters[i+1:]...)
+		m.syncFilterInput()
+	default:
+		return
+	}
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// editFilter opens the filter prompt with the cursor at the end of filter i.
+func (m *Model) editFilter(i int) {
+	m.syncFilterInput()
+	parts := make([]string, 0, i+1)
+	for _, f := range m.filters[:i+1] {
+		parts = append(parts, f.String())
+	}
+	m.showFilter = true
+	m.filterInput.Focus()
+	m.filterInput.SetCursor(len([]rune(strings.Join(parts, ", "))))
+}
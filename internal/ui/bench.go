@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// BenchOptions configures a headless benchmark run.
+type BenchOptions struct {
+	Lines  int // total synthetic lines to replay
+	Rate   int // simulated stream rate in lines per second
+	Width  int // simulated terminal size
+	Height int
+	Wrap   bool
+}
+
+// DefaultBenchOptions replays 500k lines at 50k lines/s into a 160x50 terminal.
+func DefaultBenchOptions() BenchOptions {
+	return BenchOptions{Lines: 500000, Rate: 50000, Width: 160, Height: 50}
+}
+
+var benchTags = []string{
+	"ActivityManager", "OkHttp", "RecyclerView", "Choreographer", "SurfaceFlinger",
+	"chromium", "WifiService", "AndroidRuntime", "MainViewModel", "SyncWorker",
+}
+
+var benchMessages = []string{
+	"Displayed com.example.app/.MainActivity: +412ms",
+	"--> GET https://api.example.com/v1/items?page=%d",
+	"<-- 200 OK https://api.example.com/v1/items (%dms, 12.4kB body)",
+	"Skipped %d frames!  The application may be doing too much work on its main thread.",
+	"No adapter attached; skipping layout",
+	"requestId=%d state=RUNNING attempt=1",
+	"java.lang.IllegalStateException: Fragment not attached to a context.",
+	"\tat com.example.app.ui.DetailFragment.onResume(DetailFragment.kt:%d)",
+	"Received intent: act=android.intent.action.BATTERY_CHANGED flg=0x60000010",
+}
+
+// benchLines generates a reproducible synthetic threadtime stream.
+func benchLines(n int) []string {
+	rng := rand.New(rand.NewSource(1))
+	priorities := "VVDDDDIIIIWWE"
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	lines := make([]string, n)
+	for i := range lines {
+		ts := start.Add(time.Duration(i) * 20 * time.Microsecond).Format("01-02 15:04:05.000")
+		pid := 1000 + rng.Intn(8)
+		tag := benchTags[rng.Intn(len(benchTags))]
+		message := benchMessages[rng.Intn(len(benchMessages))]
+		message = strings.ReplaceAll(message, "%d", strconv.Itoa(rng.Intn(5000)))
+		lines[i] = fmt.Sprintf("%s %5d %5d %c %s: %s", ts, pid, pid+rng.Intn(20),
+			priorities[rng.Intn(len(priorities))], tag, message)
+	}
+	return lines
+}
+
+// newBenchModel returns a model with the defaults of NewModel but no device or config.
+func newBenchModel(opts BenchOptions) Model {
+	m := Model{
+		levels:          allLevels,
+		levelCounts:     new(levelCounts),
+		narrowWidth:     DefaultNarrowWidth,
+		filters:         []Filter{},
+		parsedEntries:   make([]*logcat.Entry, 0, opts.Lines),
+		selectedEntries: make(map[uint64]bool),
+		annotations:     make(map[uint64]string),
+		flags:           make(map[uint64]entryFlags),
+		tagRates:        make(map[string]*tagRate),
+		budgetWarned:    make(map[string]bool),
+		autoScroll:      true,
+		coloredMessages: true,
+		wrapLines:       opts.Wrap,
+		showTimestamp:   true,
+		viewport:        viewport.New(opts.Width, opts.Height),
+		width:           opts.Width,
+		height:          opts.Height,
+		ready:           true,
+	}
+	SetNarrowLayout(opts.Width < m.narrowWidth)
+	return m
+}
+
+// RunBench replays a synthetic stream through the parser and renderer without a
+// terminal, one render per busy-stream render interval as in the UI, and writes a report.
+func RunBench(opts BenchOptions, w io.Writer) error {
+	if opts.Lines <= 0 || opts.Rate <= 0 || opts.Width <= 0 || opts.Height <= 0 {
+		return fmt.Errorf("benchmark lines, rate and size must be positive")
+	}
+	lines := benchLines(opts.Lines)
+
+	// Parse throughput on its own, so render cost doesn't hide parser regressions
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	parseStart := time.Now()
+	for _, line := range lines {
+		logcat.ParseLine(line)
+	}
+	parseTime := time.Since(parseStart)
+	runtime.ReadMemStats(&after)
+	parseAllocs := after.Mallocs - before.Mallocs
+
+	m := newBenchModel(opts)
+	perFrame := int(int64(opts.Rate) * int64(busyRenderInterval) / int64(time.Second))
+	if perFrame < 1 {
+		perFrame = 1
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	var frames []time.Duration
+	totalStart := time.Now()
+	for start := 0; start < len(lines); start += perFrame {
+		end := min(start+perFrame, len(lines))
+		for _, line := range lines[start:end] {
+			if entry, _ := logcat.ParseLine(line); entry != nil {
+				m.appendEntry(entry)
+			}
+		}
+		frameStart := time.Now()
+		m.updateViewportWithScroll(true)
+		m.View()
+		frames = append(frames, time.Since(frameStart))
+	}
+	totalTime := time.Since(totalStart)
+	runtime.ReadMemStats(&after)
+
+	m.renderReset = true
+	rebuildStart := time.Now()
+	m.updateViewportWithScroll(true)
+	rebuildTime := time.Since(rebuildStart)
+
+	sorted := append([]time.Duration(nil), frames...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	budget := busyRenderInterval
+	overBudget := 0
+	for _, frame := range frames {
+		if frame > budget {
+			overBudget++
+		}
+	}
+
+	n := float64(len(lines))
+	fmt.Fprintf(w, "lines:            %d (%d per frame at %d lines/s, %dx%d, wrap %v)\n",
+		len(lines), perFrame, opts.Rate, opts.Width, opts.Height, opts.Wrap)
+	fmt.Fprintf(w, "parse:            %s, %.0f lines/s, %.1f allocs/line\n",
+		parseTime.Round(time.Millisecond), n/parseTime.Seconds(), float64(parseAllocs)/n)
+	fmt.Fprintf(w, "parse+render:     %s, %.0f lines/s\n",
+		totalTime.Round(time.Millisecond), n/totalTime.Seconds())
+	fmt.Fprintf(w, "frame latency:    p50 %s, p95 %s, p99 %s, max %s (%d/%d frames over %s)\n",
+		percentile(0.50).Round(time.Microsecond), percentile(0.95).Round(time.Microsecond),
+		percentile(0.99).Round(time.Microsecond), sorted[len(sorted)-1].Round(time.Microsecond),
+		overBudget, len(frames), budget)
+	fmt.Fprintf(w, "full rebuild:     %s for %d entries\n", rebuildTime.Round(time.Microsecond), len(m.parsedEntries))
+	fmt.Fprintf(w, "allocations:      %.1f allocs/line, %.0f bytes/line, heap %d MiB\n",
+		float64(after.Mallocs-before.Mallocs)/n, float64(after.TotalAlloc-before.TotalAlloc)/n, after.HeapAlloc>>20)
+	return nil
+}
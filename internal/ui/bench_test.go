@@ -0,0 +1,15 @@
+package ui
+
+import (
+	"io"
+	"testing"
+)
+
+func TestRunBenchSmoke(t *testing.T) {
+	for _, wrap := range []bool{false, true} {
+		opts := BenchOptions{Lines: 2000, Rate: 50000, Width: 120, Height: 30, Wrap: wrap}
+		if err := RunBench(opts, io.Discard); err != nil {
+			t.Fatalf("RunBench(wrap=%v): %v", wrap, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// anyTagBudget is the tagBudgets key whose budget applies to tags without their own.
+const anyTagBudget = "*"
+
+// tagRate counts a tag's lines in the current one-minute window.
+type tagRate struct {
+	start time.Time
+	count int
+}
+
+// tagBudget returns the lines per minute allowed for a tag, or 0 for no limit.
+func (m *Model) tagBudget(tag string) int {
+	if budget, ok := m.tagBudgets[tag]; ok {
+		return budget
+	}
+	return m.tagBudgets[anyTagBudget]
+}
+
+// checkTagBudget counts an entry against its tag's budget and raises the
+// header warning the first time the tag goes over it. Windows are measured
+// in log time, so a tail of older entries doesn't look like a burst.
+func (m *Model) checkTagBudget(entry *logcat.Entry) {
+	budget := m.tagBudget(entry.Tag)
+	if budget <= 0 || entry.Unparsed {
+		return
+	}
+	at := entry.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	rate := m.tagRates[entry.Tag]
+	if rate == nil {
+		rate = &tagRate{start: at}
+		m.tagRates[entry.Tag] = rate
+	}
+	if at.Sub(rate.start) >= time.Minute {
+		rate.start, rate.count = at, 0
+	}
+	rate.count++
+	if rate.count > budget && !m.budgetWarned[entry.Tag] {
+		m.budgetWarned[entry.Tag] = true
+		m.budgetAlert = entry.Tag
+	}
+}
+
+// budgetInfo describes the tag over budget for the header.
+func (m *Model) budgetInfo() string {
+	rate := m.tagRates[m.budgetAlert]
+	return fmt.Sprintf("%s over budget: %d/min, limit %d (X: exclude)", m.budgetAlert, rate.count, m.tagBudget(m.budgetAlert))
+}
+
+// excludeBudgetTag adds an exclusion filter for the tag over budget.
+func (m *Model) excludeBudgetTag() {
+	if m.budgetAlert == "" {
+		m.statusMessage = "no tag is over its budget"
+		return
+	}
+	pattern := "^" + regexp.QuoteMeta(m.budgetAlert) + "$"
+	m.filtersOff = false
+	m.filters = append(m.filters, Filter{
+		isTag:   true,
+		exclude: true,
+		pattern: pattern,
+		regex:   regexp.MustCompile("(?i)" + pattern),
+	})
+	m.syncFilterInput()
+	m.recordHistory("filters: " + m.historyFilters())
+	m.statusMessage = "excluded tag " + m.budgetAlert
+	m.budgetAlert = ""
+	m.lineCache.clear()
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
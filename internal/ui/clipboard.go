@@ -5,6 +5,8 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+
+	"github.com/atotto/clipboard"
 )
 
 func copyToClipboard(text string) error {
@@ -12,13 +14,11 @@ func copyToClipboard(text string) error {
 	case "darwin":
 		return runClipboardCommand("pbcopy", nil, text)
 	case "windows":
-		if _, err := exec.LookPath("clip"); err == nil {
-			return runClipboardCommand("cmd", []string{"/c", "clip"}, text)
-		}
-		if _, err := exec.LookPath("powershell"); err == nil {
-			return runClipboardCommand("powershell", []string{"-NoProfile", "-Command", "Set-Clipboard"}, text)
-		}
-		return fmt.Errorf("no clipboard tool found")
+		// clip.exe reads stdin in the console code page and mangles UTF-8, so
+		// the text goes through the clipboard API as UTF-16 instead, which
+		// can't hold NUL characters
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\x00", ""), "\n", "\r\n")
+		return clipboard.WriteAll(text)
 	case "linux":
 		if _, err := exec.LookPath("wl-copy"); err == nil {
 			return runClipboardCommand("wl-copy", nil, text)
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// defaultContextLines is the number of neighbors shown on each side in context view.
+const defaultContextLines = 10
+
+// contextEntries returns the context entry with its unfiltered neighbors from the
+// full buffer, in arrival order.
+func (m *Model) contextEntries() []*logcat.Entry {
+	index := -1
+	for i, entry := range m.parsedEntries {
+		if entry.ID == m.contextEntry.ID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+
+	start := index - m.contextLines
+	if start < 0 {
+		start = 0
+	}
+	end := index + m.contextLines + 1
+	if end > len(m.parsedEntries) {
+		end = len(m.parsedEntries)
+	}
+	entries := make([]*logcat.Entry, end-start)
+	copy(entries, m.parsedEntries[start:end])
+	return entries
+}
+
+// enterContext shows the highlighted entry among its unfiltered neighbors, like grep -C.
+func (m *Model) enterContext() {
+	if m.highlightedEntry == nil {
+		m.statusMessage = "highlight an entry to show its context"
+		return
+	}
+	m.contextEntry = m.highlightedEntry
+	m.autoScroll = false
+	m.resetRenderCache()
+	m.updateViewportWithScroll(false)
+	m.ensureEntryVisible(m.contextEntry)
+}
+
+// exitContext returns to the filtered view, keeping the entry highlighted if it is visible there.
+func (m *Model) exitContext() {
+	entry := m.contextEntry
+	m.contextEntry = nil
+	if !m.isVisible(entry) {
+		m.highlightedEntry = nil
+	}
+	m.resetRenderCache()
+	m.updateViewportWithScroll(false)
+	m.ensureEntryVisible(m.highlightedEntry)
+}
@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+	"github.com/muesli/reflow/wrap"
+)
+
+const (
+	// detailChromeHeight is the number of rows around the detail viewport: the
+	// title, a blank line and the help line.
+	detailChromeHeight = 3
+	// detailSidebarWidth is the width of the field table beside the message.
+	detailSidebarWidth = 44
+	// detailKeyWidth caps the key column of the field table.
+	detailKeyWidth = 16
+	// detailStackedRows caps the field table above the message in the narrow layout.
+	detailStackedRows = 8
+)
+
+// detailField is one row of the detail view's field table.
+type detailField struct {
+	key   string
+	value string
+}
+
+// openDetail shows the full message of an entry in a scrollable view.
+func (m *Model) openDetail(entry *logcat.Entry) {
+	m.detailEntry = entry
+	m.detailFields = entryFields(entry)
+	m.detailFieldIndex = 0
+	m.showDetail = true
+	m.detailViewport = viewport.New(0, 0)
+	m.resizeDetail()
+}
+
+// resizeDetail lays out the field table and message for the terminal size.
+func (m *Model) resizeDetail() {
+	offset := m.detailViewport.YOffset
+	width, height := m.width, m.height-detailChromeHeight
+	if m.detailSidebar() {
+		width -= detailSidebarWidth + 2 // the sidebar's border and a gap
+	} else {
+		height -= m.detailStackedRows() + 1
+	}
+	m.detailViewport.Width = max(1, width)
+	m.detailViewport.Height = max(1, height)
+	m.detailViewport.SetContent(m.detailMessage(m.detailViewport.Width))
+	m.detailViewport.SetYOffset(offset)
+}
+
+// detailSidebar reports whether the field table fits beside the message.
+func (m *Model) detailSidebar() bool {
+	return !narrowLayout && len(m.detailFields) > 0
+}
+
+// detailStackedRows is the height of the field table above the message.
+func (m *Model) detailStackedRows() int {
+	return min(len(m.detailFields), detailStackedRows)
+}
+
+// entryFields lists an entry's columns followed by its extracted and JSON
+// fields in key order.
+func entryFields(entry *logcat.Entry) []detailField {
+	var fields []detailField
+	add := func(key, value string) {
+		if value != "" {
+			fields = append(fields, detailField{key, value})
+		}
+	}
+	add("time", formatTimestamp(entry))
+	if !entry.Unparsed {
+		add("level", entry.Priority.Name())
+		add("tag", entry.Tag)
+		add("pid", entry.PID)
+		add("tid", entry.TID)
+	}
+	add("source", entry.Source)
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		add(key, entry.Fields[key])
+	}
+	add("length", formatSize(len(entry.Message)))
+	return fields
+}
+
+// detailMessage returns the full message with JSON payloads indented,
+// wrapped to the given width.
+func (m *Model) detailMessage(width int) string {
+	message := m.redactText(indentJSON(m.detailEntry.Message))
+	if width > 0 {
+		message = wrap.String(message, width)
+	}
+	return message
+}
+
+// indentJSON pretty-prints a message that ends in a JSON object or array,
+// keeping any text before it, and returns other messages unchanged.
+func indentJSON(message string) string {
+	start := strings.IndexAny(message, "{[")
+	if start < 0 {
+		return message
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(message[start:]), "", "  "); err != nil {
+		return message
+	}
+	prefix := strings.TrimSpace(message[:start])
+	if prefix == "" {
+		return out.String()
+	}
+	return prefix + "\n" + out.String()
+}
+
+// handleDetailKey handles keys while the detail view is open; anything else
+// scrolls the message.
+func (m *Model) handleDetailKey(key string) bool {
+	switch key {
+	case "esc", "enter", "q":
+		m.showDetail = false
+		m.detailEntry = nil
+		m.detailFields = nil
+		return true
+	case "tab":
+		if len(m.detailFields) > 0 {
+			m.detailFieldIndex = (m.detailFieldIndex + 1) % len(m.detailFields)
+		}
+		return true
+	case "shift+tab":
+		if len(m.detailFields) > 0 {
+			m.detailFieldIndex = (m.detailFieldIndex + len(m.detailFields) - 1) % len(m.detailFields)
+		}
+		return true
+	case "y":
+		if len(m.detailFields) > 0 {
+			field := m.detailFields[m.detailFieldIndex]
+			m.copyDetail(field.value, field.key)
+		}
+		return true
+	case "c":
+		m.copyDetail(m.detailEntry.Message, "message")
+		return true
+	}
+	return false
+}
+
+// copyDetail copies text from the detail view and reports what was copied.
+func (m *Model) copyDetail(text, what string) {
+	if err := copyToClipboard(m.redactText(text)); err != nil {
+		m.statusMessage = "copy failed: " + err.Error()
+		return
+	}
+	m.statusMessage = what + " copied"
+}
+
+// fieldTable renders the field table with the selected row highlighted,
+// scrolled so the selection stays within rows.
+func (m *Model) fieldTable(width, rows int) []string {
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	keyWidth := 0
+	for _, field := range m.detailFields {
+		keyWidth = max(keyWidth, len(field.key))
+	}
+	keyWidth = min(keyWidth, detailKeyWidth)
+	valueWidth := max(1, width-keyWidth-1)
+
+	first := max(0, m.detailFieldIndex-rows+1)
+	var lines []string
+	for i := first; i < len(m.detailFields) && i < first+rows; i++ {
+		field := m.detailFields[i]
+		key := fmt.Sprintf("%-*s", keyWidth, truncate(field.key, keyWidth))
+		value := truncate(strings.ReplaceAll(m.redactText(field.value), "\n", " "), valueWidth)
+		if i == m.detailFieldIndex {
+			lines = append(lines, selectedLineStyle.Render(fmt.Sprintf("%s %-*s", key, valueWidth, value)))
+			continue
+		}
+		lines = append(lines, keyStyle.Render(key)+" "+value)
+	}
+	return lines
+}
+
+// detailView renders the detail view: the field table beside or above the
+// message, with a title and help line.
+func (m *Model) detailView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	var body string
+	if m.detailSidebar() {
+		sidebar := lipgloss.NewStyle().
+			Width(detailSidebarWidth).
+			Height(m.detailViewport.Height).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeft(true).
+			PaddingLeft(1).
+			Render(strings.Join(m.fieldTable(detailSidebarWidth-2, m.detailViewport.Height), "\n"))
+		body = lipgloss.JoinHorizontal(lipgloss.Top, m.detailViewport.View(), " ", sidebar)
+	} else {
+		table := m.fieldTable(m.width, m.detailStackedRows())
+		body = strings.Join(table, "\n") + "\n\n" + m.detailViewport.View()
+	}
+
+	title := titleStyle.Render("Entry detail")
+	help := fmt.Sprintf("j/k/pgup/pgdn: scroll | tab: next field | y: copy field | c: copy message | esc: back | %3.0f%%", m.detailViewport.ScrollPercent()*100)
+	if m.statusMessage != "" {
+		help = m.statusMessage + " | " + help
+	}
+	return title + "\n" + body + "\n\n" + helpStyle.MaxWidth(m.width).Render(help)
+}
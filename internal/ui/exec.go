@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+const (
+	// execTag is the tag of the markers added when the --exec command starts and ends.
+	execTag = "exec"
+	// maxExecLines caps the command output kept for the exec pane.
+	maxExecLines = 1000
+	// maxExecPaneRows is the most rows the exec pane takes, including its divider.
+	maxExecPaneRows = 9
+)
+
+var execCommand string
+
+// SetExec runs command, e.g. "./gradlew installDebug", alongside the log: its
+// output is mirrored in a pane below the log and its start and end are marked
+// in the log.
+func SetExec(command string) {
+	execCommand = command
+}
+
+// execRun is a run of the --exec command.
+type execRun struct {
+	cmd     *exec.Cmd
+	lines   <-chan string
+	done    <-chan error
+	started time.Time
+	running bool
+}
+
+type execStartedMsg struct {
+	cmd   *exec.Cmd
+	lines <-chan string
+	done  <-chan error
+}
+
+type execLineMsg struct{ lines []string }
+
+type execDoneMsg struct{ err error }
+
+// startExec runs the --exec command through the shell.
+func startExec() tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", execCommand)
+		} else {
+			cmd = exec.Command("sh", "-c", execCommand)
+		}
+		setProcessGroup(cmd)
+		reader, writer := io.Pipe()
+		cmd.Stdout = writer
+		cmd.Stderr = writer
+		if err := cmd.Start(); err != nil {
+			return execDoneMsg{err}
+		}
+
+		lines := make(chan string, maxLogBatch)
+		done := make(chan error, 1)
+		go func() {
+			err := cmd.Wait()
+			writer.Close()
+			done <- err
+		}()
+		go func() {
+			scanner := bufio.NewScanner(reader)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+			// Keep the pipe drained if the line is too long to scan
+			_, _ = io.Copy(io.Discard, reader)
+			close(lines)
+		}()
+		return execStartedMsg{cmd: cmd, lines: lines, done: done}
+	}
+}
+
+// waitForExecLines returns the next batch of command output, or the command's
+// exit once its output ends.
+func waitForExecLines(lines <-chan string, done <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-lines
+		if !ok {
+			return execDoneMsg{<-done}
+		}
+		batch := []string{line}
+		for len(batch) < maxLogBatch {
+			select {
+			case next, ok := <-lines:
+				if !ok {
+					return execLineMsg{batch}
+				}
+				batch = append(batch, next)
+			default:
+				return execLineMsg{batch}
+			}
+		}
+		return execLineMsg{batch}
+	}
+}
+
+// rerunExec runs the --exec command again once the last run has ended.
+func (m *Model) rerunExec() tea.Cmd {
+	if execCommand == "" {
+		m.statusMessage = "no command to run (--exec)"
+		return nil
+	}
+	if m.exec.running {
+		m.statusMessage = execCommand + " is still running"
+		return nil
+	}
+	m.exec = execRun{running: true}
+	return startExec()
+}
+
+// execStarted marks the start of the command in the log.
+func (m *Model) execStarted(msg execStartedMsg) tea.Cmd {
+	m.exec = execRun{cmd: msg.cmd, lines: msg.lines, done: msg.done, started: time.Now(), running: true}
+	m.execOutput = append(m.execOutput, "$ "+execCommand)
+	m.appendMarker(logcat.Info, execTag, execCommand+" started")
+	return waitForExecLines(msg.lines, msg.done)
+}
+
+// appendExecOutput adds command output to the exec pane.
+func (m *Model) appendExecOutput(lines []string) {
+	for _, line := range lines {
+		m.execOutput = append(m.execOutput, ansi.Strip(line))
+	}
+	if len(m.execOutput) > maxExecLines {
+		m.execOutput = m.execOutput[len(m.execOutput)-maxExecLines:]
+	}
+}
+
+// execDone marks the end of the command in the log.
+func (m *Model) execDone(err error) {
+	m.exec.running = false
+	if m.exec.cmd == nil {
+		// The command didn't start
+		m.statusMessage = fmt.Sprintf("failed to run %s: %v", execCommand, err)
+		return
+	}
+	took := time.Since(m.exec.started).Round(time.Second)
+	if err != nil {
+		text := fmt.Sprintf("%s failed after %s: %v", execCommand, took, err)
+		m.execOutput = append(m.execOutput, text)
+		m.appendMarker(logcat.Error, execTag, text)
+		m.statusMessage = text
+		return
+	}
+	text := fmt.Sprintf("%s finished in %s", execCommand, took)
+	m.execOutput = append(m.execOutput, text)
+	m.appendMarker(logcat.Info, execTag, text)
+}
+
+// stopExec kills a running command and everything it started, e.g. when
+// logdog quits, so a build doesn't keep installing to the device.
+func (m *Model) stopExec() {
+	if m.exec.running && m.exec.cmd != nil && m.exec.cmd.Process != nil {
+		_ = killProcessGroup(m.exec.cmd.Process.Pid)
+	}
+}
+
+// execRows returns the rows the exec pane and its divider take out of the
+// height available to the log.
+func (m *Model) execRows(available int) int {
+	if execCommand == "" || m.importName != "" {
+		return 0
+	}
+	return min(maxExecPaneRows, available/4)
+}
+
+// execPaneRows is the height of the exec pane including its divider.
+func (m *Model) execPaneRows() int {
+	headerHeight, footerHeight := m.layoutHeights()
+	return m.execRows(max(0, m.height-headerHeight-footerHeight-m.stickyRows()))
+}
+
+// execView renders the divider and the last lines of the command output.
+func (m *Model) execView() string {
+	height := max(0, m.execPaneRows()-1)
+	dividerStyle := lipgloss.NewStyle().Foreground(GetAccentColor())
+	state := "finished"
+	if m.exec.running {
+		state = "running"
+	}
+	label := fmt.Sprintf("── %s · %s (e: run again) ", execCommand, state)
+	divider := label + strings.Repeat("─", max(0, m.width-lipgloss.Width(label)))
+
+	start := max(0, len(m.execOutput)-height)
+	lines := make([]string, 0, height)
+	for _, line := range m.execOutput[start:] {
+		lines = append(lines, ansi.Truncate(line, m.width, ""))
+	}
+	body := lipgloss.NewStyle().
+		Width(m.width).
+		Height(height).
+		MaxHeight(height).
+		Foreground(lipgloss.Color("245")).
+		Render(strings.Join(lines, "\n"))
+	return dividerStyle.Render(ansi.Truncate(divider, m.width, "")) + "\n" + body
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeAdb puts an adb on the PATH that lists the given `adb devices -l` lines.
+func fakeAdb(t *testing.T, devices string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf 'List of devices attached\\n" + devices + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, "adb"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake adb: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	// Keep the user's saved preferences out of the model
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+}
+
+func TestNewModelRunsExecWithSingleDevice(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	fakeAdb(t, `emulator-5554 device model:Pixel\n`)
+	SetExec("true")
+	defer SetExec("")
+
+	m := NewModel("", 0)
+	if got := m.logManager.DeviceSerial(); got != "emulator-5554" {
+		t.Fatalf("expected the only device to be used, got %q", got)
+	}
+	if !m.exec.running {
+		t.Fatal("expected --exec to start with a single device attached")
+	}
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// defaultExportFormat is used when the config names no or an unknown format.
+const defaultExportFormat = "plain"
+
+// exporter returns the selected export format. Plain exports follow raw mode,
+// so exported lines look like the view.
+func (m *Model) exporter() logcat.Exporter {
+	name := m.exportFormat
+	if name == "" {
+		name = defaultExportFormat
+	}
+	if name == "plain" && m.rawMode {
+		name = "raw"
+	}
+	exporter, err := logcat.ExporterNamed(name)
+	if err != nil {
+		exporter, _ = logcat.ExporterNamed(defaultExportFormat)
+	}
+	return exporter
+}
+
+// rawExporter returns the exporter for raw logcat lines.
+func rawExporter() logcat.Exporter {
+	exporter, _ := logcat.ExporterNamed("raw")
+	return exporter
+}
+
+// export renders entries in the selected format; see exportAs.
+func (m *Model) export(entries []*logcat.Entry) string {
+	return m.exportAs(m.exporter(), entries)
+}
+
+// exportAs renders entries with their flags and notes, redacted when
+// redaction is on. Redaction applies to the entries rather than the output,
+// so formats that escape text can't hide a match from it.
+func (m *Model) exportAs(exporter logcat.Exporter, entries []*logcat.Entry) string {
+	notes := m.exportNotes(entries)
+	if m.redact {
+		entries = m.redactEntries(entries)
+		for id, lines := range notes {
+			for i, line := range lines {
+				lines[i] = m.redactText(line)
+			}
+			notes[id] = lines
+		}
+	}
+	var b strings.Builder
+	// Writing to a strings.Builder can't fail
+	_ = exporter.Write(&b, entries, notes)
+	return b.String()
+}
+
+// exportNotes returns the flags and annotation of each entry that has any.
+func (m *Model) exportNotes(entries []*logcat.Entry) map[uint64][]string {
+	notes := make(map[uint64][]string)
+	for _, entry := range entries {
+		if flags := m.flags[entry.ID]; flags != 0 {
+			notes[entry.ID] = append(notes[entry.ID], "flags: "+strings.Join(flags.names(), ", "))
+		}
+		if note, ok := m.annotations[entry.ID]; ok {
+			notes[entry.ID] = append(notes[entry.ID], "note: "+note)
+		}
+	}
+	return notes
+}
+
+// redactEntries returns redacted copies of entries, leaving the log untouched.
+func (m *Model) redactEntries(entries []*logcat.Entry) []*logcat.Entry {
+	redacted := make([]*logcat.Entry, len(entries))
+	for i, entry := range entries {
+		clone := *entry
+		clone.Tag = m.redactText(entry.Tag)
+		clone.Message = m.redactText(entry.Message)
+		clone.Raw = m.redactText(entry.Raw)
+		if len(entry.Fields) > 0 {
+			clone.Fields = make(map[string]string, len(entry.Fields))
+			for key, value := range entry.Fields {
+				clone.Fields[key] = m.redactText(value)
+			}
+		}
+		redacted[i] = &clone
+	}
+	return redacted
+}
+
+// startExportPicker opens the export menu on the current format.
+func (m *Model) startExportPicker() {
+	m.showExportPicker = true
+	m.exportPickerIndex = 0
+	for i, name := range logcat.ExporterNames() {
+		if name == m.exportFormat {
+			m.exportPickerIndex = i
+		}
+	}
+}
+
+func (m *Model) handleExportPickerKey(key string) {
+	exporters := logcat.Exporters()
+	switch key {
+	case "esc", "P":
+		m.showExportPicker = false
+	case "j", "down":
+		m.exportPickerIndex = (m.exportPickerIndex + 1) % len(exporters)
+	case "k", "up":
+		m.exportPickerIndex = (m.exportPickerIndex + len(exporters) - 1) % len(exporters)
+	case "enter", " ":
+		m.exportFormat = exporters[m.exportPickerIndex].Name()
+		m.showExportPicker = false
+		m.statusMessage = "exporting as " + m.exportFormat
+	case "s", "a":
+		m.showExportPicker = false
+		m.saveEntries(exporters[m.exportPickerIndex], key == "a")
+	}
+}
+
+func (m *Model) exportPickerView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle := itemStyle.Foreground(GetAccentColor()).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	current := m.exportFormat
+	if current == "" {
+		current = defaultExportFormat
+	}
+	lines := []string{titleStyle.Render("Export")}
+	for i, exporter := range logcat.Exporters() {
+		cursor, style := " ", itemStyle
+		if i == m.exportPickerIndex {
+			cursor, style = "›", selectedStyle
+		}
+		checkbox := "( )"
+		if exporter.Name() == current {
+			checkbox = "(•)"
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%s %s %-10s .%s", cursor, checkbox, exporter.Name(), exporter.Extension())))
+	}
+	lines = append(lines,
+		"",
+		helpStyle.Render("The selected format is used when copying, sharing, opening in the pager or editor and in report bundles. Plain follows raw mode."),
+		helpStyle.Render(fmt.Sprintf("s saves the %d visible entries in the highlighted format to a timestamped file, a saves all %d.", len(m.getVisibleEntries()), len(m.parsedEntries))),
+		"",
+		helpStyle.Render("enter: select | s: save visible | a: save all | j/k: move | esc: back"),
+	)
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
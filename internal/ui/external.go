@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type externalDoneMsg struct{ err error }
+
+// externalRunning is set while a pager or editor has the terminal.
+var externalRunning atomic.Bool
+
+// ExternalRunning reports whether a pager or editor has the terminal, so
+// Ctrl-C goes to it rather than quitting logdog.
+func ExternalRunning() bool {
+	return externalRunning.Load()
+}
+
+// externalCommand runs a program with the terminal released, noting while it runs.
+type externalCommand struct{ *exec.Cmd }
+
+func (c externalCommand) Run() error {
+	externalRunning.Store(true)
+	defer externalRunning.Store(false)
+	return c.Cmd.Run()
+}
+
+func (c externalCommand) SetStdin(r io.Reader) {
+	if c.Stdin == nil {
+		c.Stdin = r
+	}
+}
+
+func (c externalCommand) SetStdout(w io.Writer) {
+	if c.Stdout == nil {
+		c.Stdout = w
+	}
+}
+
+func (c externalCommand) SetStderr(w io.Writer) {
+	if c.Stderr == nil {
+		c.Stderr = w
+	}
+}
+
+// pagerCommand returns $PAGER or a platform default.
+func pagerCommand() string {
+	if pager := strings.TrimSpace(os.Getenv("PAGER")); pager != "" {
+		return pager
+	}
+	if runtime.GOOS == "windows" {
+		return "more"
+	}
+	return "less -R"
+}
+
+// editorCommand returns $VISUAL, $EDITOR or a platform default.
+func editorCommand() string {
+	if editor := strings.TrimSpace(os.Getenv("VISUAL")); editor != "" {
+		return editor
+	}
+	if editor := strings.TrimSpace(os.Getenv("EDITOR")); editor != "" {
+		return editor
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// openExternal writes content to a temp file with the given extension and
+// suspends the TUI while command views it.
+func openExternal(command, content, extension string) tea.Cmd {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return func() tea.Msg { return externalDoneMsg{fmt.Errorf("no command to open the view with")} }
+	}
+	file, err := os.CreateTemp("", "logdog-*."+extension)
+	if err != nil {
+		return func() tea.Msg { return externalDoneMsg{fmt.Errorf("create temp file: %w", err)} }
+	}
+	path := file.Name()
+	_, writeErr := file.WriteString(content)
+	closeErr := file.Close()
+	if writeErr != nil || closeErr != nil {
+		os.Remove(path)
+		return func() tea.Msg { return externalDoneMsg{fmt.Errorf("write temp file: %s", path)} }
+	}
+
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	// The program's output may be wrapped for synchronized updates, which
+	// would turn the pager's terminal into a pipe
+	cmd.Stdout = os.Stdout
+	return tea.Exec(externalCommand{cmd}, func(err error) tea.Msg {
+		os.Remove(path)
+		return externalDoneMsg{err}
+	})
+}
@@ -0,0 +1,31 @@
+package ui
+
+import "testing"
+
+func TestParseFiltersEscapedLeadingDashOrBang(t *testing.T) {
+	var m Model
+	m.parseFilters(`\-1, \!important, !heartbeat`)
+	if len(m.filters) != 3 {
+		t.Fatalf("expected 3 filters, got %d", len(m.filters))
+	}
+	for i, want := range []struct {
+		pattern string
+		exclude bool
+		text    string
+	}{
+		{"-1", false, `\-1`},
+		{"!important", false, `\!important`},
+		{"heartbeat", true, "-heartbeat"},
+	} {
+		f := m.filters[i]
+		if f.pattern != want.pattern || f.exclude != want.exclude {
+			t.Errorf("filter %d: got pattern %q exclude %v, want %q exclude %v", i, f.pattern, f.exclude, want.pattern, want.exclude)
+		}
+		if got := f.String(); got != want.text {
+			t.Errorf("filter %d: String() = %q, want %q", i, got, want.text)
+		}
+	}
+	if !m.filters[0].regex.MatchString("retry in -1 ms") {
+		t.Error("expected the escaped filter to match a literal -1")
+	}
+}
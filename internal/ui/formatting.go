@@ -2,20 +2,238 @@ package ui
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
 	"github.com/muesli/reflow/wrap"
 )
 
 const (
 	DefaultTagColumnWidth = 30
+	minTagColumnWidth     = 4
+	maxTagColumnWidth     = 80
 	timestampColumnWidth  = 18
+	shortTimestampWidth   = 12
+	secondsTimestampWidth = 8
+	DefaultNarrowWidth    = 100
+	extraColumnMinWidth   = 8
+	extraColumnMaxWidth   = 24
+	// DefaultMaxLineLength is the number of message bytes displayed before the
+	// rest is cut off; the detail view and exports still show the full text.
+	DefaultMaxLineLength = 2000
 )
 
 var tagColumnWidth = DefaultTagColumnWidth
 
+// matchHighlightStyle marks the text in a message matched by the search or a
+// message filter.
+var matchHighlightStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.AdaptiveColor{Light: "221", Dark: "179"})
+
+var extraColumns []string
+
+// narrowLayout drops the tag column in favor of an inline tag prefix and
+// shortens timestamps to the time of day, for narrow terminals.
+var narrowLayout bool
+
+// SetNarrowLayout switches the compact layout used for narrow terminals on or off.
+func SetNarrowLayout(narrow bool) {
+	narrowLayout = narrow
+}
+
+var maxLineLength = DefaultMaxLineLength
+
+// SetMaxLineLength sets how many bytes of a message are displayed. Zero or less
+// restores the default.
+func SetMaxLineLength(n int) {
+	if n <= 0 {
+		n = DefaultMaxLineLength
+	}
+	maxLineLength = n
+}
+
+// displayMessage cuts a message longer than the maximum line length, noting how
+// much was left out, so huge payloads don't stall wrapping and styling.
+func displayMessage(message string) string {
+	if len(message) <= maxLineLength {
+		return message
+	}
+	cut := maxLineLength
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + fmt.Sprintf("…(+%s, enter to view)", formatSize(len(message)-cut))
+}
+
+// formatSize formats a byte count as B, KB or MB.
+func formatSize(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%dB", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%dKB", (n+512)/1024)
+	}
+	return fmt.Sprintf("%.1fMB", float64(n)/(1024*1024))
+}
+
+// timestampFormat selects how much of the timestamp is shown.
+type timestampFormat int
+
+const (
+	timestampFull    timestampFormat = iota // MM-DD HH:MM:SS.mmm
+	timestampTime                           // HH:MM:SS.mmm
+	timestampSeconds                        // HH:MM:SS
+	timestampFormatCount
+)
+
+var timestampFormatNames = []string{"full", "time", "seconds"}
+
+func (f timestampFormat) String() string {
+	return timestampFormatNames[f]
+}
+
+// parseTimestampFormat returns the format with the given config name, or full.
+func parseTimestampFormat(name string) timestampFormat {
+	for i, n := range timestampFormatNames {
+		if n == name {
+			return timestampFormat(i)
+		}
+	}
+	return timestampFull
+}
+
+var currentTimestampFormat = timestampFull
+
+// SetTimestampFormat sets the timestamp format by config name: "full", "time" or "seconds".
+func SetTimestampFormat(name string) {
+	currentTimestampFormat = parseTimestampFormat(name)
+}
+
+// TimestampFormat returns the config name of the current timestamp format.
+func TimestampFormat() string {
+	return currentTimestampFormat.String()
+}
+
+// effectiveTimestampFormat is the configured format, without the date in the narrow layout.
+func effectiveTimestampFormat() timestampFormat {
+	if narrowLayout && currentTimestampFormat == timestampFull {
+		return timestampTime
+	}
+	return currentTimestampFormat
+}
+
+// timestampWidth returns the width of the timestamp column in the current layout.
+func timestampWidth() int {
+	switch effectiveTimestampFormat() {
+	case timestampTime:
+		return shortTimestampWidth
+	case timestampSeconds:
+		return secondsTimestampWidth
+	}
+	return timestampColumnWidth
+}
+
+// timestampText returns the timestamp column text in the current format.
+func timestampText(e *logcat.Entry) string {
+	ts := formatTimestamp(e)
+	if len(ts) != timestampColumnWidth {
+		return ts
+	}
+	switch effectiveTimestampFormat() {
+	case timestampTime:
+		return ts[timestampColumnWidth-shortTimestampWidth:]
+	case timestampSeconds:
+		start := timestampColumnWidth - shortTimestampWidth
+		return ts[start : start+secondsTimestampWidth]
+	}
+	return ts
+}
+
+// inlineTagStyle is the dim style of the tag prefix shown in place of the tag column.
+func inlineTagStyle(tag string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(TagColor(tag)).Faint(true)
+}
+
+var timestampShift time.Duration
+
+// SetTimestampShift sets an offset subtracted from entry times before display,
+// e.g. the device clock skew to show timestamps in host time.
+func SetTimestampShift(shift time.Duration) {
+	timestampShift = shift
+}
+
+var displayLocation *time.Location
+
+// SetDisplayLocation sets the time zone timestamps are converted to before display.
+// A nil location keeps the device's local time.
+func SetDisplayLocation(loc *time.Location) {
+	displayLocation = loc
+}
+
+// formatTimestamp returns the timestamp shown for an entry.
+func formatTimestamp(e *logcat.Entry) string {
+	if (timestampShift == 0 && displayLocation == nil) || e.Time.IsZero() {
+		return e.Timestamp
+	}
+	t := e.Time.Add(-timestampShift)
+	if displayLocation != nil {
+		t = t.In(displayLocation)
+	}
+	return t.Format("01-02 15:04:05.000")
+}
+
+// SetExtraColumns sets the extracted field names rendered as columns before the message.
+func SetExtraColumns(names []string) {
+	extraColumns = names
+}
+
+// ExtraColumns returns the extracted field names rendered as columns.
+func ExtraColumns() []string {
+	return extraColumns
+}
+
+func extraColumnWidth(name string) int {
+	width := len(name)
+	if width < extraColumnMinWidth {
+		width = extraColumnMinWidth
+	}
+	if width > extraColumnMaxWidth {
+		width = extraColumnMaxWidth
+	}
+	return width
+}
+
+// extraColumnsText returns the padded extracted field values, each followed by a separator.
+func extraColumnsText(e *logcat.Entry, continuation bool) string {
+	if len(extraColumns) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, name := range extraColumns {
+		width := extraColumnWidth(name)
+		value := ""
+		if !continuation {
+			value, _ = e.Field(name)
+		}
+		fmt.Fprintf(&b, "%-*s ", width, truncate(value, width))
+	}
+	return b.String()
+}
+
+func extraColumnsBlank() string {
+	width := 0
+	for _, name := range extraColumns {
+		width += extraColumnWidth(name) + 1
+	}
+	return strings.Repeat(" ", width)
+}
+
 // SetTagColumnWidth allows adjusting the global tag column width used for rendering.
 func SetTagColumnWidth(width int) {
 	if width <= 0 {
@@ -30,105 +248,200 @@ func TagColumnWidth() int {
 	return tagColumnWidth
 }
 
-// FormatEntry returns a formatted string with optional timestamp display.
-// When continuation is true, timestamp, tag, and priority columns are blanked
-// to visually indicate that the entry belongs to the previous timestamp.
-func FormatEntry(e *logcat.Entry, style lipgloss.Style, showTag bool, showTimestamp bool, logLevelBackground bool, coloredMessages bool, continuation bool) string {
-	lines := FormatEntryLines(e, style, showTag, showTimestamp, logLevelBackground, coloredMessages, continuation, 0)
-	return strings.Join(lines, "\n")
-}
-
 // FormatEntryLines returns formatted lines with ANSI-aware wrapping.
 // maxWidth is the full line width; when <= 0, wrapping is disabled.
-func FormatEntryLines(e *logcat.Entry, style lipgloss.Style, showTag bool, showTimestamp bool, logLevelBackground bool, coloredMessages bool, continuation bool, maxWidth int) []string {
-	// Get subtle color based on log level
-	var subtleColor lipgloss.TerminalColor
-	var priorityBgColor lipgloss.TerminalColor
-	switch e.Priority {
-	case logcat.Verbose:
-		subtleColor = GetVerboseColor()
-		priorityBgColor = GetVerboseBgColor()
-	case logcat.Debug:
-		subtleColor = GetDebugColor()
-		priorityBgColor = GetDebugBgColor()
-	case logcat.Info:
-		subtleColor = GetInfoColor()
-		priorityBgColor = GetInfoBgColor()
-	case logcat.Warn:
-		subtleColor = GetWarnColor()
-		priorityBgColor = GetWarnBgColor()
-	case logcat.Error:
-		subtleColor = GetErrorColor()
-		priorityBgColor = GetErrorBgColor()
-	case logcat.Fatal:
-		subtleColor = GetFatalColor()
-		priorityBgColor = GetFatalBgColor()
-	default:
-		subtleColor = colorDefault
-		priorityBgColor = GetVerboseBgColor()
+// The background of lineStyle, e.g. for selected or highlighted entries, is
+// applied to every column while keeping their colors. When continuation is
+// true, timestamp, tag, and priority columns are blanked to visually indicate
+// that the entry belongs to the previous timestamp. Text in the message
+// matched by any of highlights is rendered with matchHighlightStyle.
+func FormatEntryLines(e *logcat.Entry, lineStyle lipgloss.Style, showTag bool, showTimestamp bool, logLevelBackground bool, coloredMessages bool, continuation bool, maxWidth int, highlights []*regexp.Regexp) []string {
+	background := lineStyle.GetBackground()
+	_, plain := background.(lipgloss.NoColor)
+	// blank renders padding; plain lines skip the style for speed
+	blank := func(width int) string {
+		if plain {
+			return strings.Repeat(" ", width)
+		}
+		return lineStyle.Render(strings.Repeat(" ", width))
 	}
 
 	priorityStyle := lipgloss.NewStyle().Bold(true)
 	if logLevelBackground {
 		priorityStyle = priorityStyle.
 			Foreground(lipgloss.AdaptiveColor{Light: "255", Dark: "0"}).
-			Background(priorityBgColor)
+			Background(GetPriorityBgColor(e.Priority))
 	} else {
-		priorityStyle = priorityStyle.Foreground(subtleColor)
+		priorityStyle = priorityStyle.
+			Foreground(GetPriorityColor(e.Priority)).
+			Background(background)
 	}
 
 	tagStyle := lipgloss.NewStyle().
-		Foreground(TagColor(e.Tag))
+		Foreground(TagColor(e.Tag)).
+		Background(background)
 
-	messageColor := lipgloss.TerminalColor(lipgloss.AdaptiveColor{Light: "0", Dark: "254"})
-	if coloredMessages {
-		messageColor = subtleColor
+	messageStyle := GetPriorityStyle(e.Priority)
+	if !plain {
+		messageStyle = messageStyle.Background(background)
+	}
+	if !coloredMessages {
+		messageStyle = messageStyle.Foreground(lipgloss.AdaptiveColor{Light: "0", Dark: "254"})
+	}
+	if e.Unparsed {
+		messageStyle = messageStyle.Foreground(GetUnknownColor()).Italic(true)
 	}
-	messageStyle := lipgloss.NewStyle().Foreground(messageColor)
 
 	var tagStr string
 	if showTag && !continuation {
 		tagText := truncate(e.Tag, TagColumnWidth())
 		tagStr = tagStyle.Render(fmt.Sprintf("%*s", TagColumnWidth(), tagText))
 	} else {
-		tagStr = strings.Repeat(" ", TagColumnWidth())
+		tagStr = blank(TagColumnWidth())
+	}
+	tagStr += blank(1)
+	tagBlank := blank(TagColumnWidth() + 1)
+	inlineTag := ""
+	if narrowLayout {
+		tagStr, tagBlank = "", ""
+		if showTag && !continuation && e.Tag != "" {
+			inlineTag = inlineTagStyle(e.Tag).Background(background).Render(e.Tag + ": ")
+		}
 	}
 
 	priorityWidth := len(e.Priority.String()) + 2
-	priorityStr := strings.Repeat(" ", priorityWidth)
+	priorityStr := blank(priorityWidth)
 	if !continuation {
 		priorityStr = priorityStyle.Render(" " + e.Priority.String() + " ")
 	}
-	message := e.Message
+	message := displayMessage(e.Message)
+	fieldStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "243", Dark: "245"}).
+		Background(background)
+	fieldsStr := ""
+	fieldsBlank := ""
+	if len(extraColumns) > 0 {
+		fieldsStr = fieldStyle.Render(extraColumnsText(e, continuation))
+		fieldsBlank = blank(len(extraColumnsBlank()))
+	}
 
+	sep := blank(1)
+	prefix := tagStr + priorityStr + sep + fieldsStr + inlineTag
+	contPrefix := tagBlank + blank(priorityWidth) + sep + fieldsBlank
 	if showTimestamp {
 		timestampStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "252"})
-		timestampContent := strings.Repeat(" ", timestampColumnWidth)
+			Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "252"}).
+			Background(background)
+		timestampContent := strings.Repeat(" ", timestampWidth())
 		if !continuation {
-			timestampContent = fmt.Sprintf("%-*s", timestampColumnWidth, e.Timestamp)
+			timestampContent = fmt.Sprintf("%-*s", timestampWidth(), timestampText(e))
 		}
-		timestampStr := timestampStyle.Render(timestampContent)
-		sep := " "
-		prefix := timestampStr + sep + tagStr + sep + priorityStr + sep
-		contPrefix := timestampStyle.Render(strings.Repeat(" ", timestampColumnWidth)) +
-			sep +
-			strings.Repeat(" ", TagColumnWidth()) +
-			sep +
-			strings.Repeat(" ", priorityWidth) +
-			sep
-		renderOne := func(s string) string { return messageStyle.Render(s) }
-		return wrapWithPrefix(message, renderOne, prefix, contPrefix, maxWidth)
-	}
-
-	sep := " "
-	prefix := tagStr + sep + priorityStr + sep
-	contPrefix := strings.Repeat(" ", TagColumnWidth()) +
-		sep +
-		strings.Repeat(" ", priorityWidth) +
-		sep
+		prefix = timestampStyle.Render(timestampContent) + sep + prefix
+		contPrefix = timestampStyle.Render(strings.Repeat(" ", timestampWidth())) + sep + contPrefix
+	}
 	renderOne := func(s string) string { return messageStyle.Render(s) }
-	return wrapWithPrefix(message, renderOne, prefix, contPrefix, maxWidth)
+	if mask := matchMask(message, highlights); mask != nil {
+		renderOne = highlighter(message, mask, messageStyle)
+	}
+	return wrapWithPrefix(message, renderOne, prefix, contPrefix, messageWidth(e, showTag, showTimestamp, continuation, maxWidth))
+}
+
+// prefixWidth returns the width of the columns FormatEntryLines puts before
+// the message, without rendering them.
+func prefixWidth(e *logcat.Entry, showTag, showTimestamp, continuation bool) int {
+	width := len(e.Priority.String()) + 3
+	if !narrowLayout {
+		width += TagColumnWidth() + 1
+	} else if showTag && !continuation && e.Tag != "" {
+		width += ansi.StringWidth(e.Tag) + 2
+	}
+	if len(extraColumns) > 0 {
+		width += len(extraColumnsBlank())
+	}
+	if showTimestamp {
+		width += timestampWidth() + 1
+	}
+	return width
+}
+
+// messageWidth returns the width an entry's message is wrapped at for a full
+// line width of maxWidth, or 0 when wrapping is disabled.
+func messageWidth(e *logcat.Entry, showTag, showTimestamp, continuation bool, maxWidth int) int {
+	if maxWidth <= 0 {
+		return 0
+	}
+	return max(1, maxWidth-prefixWidth(e, showTag, showTimestamp, continuation))
+}
+
+// wrappedLineCount returns how many lines text takes wrapped at width, the
+// same count FormatEntryLines produces, without styling the lines.
+func wrappedLineCount(text string, width int) int {
+	if width <= 0 {
+		return 1
+	}
+	return strings.Count(wrap.String(text, width), "\n") + 1
+}
+
+// matchMask marks the bytes of message matched by any of the patterns, or
+// returns nil when nothing matches.
+func matchMask(message string, patterns []*regexp.Regexp) []bool {
+	var mask []bool
+	for _, pattern := range patterns {
+		for _, loc := range pattern.FindAllStringIndex(message, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			if mask == nil {
+				mask = make([]bool, len(message))
+			}
+			for i := loc[0]; i < loc[1]; i++ {
+				mask[i] = true
+			}
+		}
+	}
+	return mask
+}
+
+// highlighter returns a renderer for the wrapped lines of message, called in
+// order, that renders the bytes marked in mask with matchHighlightStyle and
+// the rest with style. Wrapping drops spaces at line breaks, so each line is
+// lined up with the message again as it is rendered.
+func highlighter(message string, mask []bool, style lipgloss.Style) func(string) string {
+	highlight := matchHighlightStyle.Inherit(style)
+	pos := 0
+	return func(line string) string {
+		var b strings.Builder
+		start, hit := 0, false
+		for i := 0; i < len(line); {
+			_, size := utf8.DecodeRuneInString(line[i:])
+			char := line[i : i+size]
+			for pos < len(message) && !strings.HasPrefix(message[pos:], char) {
+				_, skip := utf8.DecodeRuneInString(message[pos:])
+				pos += skip
+			}
+			marked := pos < len(message) && mask[pos]
+			if marked != hit {
+				b.WriteString(renderSpan(line[start:i], hit, highlight, style))
+				start, hit = i, marked
+			}
+			if pos < len(message) {
+				pos += size
+			}
+			i += size
+		}
+		b.WriteString(renderSpan(line[start:], hit, highlight, style))
+		return b.String()
+	}
+}
+
+func renderSpan(text string, hit bool, highlight, style lipgloss.Style) string {
+	if text == "" {
+		return ""
+	}
+	if hit {
+		return highlight.Render(text)
+	}
+	return style.Render(text)
 }
 
 func truncate(s string, maxLen int) string {
@@ -138,17 +451,15 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen]
 }
 
-func wrapWithPrefix(message string, render func(string) string, prefix, contPrefix string, maxWidth int) []string {
+// wrapWithPrefix wraps message at messageWidth, or not at all when it is <= 0,
+// and puts prefix before the first line and contPrefix before the rest.
+func wrapWithPrefix(message string, render func(string) string, prefix, contPrefix string, messageWidth int) []string {
 	if render == nil {
 		render = func(s string) string { return s }
 	}
-	if maxWidth <= 0 {
+	if messageWidth <= 0 {
 		return []string{prefix + render(message)}
 	}
-	messageWidth := maxWidth - lipgloss.Width(prefix)
-	if messageWidth < 1 {
-		messageWidth = 1
-	}
 	wrapped := wrap.String(message, messageWidth)
 	lines := strings.Split(wrapped, "\n")
 	if len(lines) == 0 {
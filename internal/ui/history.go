@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxHistory caps the audit trail; the oldest changes are dropped first.
+const maxHistory = 100
+
+// historyEntry is the view state after a level, filter or device change.
+type historyEntry struct {
+	at         time.Time
+	change     string
+	levels     levelSet
+	filters    []Filter
+	filtersOff bool
+	device     string
+}
+
+// filtersText describes the entry's filters the way they are typed.
+func (h historyEntry) filtersText() string {
+	if len(h.filters) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(h.filters))
+	for _, filter := range h.filters {
+		parts = append(parts, filter.String())
+	}
+	text := strings.Join(parts, ", ")
+	if h.filtersOff {
+		text += " (off)"
+	}
+	return text
+}
+
+// String formats the entry as one line of the history file in a report.
+func (h historyEntry) String() string {
+	line := fmt.Sprintf("%s  %s  [level: %s; filters: %s", h.at.Format("15:04:05"), h.change, h.levels.label(), h.filtersText())
+	if h.device != "" {
+		line += "; device: " + h.device
+	}
+	return line + "]"
+}
+
+// recordHistory appends the current levels, filters and device to the audit
+// trail, labeled with the change that led to them.
+func (m *Model) recordHistory(change string) {
+	m.history = append(m.history, historyEntry{
+		at:         time.Now(),
+		change:     change,
+		levels:     m.levels,
+		filters:    slices.Clone(m.filters),
+		filtersOff: m.filtersOff,
+		device:     m.selectedDevice,
+	})
+	if len(m.history) > maxHistory {
+		m.history = m.history[len(m.history)-maxHistory:]
+	}
+}
+
+// historyFilters describes the active filters for a change label.
+func (m *Model) historyFilters() string {
+	return historyEntry{filters: m.filters}.filtersText()
+}
+
+// historyLines returns the audit trail as text, oldest first.
+func (m *Model) historyLines() []string {
+	lines := make([]string, 0, len(m.history))
+	for _, h := range m.history {
+		lines = append(lines, h.String())
+	}
+	return lines
+}
+
+// restoreHistory re-applies the levels and filters of an earlier state.
+// The device is not switched back, since that restarts logcat.
+func (m *Model) restoreHistory(h historyEntry) {
+	m.levels = h.levels
+	*m.pendingLevels = h.levels
+	if len(m.filters) > 0 {
+		m.lastFilters = m.filters
+	}
+	m.filters = slices.Clone(h.filters)
+	m.filtersOff = h.filtersOff
+	m.syncFilterInput()
+	m.recordHistory("restored " + h.at.Format("15:04:05"))
+	m.statusMessage = "restored state from " + h.at.Format("15:04:05")
+	m.lineCache.clear()
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// historyView renders the history panel, newest change first.
+func (m *Model) historyView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle := itemStyle.Foreground(GetAccentColor()).Bold(true)
+	detailStyle := lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("245"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	// Two rows per change; scroll so the cursor stays inside the panel
+	rows := max(1, (m.height-8)/2)
+	pos := len(m.history) - 1 - m.historyIndex
+	first := max(0, pos-rows+1)
+
+	lines := []string{titleStyle.Render("History")}
+	for n := first; n < len(m.history) && n < first+rows; n++ {
+		i := len(m.history) - 1 - n
+		h := m.history[i]
+		cursor := " "
+		style := itemStyle
+		if i == m.historyIndex {
+			cursor = "›"
+			style = selectedStyle
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%s %s  %s", cursor, h.at.Format("15:04:05"), h.change)))
+		detail := fmt.Sprintf("level: %s | filters: %s", h.levels.label(), h.filtersText())
+		if h.device != "" {
+			detail += " | device: " + h.device
+		}
+		lines = append(lines, detailStyle.Render(truncate(detail, max(0, m.width-10))))
+	}
+	lines = append(lines, "", helpStyle.Render("enter: re-apply levels and filters | j/k: move | esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// handleHistoryKey handles keys while the history panel is open. The list is
+// shown newest first, so moving down steps back in time.
+func (m *Model) handleHistoryKey(key string) {
+	switch key {
+	case "esc", "H":
+		m.showHistory = false
+	case "j", "down":
+		if m.historyIndex > 0 {
+			m.historyIndex--
+		}
+	case "k", "up":
+		if m.historyIndex < len(m.history)-1 {
+			m.historyIndex++
+		}
+	case "enter":
+		m.showHistory = false
+		m.restoreHistory(m.history[m.historyIndex])
+	}
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+	"github.com/muesli/termenv"
+)
+
+// htmlExporter writes a standalone page with the log's level and tag colors
+// as inline CSS, so a styled session can be attached to a bug report and
+// viewed in a browser. It lives here rather than with the other formats
+// because the colors come from the active theme.
+type htmlExporter struct{}
+
+func (htmlExporter) Name() string      { return "html" }
+func (htmlExporter) Extension() string { return "html" }
+
+func (htmlExporter) Write(w io.Writer, entries []*logcat.Entry, notes map[uint64][]string) error {
+	background := "#ffffff"
+	if lipgloss.HasDarkBackground() {
+		background = "#1c1c1c"
+	}
+	dim := cssColor(colors.unknown)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>logdog export</title>\n</head>\n")
+	fmt.Fprintf(bw, "<body style=\"margin:0;background:%s;color:%s\">\n", background, cssColor(colors.text))
+	bw.WriteString("<pre style=\"margin:0;padding:1em;font-family:ui-monospace,Menlo,Consolas,monospace;font-size:13px;line-height:1.4\">\n")
+	for _, entry := range entries {
+		if entry.Source != "" {
+			bw.WriteString(htmlSpan("color:"+dim, "["+entry.Source+"]") + " ")
+		}
+		if entry.Timestamp != "" {
+			bw.WriteString(htmlSpan("color:"+dim, entry.Timestamp) + " ")
+		}
+		bw.WriteString(htmlSpan("font-weight:bold;color:"+cssColor(GetPriorityColor(entry.Priority)), entry.Priority.String()) + " ")
+		if tag := strings.TrimRight(entry.Tag, " "); tag != "" {
+			bw.WriteString(htmlSpan("color:"+cssColor(TagColor(tag)), tag) + " ")
+		}
+		bw.WriteString(htmlSpan(cssStyle(GetPriorityStyle(entry.Priority)), entry.Message))
+		bw.WriteByte('\n')
+		for _, note := range notes[entry.ID] {
+			bw.WriteString(htmlSpan("font-style:italic;color:"+dim, "    # "+note) + "\n")
+		}
+	}
+	bw.WriteString("</pre>\n</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// htmlSpan returns text, escaped, in a span with the inline style.
+func htmlSpan(style, text string) string {
+	if style == "" {
+		return html.EscapeString(text)
+	}
+	return `<span style="` + style + `">` + html.EscapeString(text) + "</span>"
+}
+
+// cssStyle converts a lipgloss style's colors and attributes to inline CSS.
+func cssStyle(style lipgloss.Style) string {
+	var rules []string
+	if color := cssColor(style.GetForeground()); color != "" {
+		rules = append(rules, "color:"+color)
+	}
+	if color := cssColor(style.GetBackground()); color != "" {
+		rules = append(rules, "background:"+color)
+	}
+	if style.GetBold() {
+		rules = append(rules, "font-weight:bold")
+	}
+	if style.GetItalic() {
+		rules = append(rules, "font-style:italic")
+	}
+	if style.GetUnderline() {
+		rules = append(rules, "text-decoration:underline")
+	}
+	if style.GetFaint() {
+		rules = append(rules, "opacity:0.6")
+	}
+	return strings.Join(rules, ";")
+}
+
+// cssColor returns a terminal color as a CSS hex color, picking the variant
+// of adaptive colors for the terminal's background. Colors are resolved at
+// full depth rather than degraded to the terminal's profile. It returns ""
+// for no color.
+func cssColor(color lipgloss.TerminalColor) string {
+	var value string
+	switch c := color.(type) {
+	case lipgloss.Color:
+		value = string(c)
+	case lipgloss.ANSIColor:
+		value = strconv.FormatUint(uint64(c), 10)
+	case lipgloss.AdaptiveColor:
+		value = c.Light
+		if lipgloss.HasDarkBackground() {
+			value = c.Dark
+		}
+	case lipgloss.CompleteColor:
+		value = c.TrueColor
+	case lipgloss.CompleteAdaptiveColor:
+		value = c.Light.TrueColor
+		if lipgloss.HasDarkBackground() {
+			value = c.Dark.TrueColor
+		}
+	}
+	resolved := termenv.TrueColor.Color(value)
+	if resolved == nil {
+		return ""
+	}
+	return termenv.ConvertToRGB(resolved).Hex()
+}
+
+func init() {
+	logcat.RegisterExporter(htmlExporter{})
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// importTag is the tag given to imported lines that don't carry their own.
+const importTag = "stacktrace"
+
+// importSampleLines is how many lines are sampled to detect the log format.
+const importSampleLines = 20
+
+// importSource is text shown instead of a device stream.
+type importSource struct {
+	name   string
+	text   string
+	format string
+	// entry is the permalink number of the entry to show, or 0
+	entry int
+}
+
+var imported *importSource
+
+// logFile is a saved logcat dump read instead of a device stream.
+var logFile string
+
+// SetImport makes NewModel show the given text, e.g. a stack trace copied from
+// Crashlytics or the Play Console, instead of streaming from a device. The
+// format names a registered log format; empty detects it from the text.
+func SetImport(name, text, format string) {
+	imported = &importSource{name: name, text: text, format: format}
+}
+
+// SetFile makes NewModel read a saved logcat file, e.g. from `adb logcat -d`,
+// through the same pipeline as a device stream. The format is detected from
+// the file's first lines.
+func SetFile(path string) {
+	logFile = path
+}
+
+// importFormat returns the named format, or the one detected from the first
+// non-blank lines. It reports false when neither gives a format.
+func importFormat(lines []string, name string) (logcat.Format, bool) {
+	if format, err := logcat.FormatNamed(name); err == nil {
+		return format, true
+	}
+	var sample []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			sample = append(sample, line)
+		}
+		if len(sample) == importSampleLines {
+			break
+		}
+	}
+	return logcat.DetectFormat(sample)
+}
+
+// importEntries turns pasted text into entries using the import's log format.
+// Lines the format doesn't recognize are taken as a stack trace: frames and
+// indented lines attach to the entry before them, and any other line, like an
+// exception header, starts a new error entry. It also returns the name of the
+// format used.
+func importEntries(text, formatName string) ([]*logcat.Entry, string) {
+	var entries []*logcat.Entry
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	format, known := importFormat(lines, formatName)
+	name := format.Name
+	if !known {
+		name = "stack trace"
+	}
+	parser := format.NewParser()
+	for _, line := range lines {
+		entry := parser.Parse(line)
+		if entry == nil || strings.TrimSpace(entry.Raw) == "" {
+			continue
+		}
+		if entry.Unparsed && (len(entries) == 0 || !isContinuationText(entry.Message)) {
+			entry.Priority = logcat.Error
+			entry.Tag = importTag
+			entry.Unparsed = false
+		}
+		entries = append(entries, entry)
+	}
+	return entries, name
+}
+
+// loadFile reads the log file instead of a device. Like an import, the file
+// has no device behind it, so device actions stay off.
+func (m *Model) loadFile() {
+	m.logManager = logcat.NewFileManager(logFile)
+	m.importName = filepath.Base(logFile)
+}
+
+// fileStartedMsg reports that the log file is open and the format its lines
+// are in.
+type fileStartedMsg struct{ format logcat.Format }
+
+// startFile opens the log file. Reading waits for fileStartedMsg, so no line
+// arrives before its parser is set.
+func startFile(manager *logcat.Manager) tea.Cmd {
+	return func() tea.Msg {
+		if err := manager.Start(); err != nil {
+			return errMsg{err}
+		}
+		return fileStartedMsg{manager.FileFormat()}
+	}
+}
+
+// readFile reads the opened log file in the file's format.
+func (m *Model) readFile(msg fileStartedMsg) tea.Cmd {
+	m.fileParser = msg.format.NewParser()
+	m.statusMessage = fmt.Sprintf("reading %s as %s", m.importName, msg.format.Name)
+	manager, lineChan := m.logManager, m.lineChan
+	return tea.Batch(func() tea.Msg {
+		go manager.ReadLines(lineChan)
+		return nil
+	}, waitForLogLine(lineChan))
+}
+
+// parseLine parses a streamed line, or returns nil for lines that carry no
+// entry.
+func (m *Model) parseLine(line string) *logcat.Entry {
+	if m.fileParser != nil {
+		return m.fileParser.Parse(line)
+	}
+	entry, _ := logcat.ParseLine(line)
+	return entry
+}
+
+// loadImport fills the log with the imported entries.
+func (m *Model) loadImport() {
+	m.importName = imported.name
+	entries, format := importEntries(imported.text, imported.format)
+	for _, entry := range entries {
+		m.appendEntry(entry)
+	}
+	m.autoScroll = false
+	m.statusMessage = fmt.Sprintf("imported %s as %s", imported.name, format)
+	if imported.entry > 0 {
+		if m.pendingJump = m.importEntryNamed(imported.entry); m.pendingJump == nil {
+			m.statusMessage = fmt.Sprintf("%s has no entry %d", imported.name, imported.entry)
+		}
+	}
+}
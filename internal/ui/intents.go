@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// maxIntents caps the intents panel; the oldest intents are dropped first.
+const maxIntents = 200
+
+// loggedIntent is an intent parsed from a log entry.
+type loggedIntent struct {
+	logcat.Intent
+	entry *logcat.Entry
+}
+
+// trackIntent records an intent logged in the entry. With an app filter,
+// only intents involving the app are kept, unless the app logged them itself.
+func (m *Model) trackIntent(entry *logcat.Entry) {
+	intent, ok := logcat.ParseIntent(entry)
+	if !ok {
+		return
+	}
+	if m.appID != "" && !intent.Involves(m.appID) && !strings.Contains(","+m.appPID+",", ","+entry.PID+",") {
+		return
+	}
+	m.intents = append(m.intents, loggedIntent{Intent: intent, entry: entry})
+	if len(m.intents) > maxIntents {
+		m.intents = m.intents[len(m.intents)-maxIntents:]
+	}
+}
+
+// intentDetail describes an intent's data, categories, flags and extras.
+func intentDetail(intent logcat.Intent) string {
+	var parts []string
+	add := func(label, value string) {
+		if value != "" {
+			parts = append(parts, label+": "+value)
+		}
+	}
+	add("data", intent.Data)
+	add("category", intent.Categories)
+	add("package", intent.Package)
+	add("flags", intent.Flags)
+	add("extras", intent.Extras)
+	if len(parts) == 0 {
+		return "no data or extras logged"
+	}
+	return strings.Join(parts, " | ")
+}
+
+// intentsView renders the intents panel, newest intent first.
+func (m *Model) intentsView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle := itemStyle.Foreground(GetAccentColor()).Bold(true)
+	detailStyle := lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("245"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	lines := []string{titleStyle.Render(fmt.Sprintf("Intents (%d)", len(m.intents)))}
+
+	// Two rows per intent; scroll so the cursor stays inside the panel
+	rows := max(1, (m.height-8)/2)
+	pos := len(m.intents) - 1 - m.intentsIndex
+	first := max(0, pos-rows+1)
+	for n := first; n < len(m.intents) && n < first+rows; n++ {
+		i := len(m.intents) - 1 - n
+		intent := m.intents[i]
+		cursor := " "
+		style := itemStyle
+		if i == m.intentsIndex {
+			cursor = "›"
+			style = selectedStyle
+		}
+		target := intent.Component
+		if target == "" {
+			target = intent.Package
+		}
+		line := fmt.Sprintf("%s %s  %-9s %s", cursor, timestampText(intent.entry), intent.Kind, intent.Action)
+		if target != "" {
+			line += " → " + target
+		}
+		lines = append(lines, style.Render(truncate(line, max(0, m.width-8))))
+		lines = append(lines, detailStyle.Render(truncate(intentDetail(intent.Intent), max(0, m.width-10))))
+	}
+	if len(m.intents) == 0 {
+		lines = append(lines, itemStyle.Render("no intents logged yet"))
+	}
+	lines = append(lines, "", helpStyle.Render("enter: show in log | j/k: move | esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// handleIntentsKey handles keys while the intents panel is open. The list is
+// shown newest first, so moving down steps back in time.
+func (m *Model) handleIntentsKey(key string) {
+	switch key {
+	case "esc", "I":
+		m.showIntents = false
+	case "j", "down":
+		if m.intentsIndex > 0 {
+			m.intentsIndex--
+		}
+	case "k", "up":
+		if m.intentsIndex < len(m.intents)-1 {
+			m.intentsIndex++
+		}
+	case "enter":
+		if len(m.intents) > 0 {
+			m.showIntents = false
+			m.jumpToEntry(m.intents[m.intentsIndex].entry)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+const (
+	// maxJobs caps the jobs timeline; the least recently updated jobs are dropped first.
+	maxJobs = 100
+	// maxJobTransitions caps the transitions kept per job, e.g. for periodic work.
+	maxJobTransitions = 8
+)
+
+// jobTransition is a job's state change and the entry that logged it.
+type jobTransition struct {
+	state logcat.JobState
+	entry *logcat.Entry
+}
+
+// jobTimeline is a background job and its transitions, oldest first.
+type jobTimeline struct {
+	id          string
+	name        string
+	transitions []jobTransition
+	// dropped counts transitions dropped past maxJobTransitions
+	dropped int
+}
+
+// trackJob records a WorkManager or JobScheduler transition. Jobs are kept
+// in order of their latest transition.
+func (m *Model) trackJob(entry *logcat.Entry) {
+	event, ok := logcat.ParseJobEvent(entry)
+	if !ok {
+		return
+	}
+	// JobScheduler lines come from system_server and name the job's package
+	if m.appID != "" && strings.Contains(event.Name, "/") && !strings.HasPrefix(event.Name, m.appID+"/") {
+		return
+	}
+
+	var job *jobTimeline
+	for i, j := range m.jobs {
+		if j.id == event.ID {
+			job = j
+			m.jobs = append(m.jobs[:i], m.jobs[i+1:]...)
+			break
+		}
+	}
+	if job == nil {
+		job = &jobTimeline{id: event.ID}
+	}
+	if event.Name != "" {
+		job.name = event.Name
+	}
+	job.transitions = append(job.transitions, jobTransition{state: event.State, entry: entry})
+	if len(job.transitions) > maxJobTransitions {
+		job.dropped += len(job.transitions) - maxJobTransitions
+		job.transitions = job.transitions[len(job.transitions)-maxJobTransitions:]
+	}
+	m.jobs = append(m.jobs, job)
+	if len(m.jobs) > maxJobs {
+		m.jobs = m.jobs[len(m.jobs)-maxJobs:]
+	}
+}
+
+// jobLabel returns a short name for the job: the worker's class without its
+// package, or the start of its ID when no name was logged.
+func jobLabel(job *jobTimeline) string {
+	name := job.name
+	if name == "" {
+		if len(job.id) > 8 {
+			return job.id[:8]
+		}
+		return job.id
+	}
+	// WorkManager tags are comma separated; the worker class comes first
+	name, _, _ = strings.Cut(name, ",")
+	if _, component, ok := strings.Cut(name, "/"); ok {
+		name = component
+	}
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// jobTimelineText renders the job's transitions as "started 12:00:05 → succeeded 12:00:07 (2s)".
+func jobTimelineText(job *jobTimeline) string {
+	var parts []string
+	if job.dropped > 0 {
+		parts = append(parts, "…")
+	}
+	var started time.Time
+	for _, t := range job.transitions {
+		text := t.state.String() + " " + jobTime(t.entry)
+		switch t.state {
+		case logcat.JobStarted:
+			started = t.entry.Time
+		case logcat.JobSucceeded, logcat.JobFailed, logcat.JobRetried, logcat.JobStopped:
+			if !started.IsZero() && !t.entry.Time.IsZero() {
+				text += fmt.Sprintf(" (%s)", t.entry.Time.Sub(started).Round(time.Millisecond))
+			}
+			started = time.Time{}
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, " → ")
+}
+
+// jobTime returns the entry's time of day for the timeline.
+func jobTime(entry *logcat.Entry) string {
+	if entry.Time.IsZero() {
+		return entry.Timestamp
+	}
+	return entry.Time.Format("15:04:05")
+}
+
+// jobsView renders the jobs timeline, most recently updated job first.
+func (m *Model) jobsView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle := itemStyle.Foreground(GetAccentColor()).Bold(true)
+	failedStyle := itemStyle.Foreground(GetErrorColor())
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	lines := []string{titleStyle.Render(fmt.Sprintf("Jobs (%d)", len(m.jobs)))}
+
+	labelWidth := 0
+	for _, job := range m.jobs {
+		labelWidth = max(labelWidth, len(jobLabel(job)))
+	}
+	labelWidth = min(labelWidth, 32)
+
+	rows := max(1, m.height-8)
+	pos := len(m.jobs) - 1 - m.jobsIndex
+	first := max(0, pos-rows+1)
+	for n := first; n < len(m.jobs) && n < first+rows; n++ {
+		i := len(m.jobs) - 1 - n
+		job := m.jobs[i]
+		cursor := " "
+		style := itemStyle
+		last := job.transitions[len(job.transitions)-1].state
+		if last == logcat.JobFailed {
+			style = failedStyle
+		}
+		if i == m.jobsIndex {
+			cursor = "›"
+			style = selectedStyle
+		}
+		line := fmt.Sprintf("%s %-*s  %s", cursor, labelWidth, truncate(jobLabel(job), labelWidth), jobTimelineText(job))
+		lines = append(lines, style.Render(truncate(line, max(0, m.width-8))))
+	}
+	if len(m.jobs) == 0 {
+		lines = append(lines, itemStyle.Render("no WorkManager or JobScheduler jobs logged yet"))
+	}
+	lines = append(lines, "", helpStyle.Render("WorkManager logs transitions at debug level only once its minimum logging level is lowered to DEBUG"))
+	lines = append(lines, helpStyle.Render("enter: show latest transition in log | j/k: move | esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// handleJobsKey handles keys while the jobs timeline is open. The list is
+// shown most recent first, so moving down steps back in time.
+func (m *Model) handleJobsKey(key string) {
+	switch key {
+	case "esc", "w":
+		m.showJobs = false
+	case "j", "down":
+		if m.jobsIndex > 0 {
+			m.jobsIndex--
+		}
+	case "k", "up":
+		if m.jobsIndex < len(m.jobs)-1 {
+			m.jobsIndex++
+		}
+	case "enter":
+		if len(m.jobs) > 0 {
+			transitions := m.jobs[m.jobsIndex].transitions
+			m.showJobs = false
+			m.jumpToEntry(transitions[len(transitions)-1].entry)
+		}
+	}
+}
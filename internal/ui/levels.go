@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// levelSet is the set of priorities shown in the log view, one bit per priority.
+// Entries outside Verbose..Assert are not governed by the set.
+type levelSet uint8
+
+const allLevels levelSet = 1<<(logcat.Assert+1) - 1
+
+// levelsFrom returns the set of all priorities at or above min.
+func levelsFrom(min logcat.Priority) levelSet {
+	return allLevels &^ (1<<min - 1)
+}
+
+func (s levelSet) has(p logcat.Priority) bool {
+	if p < logcat.Verbose || p > logcat.Assert {
+		return true
+	}
+	return s&(1<<p) != 0
+}
+
+func (s levelSet) toggle(p logcat.Priority) levelSet {
+	return s ^ 1<<p
+}
+
+// lowest returns the lowest priority in the set, or Assert if the set is empty.
+func (s levelSet) lowest() logcat.Priority {
+	for p := logcat.Verbose; p < logcat.Assert; p++ {
+		if s.has(p) {
+			return p
+		}
+	}
+	return logcat.Assert
+}
+
+// threshold reports whether the set is a plain minimum level, i.e. everything from lowest() up.
+func (s levelSet) threshold() bool {
+	return s == levelsFrom(s.lowest())
+}
+
+// step moves the minimum level up (delta > 0) or down, clamped to Verbose..Assert.
+// A non-threshold set steps from its lowest member and becomes a threshold.
+func (s levelSet) step(delta int) levelSet {
+	min := s.lowest() + logcat.Priority(delta)
+	if min < logcat.Verbose {
+		min = logcat.Verbose
+	}
+	if min > logcat.Assert {
+		min = logcat.Assert
+	}
+	return levelsFrom(min)
+}
+
+// label describes the set for the header: the level name for a threshold, otherwise the members.
+func (s levelSet) label() string {
+	if s.threshold() {
+		return strings.ToLower(s.lowest().Name())
+	}
+	var names []string
+	for p := logcat.Verbose; p <= logcat.Assert; p++ {
+		if s.has(p) {
+			names = append(names, strings.ToLower(p.Name()))
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// letters returns the members as logcat priority letters, for persisting.
+func (s levelSet) letters() []string {
+	var letters []string
+	for p := logcat.Verbose; p <= logcat.Assert; p++ {
+		if s.has(p) {
+			letters = append(letters, p.String())
+		}
+	}
+	return letters
+}
+
+// levelCounts counts the buffered entries at each priority, kept up to date as
+// entries arrive and leave so the level list can show them without recounting.
+type levelCounts [logcat.Unknown + 1]int
+
+func (c *levelCounts) add(entry *logcat.Entry, n int) {
+	if entry.Priority >= logcat.Verbose && entry.Priority <= logcat.Unknown {
+		c[entry.Priority] += n
+	}
+}
+
+func (c *levelCounts) total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// shown returns how many counted entries a level set lets through.
+func (c *levelCounts) shown(s levelSet) int {
+	shown := 0
+	for p, n := range c {
+		if s.has(logcat.Priority(p)) {
+			shown += n
+		}
+	}
+	return shown
+}
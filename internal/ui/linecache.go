@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+	"github.com/muesli/reflow/wrap"
+)
+
+var (
+	selectedLineStyle    = lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "251", Dark: "240"})
+	highlightedLineStyle = lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "254", Dark: "237"})
+)
+
+// lineEmphasis is the background an entry is rendered with.
+type lineEmphasis uint8
+
+const (
+	emphasisNone lineEmphasis = iota
+	emphasisSelected
+	emphasisHighlighted
+	// emphasisDimmed is an entry that doesn't match the filters in spotlight mode
+	emphasisDimmed
+	// emphasisMatch is an entry that matches the search
+	emphasisMatch
+)
+
+// lineKey identifies one rendering of an entry.
+type lineKey struct {
+	id           uint64
+	showTag      bool
+	continuation bool
+	emphasis     lineEmphasis
+	width        int
+}
+
+// lineStyleState is the style state formatted lines depend on besides their
+// key. The cache is dropped whenever it changes, e.g. on a settings toggle.
+type lineStyleState struct {
+	rawMode            bool
+	showTimestamp      bool
+	logLevelBackground bool
+	coloredMessages    bool
+	tagWidth           int
+	narrow             bool
+	timestampFormat    timestampFormat
+	shift              time.Duration
+	location           *time.Location
+	extraColumns       string
+	maxLineLength      int
+	highlights         string
+	tagColors          int
+}
+
+// lineCache memoizes formatted entry lines, so rebuilding the viewport after a
+// highlight move, sort or scroll doesn't run unchanged entries through lipgloss.
+type lineCache struct {
+	state lineStyleState
+	lines map[lineKey][]string
+}
+
+// sync drops the cache if the style state changed since it was filled.
+func (c *lineCache) sync(state lineStyleState) {
+	if c.lines == nil || c.state != state {
+		c.state = state
+		c.lines = make(map[lineKey][]string)
+	}
+}
+
+// clear drops all cached lines.
+func (c *lineCache) clear() {
+	c.lines = nil
+}
+
+func (m *Model) lineStyleState() lineStyleState {
+	return lineStyleState{
+		rawMode:            m.rawMode,
+		showTimestamp:      m.showTimestamp,
+		logLevelBackground: m.logLevelBackground,
+		coloredMessages:    m.coloredMessages,
+		tagWidth:           TagColumnWidth(),
+		narrow:             narrowLayout,
+		timestampFormat:    currentTimestampFormat,
+		shift:              timestampShift,
+		location:           displayLocation,
+		extraColumns:       strings.Join(extraColumns, ","),
+		maxLineLength:      maxLineLength,
+		highlights:         patternsKey(m.matchHighlights()),
+		tagColors:          tagColorGeneration,
+	}
+}
+
+// formatLines returns the formatted lines of an entry, without gutter or source
+// label, from the cache when possible. The returned slice is a copy, since the
+// gutter and source label are prepended in place.
+func (m *Model) formatLines(entry *logcat.Entry, showTag, continuation bool, maxWidth int) []string {
+	key := lineKey{id: entry.ID, showTag: showTag, continuation: continuation, width: maxWidth}
+	if m.selectedEntries[entry.ID] {
+		key.emphasis = emphasisSelected
+	} else if m.isHighlighted(entry) {
+		key.emphasis = emphasisHighlighted
+	} else if m.spotlight && !m.matchesFilters(entry) {
+		key.emphasis = emphasisDimmed
+	} else if m.searchMatches(entry) {
+		key.emphasis = emphasisMatch
+	}
+	if lines, ok := m.lineCache.lines[key]; ok {
+		return slices.Clone(lines)
+	}
+
+	var lines []string
+	if m.rawMode {
+		lines = m.rawLines(entry, key.emphasis, maxWidth)
+	} else {
+		lines = m.styledLines(entry, key.emphasis, showTag, continuation, maxWidth)
+	}
+	if key.emphasis == emphasisDimmed {
+		dimmed := lipgloss.NewStyle().Foreground(GetUnknownColor())
+		for i, line := range lines {
+			lines[i] = dimmed.Render(ansi.Strip(line))
+		}
+	}
+	if m.lineCache.lines != nil {
+		m.lineCache.lines[key] = slices.Clone(lines)
+	}
+	return lines
+}
+
+// rawLines renders the line exactly as received, with only the selection or
+// highlight background.
+func (m *Model) rawLines(entry *logcat.Entry, emphasis lineEmphasis, maxWidth int) []string {
+	raw := displayMessage(entry.Raw)
+	lines := []string{raw}
+	if maxWidth > 0 {
+		lines = strings.Split(wrap.String(raw, maxWidth), "\n")
+	}
+	var style lipgloss.Style
+	switch emphasis {
+	case emphasisSelected:
+		style = selectedLineStyle
+	case emphasisHighlighted:
+		style = highlightedLineStyle
+	case emphasisMatch:
+		style = searchMatchLineStyle
+	default:
+		return lines
+	}
+	for i, line := range lines {
+		lines[i] = style.Render(line)
+	}
+	return lines
+}
+
+func (m *Model) styledLines(entry *logcat.Entry, emphasis lineEmphasis, showTag, continuation bool, maxWidth int) []string {
+	lineStyle := lipgloss.NewStyle()
+	switch emphasis {
+	case emphasisSelected:
+		lineStyle = selectedLineStyle
+	case emphasisHighlighted:
+		lineStyle = highlightedLineStyle
+	case emphasisMatch:
+		lineStyle = searchMatchLineStyle
+	}
+	return FormatEntryLines(entry, lineStyle, showTag, m.showTimestamp, m.logLevelBackground, m.coloredMessages, continuation, maxWidth, m.matchHighlights())
+}
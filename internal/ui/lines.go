@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// The renderer lays out the visible entries without formatting them: it
+// records, for every line, the entry it belongs to (lineEntries) and, for every
+// entry, the span of lines it occupies along with how it is drawn
+// (entryLineRanges). Wrapped entries span several lines, so mouse handling and
+// scrolling must go through this mapping rather than assume one row per entry.
+// Entries are addressed by ID, so highlights, selection and bookmarks don't
+// depend on an entry keeping its identity across rebuilds.
+//
+// Only the lines inside the viewport window are formatted, on demand, so the
+// work per frame is bounded by the terminal height rather than the buffer size.
+// The viewport holds no content: it provides the window size and key bindings,
+// and its YOffset is clamped here against the number of laid out lines.
+
+// windowOverscan is how many lines above and below the window are formatted
+// ahead of time, so short scrolls hit the line cache.
+const windowOverscan = 20
+
+// contentLine maps a screen row to the index of the rendered line shown there.
+func (m *Model) contentLine(y int) (int, bool) {
+	y -= m.stickyRows()
+	if y < 0 || y >= m.viewport.Height {
+		return 0, false
+	}
+	line := y + m.viewport.YOffset
+	if line < 0 || line >= len(m.lineEntries) {
+		return 0, false
+	}
+	return line, true
+}
+
+// entryAtRow returns the entry rendered on the given screen row, or nil when
+// the row is outside the content or does not belong to an entry.
+func (m *Model) entryAtRow(y int) *logcat.Entry {
+	line, ok := m.contentLine(y)
+	if !ok {
+		return nil
+	}
+	return m.lineEntries[line]
+}
+
+// entryLineCount returns how many lines an entry takes. Unwrapped entries are
+// always one line; wrapped ones are counted from the message's wrap width
+// without formatting them.
+func (m *Model) entryLineCount(entry *logcat.Entry, showTag, continuation bool, maxWidth int) int {
+	if maxWidth <= 0 {
+		return 1
+	}
+	if m.rawMode {
+		return wrappedLineCount(displayMessage(entry.Raw), maxWidth)
+	}
+	return wrappedLineCount(displayMessage(entry.Message), messageWidth(entry, showTag, m.showTimestamp, continuation, maxWidth))
+}
+
+// entryLines returns the fully decorated lines of a laid out entry. Raw lines
+// are shown without gutter or source label.
+func (m *Model) entryLines(entry *logcat.Entry) []string {
+	r := m.entryLineRanges[entry.ID]
+	lines := m.formatLines(entry, r.showTag, r.continuation, m.contentWidth())
+	if m.rawMode {
+		return lines
+	}
+	return m.withGutter(entry, m.withSourceLabel(entry, lines))
+}
+
+// renderedLine returns the line at the given index of the laid out content.
+func (m *Model) renderedLine(line int) string {
+	if line < 0 || line >= len(m.lineEntries) {
+		return ""
+	}
+	entry := m.lineEntries[line]
+	lines := m.entryLines(entry)
+	if i := line - m.entryLineRanges[entry.ID].start; i >= 0 && i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// windowLines formats the laid out lines in [top, bottom).
+func (m *Model) windowLines(top, bottom int) []string {
+	top = max(0, top)
+	bottom = min(bottom, len(m.lineEntries))
+	if top >= bottom {
+		return nil
+	}
+	lines := make([]string, 0, bottom-top)
+	var entry *logcat.Entry
+	var entryLines []string
+	for line := top; line < bottom; line++ {
+		if m.lineEntries[line] != entry {
+			entry = m.lineEntries[line]
+			entryLines = m.entryLines(entry)
+		}
+		text := ""
+		if i := line - m.entryLineRanges[entry.ID].start; i >= 0 && i < len(entryLines) {
+			text = entryLines[i]
+		}
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+// prerenderWindow fills the line cache for the window and its overscan.
+func (m *Model) prerenderWindow() {
+	m.windowLines(m.viewport.YOffset-windowOverscan, m.viewport.YOffset+m.viewport.Height+windowOverscan)
+}
+
+// viewAnchor is an entry kept on the same screen row when the viewport is
+// rebuilt, so entries arriving or sorted in above it don't shift the view.
+type viewAnchor struct {
+	id uint64
+	// row is the entry's first line relative to the top of the viewport
+	row int
+}
+
+// captureAnchor returns the highlighted entry while it is on screen, or else
+// the entry at the top of the viewport.
+func (m *Model) captureAnchor() viewAnchor {
+	if len(m.lineEntries) == 0 {
+		return viewAnchor{}
+	}
+	top := m.viewport.YOffset
+	if m.highlightedEntry != nil {
+		if r, ok := m.entryLineRanges[m.highlightedEntry.ID]; ok && r.start >= top && r.start < top+m.viewport.Height {
+			return viewAnchor{id: m.highlightedEntry.ID, row: r.start - top}
+		}
+	}
+	if top < 0 || top >= len(m.lineEntries) {
+		return viewAnchor{}
+	}
+	entry := m.lineEntries[top]
+	return viewAnchor{id: entry.ID, row: m.entryLineRanges[entry.ID].start - top}
+}
+
+// restoreAnchor scrolls the anchor entry back to its row, if it is still laid out.
+func (m *Model) restoreAnchor(anchor viewAnchor) {
+	if anchor.id == 0 {
+		return
+	}
+	if r, ok := m.entryLineRanges[anchor.id]; ok {
+		m.setYOffset(r.start - anchor.row)
+	}
+}
+
+// maxYOffset returns the offset that shows the last laid out line at the bottom.
+func (m *Model) maxYOffset() int {
+	return max(0, len(m.lineEntries)-m.viewport.Height)
+}
+
+// setYOffset scrolls the log so line n is at the top, clamped to the layout.
+func (m *Model) setYOffset(n int) {
+	m.viewport.YOffset = max(0, min(n, m.maxYOffset()))
+}
+
+// gotoBottom scrolls the log to its last line.
+func (m *Model) gotoBottom() {
+	m.viewport.YOffset = m.maxYOffset()
+}
+
+// atBottom reports whether the last line is in view.
+func (m *Model) atBottom() bool {
+	return m.viewport.YOffset >= m.maxYOffset()
+}
+
+// scrollLog scrolls the log for the viewport's paging keys and the mouse
+// wheel, as the viewport would if it held the content.
+func (m *Model) scrollLog(msg tea.Msg) {
+	keys := m.viewport.KeyMap
+	height := m.viewport.Height
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.PageDown):
+			m.setYOffset(m.viewport.YOffset + height)
+		case key.Matches(msg, keys.PageUp):
+			m.setYOffset(m.viewport.YOffset - height)
+		case key.Matches(msg, keys.HalfPageDown):
+			m.setYOffset(m.viewport.YOffset + height/2)
+		case key.Matches(msg, keys.HalfPageUp):
+			m.setYOffset(m.viewport.YOffset - height/2)
+		case key.Matches(msg, keys.Down):
+			m.setYOffset(m.viewport.YOffset + 1)
+		case key.Matches(msg, keys.Up):
+			m.setYOffset(m.viewport.YOffset - 1)
+		}
+	case tea.MouseMsg:
+		if !m.viewport.MouseWheelEnabled || msg.Action != tea.MouseActionPress || msg.Shift {
+			return
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.setYOffset(m.viewport.YOffset - m.viewport.MouseWheelDelta)
+		case tea.MouseButtonWheelDown:
+			m.setYOffset(m.viewport.YOffset + m.viewport.MouseWheelDelta)
+		}
+	}
+}
+
+// logView renders the viewport window, padded and cut to the viewport size
+// like the viewport's own View.
+func (m *Model) logView() string {
+	width, height := m.viewport.Width, m.viewport.Height
+	lines := m.windowLines(m.viewport.YOffset, m.viewport.YOffset+height)
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "")
+	}
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		MaxHeight(height).
+		MaxWidth(width).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxMacroKeys caps a recorded macro, in case recording is left running.
+const maxMacroKeys = 100
+
+// recordMacroKey appends a key press to the macro being recorded.
+func (m *Model) recordMacroKey(msg tea.KeyMsg) {
+	if m.recordingMacro && !m.replayingMacro && len(m.macro) < maxMacroKeys {
+		m.macro = append(m.macro, msg)
+	}
+}
+
+// toggleMacroRecording starts recording a new macro, or stops and keeps the
+// keys recorded so far. The key that stopped recording is not part of it.
+func (m *Model) toggleMacroRecording() {
+	if !m.recordingMacro {
+		m.recordingMacro = true
+		m.macro = nil
+		m.statusMessage = "recording macro (Q: stop)"
+		return
+	}
+	m.recordingMacro = false
+	m.macro = m.macro[:max(0, len(m.macro)-1)]
+	m.statusMessage = fmt.Sprintf("recorded macro of %d keys (@: replay)", len(m.macro))
+}
+
+// replayMacro feeds the recorded keys through Update as if they were typed,
+// so they act on whichever view they open along the way.
+func (m Model) replayMacro() (tea.Model, tea.Cmd) {
+	if m.recordingMacro {
+		// Drop the @ itself; replaying into the recording would repeat forever
+		m.macro = m.macro[:max(0, len(m.macro)-1)]
+		m.statusMessage = "can't replay while recording"
+		return m, nil
+	}
+	if len(m.macro) == 0 {
+		m.statusMessage = "no macro recorded (Q: record)"
+		return m, nil
+	}
+	if m.replayingMacro {
+		return m, nil
+	}
+
+	m.replayingMacro = true
+	var model tea.Model = m
+	var cmds []tea.Cmd
+	for _, key := range m.macro {
+		var cmd tea.Cmd
+		model, cmd = model.Update(key)
+		cmds = append(cmds, cmd)
+	}
+	result := model.(Model)
+	result.replayingMacro = false
+	if result.statusMessage == "" {
+		result.statusMessage = fmt.Sprintf("replayed %d keys", len(m.macro))
+	}
+	return result, tea.Batch(cmds...)
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// defaultMemoryLimitMB caps the log buffer when the config sets no memoryLimitMB.
+const defaultMemoryLimitMB = 512
+
+// memoryEvictFraction is how far below the ceiling eviction goes, so a busy
+// stream doesn't evict and rebuild the view on every batch.
+const memoryEvictFraction = 0.9
+
+// memoryLimit returns the buffer ceiling in bytes, or 0 when the config turns
+// it off with a negative memoryLimitMB.
+func (m *Model) memoryLimit() int {
+	switch {
+	case m.memoryLimitMB < 0:
+		return 0
+	case m.memoryLimitMB == 0:
+		return defaultMemoryLimitMB << 20
+	}
+	return m.memoryLimitMB << 20
+}
+
+// enforceMemoryLimit drops the oldest entries once the buffer holds more than
+// the ceiling, down to a margin below it. Entries held back while frozen
+// count towards the buffer and are dropped once the log before them is gone,
+// so a freeze left on doesn't grow without bound.
+func (m *Model) enforceMemoryLimit() {
+	limit := m.memoryLimit()
+	if limit == 0 || m.bufferBytes <= limit {
+		return
+	}
+
+	target := int(float64(limit) * memoryEvictFraction)
+	cut := m.evictOldest(m.parsedEntries, target)
+	heldCut := m.evictOldest(m.heldEntries, target)
+	if cut+heldCut == 0 {
+		return
+	}
+	// Copy the rest so the dropped entries' backing array can be freed
+	m.parsedEntries = append(make([]*logcat.Entry, 0, max(len(m.parsedEntries)-cut, cap(m.parsedEntries)/2)), m.parsedEntries[cut:]...)
+	m.heldEntries = append([]*logcat.Entry(nil), m.heldEntries[heldCut:]...)
+	m.evictedEntries += cut + heldCut
+	m.resetFilterCounts()
+	m.lineCache.clear()
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// evictOldest forgets entries from the front of entries until the buffer is
+// down to target, and returns how many it forgot.
+func (m *Model) evictOldest(entries []*logcat.Entry, target int) int {
+	cut := 0
+	for cut < len(entries) && m.bufferBytes > target {
+		entry := entries[cut]
+		m.bufferBytes -= entry.Size()
+		m.levelCounts.add(entry, -1)
+		m.forgetEntry(entry)
+		cut++
+	}
+	return cut
+}
+
+// forgetEntry drops the state kept for an entry leaving the buffer.
+func (m *Model) forgetEntry(entry *logcat.Entry) {
+	delete(m.annotations, entry.ID)
+	delete(m.flags, entry.ID)
+	delete(m.selectedEntries, entry.ID)
+	if m.selectionAnchor != nil && m.selectionAnchor.ID == entry.ID {
+		m.selectionAnchor = nil
+	}
+	if m.highlightedEntry != nil && m.highlightedEntry.ID == entry.ID {
+		m.highlightedEntry = nil
+	}
+	if m.contextEntry != nil && m.contextEntry.ID == entry.ID {
+		m.contextEntry = nil
+	}
+	if m.pausedOn != nil && m.pausedOn.ID == entry.ID {
+		m.pausedOn = nil
+	}
+}
+
+// memoryInfo describes the buffer's size against its ceiling for the header,
+// warning once entries have been dropped.
+func (m *Model) memoryInfo() string {
+	info := "buffer: " + formatMegabytes(m.bufferBytes)
+	if limit := m.memoryLimit(); limit > 0 {
+		info += " of " + formatMegabytes(limit)
+	}
+	if m.evictedEntries > 0 {
+		info += " " + lipgloss.NewStyle().Foreground(GetWarnColor()).Render(fmt.Sprintf("(%d oldest dropped)", m.evictedEntries))
+	}
+	return info
+}
+
+// formatMegabytes formats a byte count in whole megabytes, or with one decimal below 10 MB.
+func formatMegabytes(bytes int) string {
+	mb := float64(bytes) / (1 << 20)
+	if mb < 10 {
+		return fmt.Sprintf("%.1f MB", mb)
+	}
+	return fmt.Sprintf("%.0f MB", mb)
+}
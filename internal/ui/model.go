@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
@@ -14,6 +15,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mikaelreiersolmoen/logdog/internal/adb"
 	"github.com/mikaelreiersolmoen/logdog/internal/config"
+	"github.com/mikaelreiersolmoen/logdog/internal/gist"
 	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
 )
 
@@ -21,7 +23,10 @@ type logLevelItem logcat.Priority
 
 func (i logLevelItem) FilterValue() string { return "" }
 
-type logLevelDelegate struct{}
+type logLevelDelegate struct {
+	pending *levelSet
+	counts  *levelCounts
+}
 
 func (d logLevelDelegate) Height() int                             { return 1 }
 func (d logLevelDelegate) Spacing() int                            { return 0 }
@@ -49,31 +54,21 @@ func (d logLevelDelegate) Render(w io.Writer, m list.Model, index int, listItem
 		shortcut = "e"
 	case logcat.Fatal:
 		shortcut = "f"
+	case logcat.Assert:
+		shortcut = "a"
 	}
 
-	str := fmt.Sprintf("(%s) %s", shortcut, priority.Name())
-
-	// Get subtle message color for this priority
-	var subtleColor lipgloss.TerminalColor
-	switch priority {
-	case logcat.Verbose:
-		subtleColor = GetVerboseColor()
-	case logcat.Debug:
-		subtleColor = GetDebugColor()
-	case logcat.Info:
-		subtleColor = GetInfoColor()
-	case logcat.Warn:
-		subtleColor = GetWarnColor()
-	case logcat.Error:
-		subtleColor = GetErrorColor()
-	case logcat.Fatal:
-		subtleColor = GetFatalColor()
-	default:
-		subtleColor = GetVerboseColor()
+	check := "[ ]"
+	if d.pending != nil && d.pending.has(priority) {
+		check = "[x]"
+	}
+	str := fmt.Sprintf("%s (%s) %-8s", check, shortcut, priority.Name())
+	if d.counts != nil {
+		str += fmt.Sprintf(" %8d %6s", d.counts[priority], percentOf(d.counts[priority], d.counts.total()))
 	}
 
 	itemStyle := lipgloss.NewStyle().PaddingLeft(4)
-	selectedItemStyle := lipgloss.NewStyle().PaddingLeft(2).Foreground(subtleColor)
+	selectedItemStyle := lipgloss.NewStyle().PaddingLeft(2).Foreground(GetPriorityColor(priority))
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -89,7 +84,9 @@ type deviceItem adb.Device
 
 func (i deviceItem) FilterValue() string { return "" }
 
-type deviceDelegate struct{}
+type deviceDelegate struct {
+	checked map[string]bool
+}
 
 func (d deviceDelegate) Height() int                             { return 1 }
 func (d deviceDelegate) Spacing() int                            { return 0 }
@@ -102,11 +99,29 @@ func (d deviceDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 
 	device := adb.Device(i)
 	str := fmt.Sprintf("%s - %s", device.Serial, device.Model)
+	if device.AVDName != "" {
+		str = fmt.Sprintf("%s (%s) - %s", device.AVDName, device.Serial, device.Model)
+	}
+	online := device.Status == "device"
+	if !online {
+		str += " " + deviceStatusBadge(device.Status)
+	}
+	if len(d.checked) > 0 {
+		checkbox := "[ ]"
+		if d.checked[device.Serial] {
+			checkbox = "[x]"
+		}
+		str = checkbox + " " + str
+	}
 
 	itemStyle := lipgloss.NewStyle().PaddingLeft(4)
 	selectedItemStyle := lipgloss.NewStyle().
 		PaddingLeft(2).
 		Foreground(GetAccentColor())
+	if !online {
+		itemStyle = itemStyle.Foreground(lipgloss.Color("245"))
+		selectedItemStyle = selectedItemStyle.Foreground(lipgloss.Color("245"))
+	}
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -118,6 +133,51 @@ func (d deviceDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 	fmt.Fprint(w, fn(str))
 }
 
+// deviceMatch narrows the devices offered at startup, set from the command line.
+var deviceMatch adb.DeviceMatch
+
+// SetDeviceMatch preselects devices so the selector is skipped when exactly one matches.
+func SetDeviceMatch(match adb.DeviceMatch) {
+	deviceMatch = match
+}
+
+// deviceStatusBadge explains a non-online adb device state.
+func deviceStatusBadge(status string) string {
+	switch status {
+	case "unauthorized":
+		return "[unauthorized — accept the prompt on the device]"
+	case "offline":
+		return "[offline]"
+	case "authorizing", "connecting":
+		return "[" + status + "...]"
+	default:
+		return "[" + status + "]"
+	}
+}
+
+// newDeviceList builds the device selector list.
+func newDeviceList(devices []adb.Device, checked map[string]bool) list.Model {
+	deviceList := list.New(deviceItems(devices), deviceDelegate{checked: checked}, 80, len(devices)+4)
+	deviceList.Title = "Select device (space: add as source)"
+	deviceList.SetShowStatusBar(false)
+	deviceList.SetFilteringEnabled(false)
+	deviceList.SetShowPagination(false)
+	deviceList.Styles.Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor()).
+		Padding(0, 1)
+	return deviceList
+}
+
+// deviceItems converts devices into selector list items.
+func deviceItems(devices []adb.Device) []list.Item {
+	items := make([]list.Item, len(devices))
+	for i, device := range devices {
+		items[i] = deviceItem(device)
+	}
+	return items
+}
+
 type Model struct {
 	viewport           viewport.Model
 	logManager         *logcat.Manager
@@ -126,27 +186,64 @@ type Model struct {
 	width              int
 	height             int
 	appID              string
-	appStatus          string
-	deviceStatus       string
+	appStatus          logcat.State
+	appStatusErr       error
+	deviceStatus       logcat.State
+	appStatusSince     time.Time
+	appPID             string
+	appMainPID         string
+	appRestarts        int
+	appRestartedAt     time.Time
+	deviceStatusSince  time.Time
 	terminating        bool
 	showLogLevel       bool
 	logLevelList       list.Model
-	minLogLevel        logcat.Priority
+	levels             levelSet
+	hideUnparsed       bool
+	rawMode            bool
+	exportFormat       string
+	showExportPicker   bool
+	exportPickerIndex  int
+	bookmarkFatal      bool
+	bookmarkErrors     bool
+	filtersOff         bool
+	contextEntry       *logcat.Entry
+	pauseOnError       bool
+	freezeOnError      bool
+	errorPaused        bool
+	pausedOn           *logcat.Entry
+	frozen             bool
+	heldEntries        []*logcat.Entry
+	contextLines       int
+	lastFilters        []Filter
+	stickyHeader       bool
+	narrowWidth        int
+	pendingLevels      *levelSet
+	levelCounts        *levelCounts
 	showFilter         bool
 	filterInput        textinput.Model
 	filters            []Filter
 	parsedEntries      []*logcat.Entry
+	triggers           []*trigger
+	captures           []*capture
+	bufferBytes        int
+	evictedEntries     int
+	memoryLimitMB      int
+	nextEntryID        uint64
+	errorsSeen         int
+	sessionStart       time.Time
+	exportPaths        []string
 	needsUpdate        bool
 	highlightedEntry   *logcat.Entry
 	selectionMode      bool
-	selectedEntries    map[*logcat.Entry]bool
+	selectedEntries    map[uint64]bool
 	selectionAnchor    *logcat.Entry
 	lineEntries        []*logcat.Entry
-	entryLineRanges    map[*logcat.Entry]entryLineRange
-	renderedLines      []string
+	entryLineRanges    map[uint64]entryLineRange
+	renderAnchor       viewAnchor
+	lineCache          lineCache
 	renderedUpTo       int
 	renderReset        bool
-	viewportContent    string
 	lastRenderedTag    string
 	lastRenderedTime   string
 	lastRenderedCont   bool
@@ -156,6 +253,8 @@ type Model struct {
 	lastRenderedPrev   *logcat.Entry
 	lastRenderedLast   *logcat.Entry
 	renderScheduled    bool
+	streamActive       bool
+	lastLinesAt        time.Time
 	wrapLines          bool
 	autoScroll         bool
 	showDeviceSelect   bool
@@ -169,7 +268,107 @@ type Model struct {
 	showSettings       bool
 	settingsIndex      int
 	showClearConfirm   bool
+	showSavePrompt     bool
+	saveAll            bool
+	saveRaw            bool
 	clearInput         textinput.Model
+	forwarder          *logcat.Forwarder
+	extractors         []*logcat.Extractor
+	sortMode           sortMode
+	reorderer          *logcat.Reorderer
+	reorderScheduled   bool
+	clockSkew          time.Duration
+	activity           string
+	monkeyEvents       int
+	monkeySeed         int64
+	autosaveOnSignal   bool
+	lowPower           bool
+	renderInterval     time.Duration
+	syncOutput         *bool
+	readInterval       time.Duration
+	monkeyRunning      bool
+	showTests          bool
+	testsIndex         int
+	tests              *testRun
+	exec               execRun
+	execOutput         []string
+	clockSkewKnown     bool
+	hostTime           bool
+	zoneTime           bool
+	timeZone           *time.Location
+	redact             bool
+	redactor           *logcat.Redactor
+	gistToken          string
+	searchURLTemplate  string
+	statusMessage      string
+	showAnnotate       bool
+	showWatchInput     bool
+	watchInput         textinput.Model
+	showUntilInput     bool
+	untilInput         textinput.Model
+	showSearchInput    bool
+	searchInput        textinput.Model
+	searchText         string
+	search             *regexp.Regexp
+	searchOrigin       *logcat.Entry
+	searchFollowed     bool
+	watches            []*watch
+	annotateInput      textinput.Model
+	annotations        map[uint64]string
+	flags              map[uint64]entryFlags
+	columnDrag         *columnDrag
+	lastClickX         int
+	lastClickY         int
+	lastClickAt        time.Time
+	quickToken         string
+	tailSize           int
+	tailSizeChanged    bool
+	sources            []*source
+	showSources        bool
+	sourcesIndex       int
+	showDetail         bool
+	history            []historyEntry
+	showHistory        bool
+	historyIndex       int
+	macro              []tea.KeyMsg
+	recordingMacro     bool
+	replayingMacro     bool
+	importName         string
+	pendingJump        *logcat.Entry
+	sessionPath        string
+	detailEntry        *logcat.Entry
+	detailFields       []detailField
+	detailFieldIndex   int
+	detailViewport     viewport.Model
+	deviceAliases      map[string]string
+	tagBudgets         map[string]int
+	tagRates           map[string]*tagRate
+	budgetWarned       map[string]bool
+	budgetAlert        string
+	showSnapshot       bool
+	spotlight          bool
+	showPower          bool
+	power              powerState
+	showIntents        bool
+	intentsIndex       int
+	intents            []loggedIntent
+	showJobs           bool
+	showStats          bool
+	statsReport        string
+	jobsIndex          int
+	jobs               []*jobTimeline
+	showParseErrors    bool
+	parseErrorsIndex   int
+	parseFailures      int
+	parseSamples       []*logcat.Entry
+	snapshot           viewSnapshot
+	checkedDevices     map[string]bool
+
+	// statusClockScheduled is set while a status clock tick is pending
+	statusClockScheduled bool
+	// fileParser parses the lines of a log file in its detected format; nil
+	// parses device lines as threadtime
+	fileParser logcat.Parser
 }
 
 type errMsg struct{ err error }
@@ -178,20 +377,56 @@ func (e errMsg) Error() string { return e.err.Error() }
 
 type Filter struct {
 	isTag   bool
+	field   string
+	flag    string
+	exclude bool
 	pattern string
 	regex   *regexp.Regexp
+	// disabled filters are kept in the header but not applied
+	disabled bool
+	// matches counts the entries the filter matches, once counted is set
+	matches int
+	counted bool
+}
+
+// String returns the filter in the syntax accepted by the filter input.
+func (f Filter) String() string {
+	if f.flag != "" {
+		if f.exclude {
+			return "-flag:" + f.flag
+		}
+		return "flag:" + f.flag
+	}
+	return formatFilterPreference(config.FilterPreference{IsTag: f.isTag, Field: f.field, Exclude: f.exclude, Pattern: f.pattern})
 }
 
 type logLineMsg struct {
-	lines []string
+	lines  []string
+	source string
 }
 type updateViewportMsg struct{}
-type appStatusMsg string
-type deviceStatusMsg string
+type reorderFlushMsg struct{}
+type devicesMsg struct {
+	devices []adb.Device
+	err     error
+}
+type gistMsg struct {
+	url string
+	err error
+}
+type clockSkewMsg struct {
+	skew time.Duration
+	err  error
+}
+type appStatusMsg logcat.StatusUpdate
+type deviceStatusMsg logcat.StatusUpdate
+type statusClockMsg struct{}
 
 type entryLineRange struct {
-	start int
-	end   int
+	start        int
+	end          int
+	showTag      bool
+	continuation bool
 }
 
 const (
@@ -199,6 +434,16 @@ const (
 	settingWrapLines
 	settingLogLevelBackground
 	settingColoredMessages
+	settingHostTime
+	settingZoneTime
+	settingRedact
+	settingShowUnparsed
+	settingStickyHeader
+	settingPauseOnError
+	settingRawMode
+	settingBookmarkFatal
+	settingBookmarkErrors
+	settingLowPower
 	settingCount
 )
 
@@ -215,20 +460,24 @@ func NewModel(appID string, tailSize int) Model {
 		logLevelItem(logcat.Warn),
 		logLevelItem(logcat.Error),
 		logLevelItem(logcat.Fatal),
+		logLevelItem(logcat.Assert),
 	}
 
-	logLevelList := list.New(items, logLevelDelegate{}, 30, len(items)+4)
-	logLevelList.Title = "Select log level (v/d/i/w/e/f)"
+	pendingLevels := new(levelSet)
+	*pendingLevels = allLevels
+	counts := new(levelCounts)
+	logLevelList := list.New(items, logLevelDelegate{pending: pendingLevels, counts: counts}, 80, len(items)+4)
+	logLevelList.Title = "Log levels (space or v/d/i/w/e/f/a: toggle, V/D/I/W/E/F/A: level and above)"
 	logLevelList.SetShowStatusBar(false)
 	logLevelList.SetFilteringEnabled(false)
 	logLevelList.SetShowPagination(false)
 	logLevelList.Styles.Title = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(accentColor).
+		Foreground(GetAccentColor()).
 		Padding(0, 1)
 
 	filterInput := textinput.New()
-	filterInput.Placeholder = "e.g., tag:MyTag, some message"
+	filterInput.Placeholder = "e.g., tag:MyTag, some message, !tag:Choreographer"
 	filterInput.CharLimit = 500
 	filterInput.Width = 80
 
@@ -237,78 +486,49 @@ func NewModel(appID string, tailSize int) Model {
 	clearInput.CharLimit = 10
 	clearInput.Width = 40
 
+	annotateInput := textinput.New()
+	annotateInput.Placeholder = "note for the highlighted entry (empty removes it)"
+	annotateInput.CharLimit = 500
+	annotateInput.Width = 80
+
 	entryCapacity := 10000
 	if tailSize > 0 {
 		entryCapacity = tailSize
 	}
 
-	// Check for multiple devices
-	devices, deviceErr := adb.GetDevices()
+	checkedDevices := make(map[string]bool)
+
+	// Check for multiple devices; an import or log file needs none
+	var devices []adb.Device
+	var deviceErr error
+	if imported == nil && logFile == "" {
+		devices, deviceErr = adb.GetDevices()
+		if deviceErr == nil {
+			devices, deviceErr = deviceMatch.Filter(devices)
+		}
+	}
 	showDeviceSelect := false
 	var deviceList list.Model
 
 	if deviceErr == nil && len(devices) > 1 {
 		// Multiple devices - show device selector
 		showDeviceSelect = true
-		deviceItems := make([]list.Item, len(devices))
-		for i, device := range devices {
-			deviceItems[i] = deviceItem(device)
-		}
-		deviceList = list.New(deviceItems, deviceDelegate{}, 50, len(devices)+4)
-		deviceList.Title = "Select device"
-		deviceList.SetShowStatusBar(false)
-		deviceList.SetFilteringEnabled(false)
-		deviceList.SetShowPagination(false)
-		deviceList.Styles.Title = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(GetAccentColor()).
-			Padding(0, 1)
-	} else if deviceErr == nil && len(devices) == 1 {
-		// Single device - use it automatically
-		logManager := logcat.NewManager(appID, tailSize)
-		logManager.SetDevice(devices[0].Serial)
-		model := Model{
-			appID:              appID,
-			logManager:         logManager,
-			lineChan:           make(chan string, 100),
-			showLogLevel:       false,
-			logLevelList:       logLevelList,
-			minLogLevel:        logcat.Verbose,
-			showFilter:         false,
-			filterInput:        filterInput,
-			filters:            []Filter{},
-			parsedEntries:      make([]*logcat.Entry, 0, entryCapacity),
-			needsUpdate:        false,
-			highlightedEntry:   nil,
-			selectionMode:      false,
-			selectedEntries:    make(map[*logcat.Entry]bool),
-			selectionAnchor:    nil,
-			autoScroll:         true,
-			showDeviceSelect:   false,
-			deviceList:         list.Model{},
-			devices:            devices,
-			selectedDevice:     devices[0].Model,
-			deviceStatus:       "connected",
-			showClearConfirm:   false,
-			clearInput:         clearInput,
-			showTimestamp:      false,
-			logLevelBackground: false,
-			coloredMessages:    true,
-			wrapLines:          false,
-		}
-		if prefsLoaded {
-			model.applyPreferences(prefs)
-		}
-		return model
+		deviceList = newDeviceList(devices, checkedDevices)
 	}
 
 	model := Model{
 		appID:              appID,
+		sessionStart:       time.Now(),
 		logManager:         logcat.NewManager(appID, tailSize),
 		lineChan:           make(chan string, 100),
 		showLogLevel:       false,
 		logLevelList:       logLevelList,
-		minLogLevel:        logcat.Verbose,
+		levels:             allLevels,
+		narrowWidth:        DefaultNarrowWidth,
+		stickyHeader:       true,
+		contextLines:       defaultContextLines,
+		pendingLevels:      pendingLevels,
+		levelCounts:        counts,
 		showFilter:         false,
 		filterInput:        filterInput,
 		filters:            []Filter{},
@@ -316,7 +536,7 @@ func NewModel(appID string, tailSize int) Model {
 		needsUpdate:        false,
 		highlightedEntry:   nil,
 		selectionMode:      false,
-		selectedEntries:    make(map[*logcat.Entry]bool),
+		selectedEntries:    make(map[uint64]bool),
 		selectionAnchor:    nil,
 		autoScroll:         true,
 		showDeviceSelect:   showDeviceSelect,
@@ -325,46 +545,138 @@ func NewModel(appID string, tailSize int) Model {
 		selectedDevice:     "",
 		showClearConfirm:   false,
 		clearInput:         clearInput,
+		checkedDevices:     checkedDevices,
+		tailSize:           tailSize,
+		annotateInput:      annotateInput,
+		annotations:        make(map[uint64]string),
+		flags:              make(map[uint64]entryFlags),
+		tagRates:           make(map[string]*tagRate),
+		budgetWarned:       make(map[string]bool),
 		showTimestamp:      false,
 		logLevelBackground: false,
 		coloredMessages:    true,
 		wrapLines:          false,
 	}
+	if deviceErr == nil && len(devices) == 1 {
+		// Single device - use it automatically
+		model.logManager.SetDevice(devices[0].Serial)
+		model.selectedDevice = devices[0].DisplayName()
+		model.deviceStatus = logcat.StateConnected
+	}
 
+	if logFile != "" {
+		model.loadFile()
+	}
 	if prefsLoaded {
 		model.applyPreferences(prefs)
 	}
+	if imported != nil {
+		model.loadImport()
+	}
+	// Init starts the command
+	model.exec.running = execCommand != "" && model.importName == ""
+	model.recordHistory("session start")
 
 	return model
 }
 
 func (m *Model) applyPreferences(prefs config.Preferences) {
 	if priority, ok := priorityFromConfig(prefs.MinLogLevel); ok {
-		m.minLogLevel = priority
-		if priority >= logcat.Verbose && priority <= logcat.Fatal {
+		m.levels = levelsFrom(priority)
+		if priority >= logcat.Verbose && priority <= logcat.Assert {
 			m.logLevelList.Select(int(priority))
 		}
 	}
+	if len(prefs.Levels) > 0 {
+		var levels levelSet
+		for _, value := range prefs.Levels {
+			if priority, ok := priorityFromConfig(value); ok {
+				levels |= levelsFrom(priority) &^ levelsFrom(priority+1)
+			}
+		}
+		m.levels = levels
+	}
 
 	m.showTimestamp = prefs.ShowTimestamp
+	m.hostTime = prefs.HostTime
+	m.zoneTime = prefs.ZoneTime
+	if prefs.TimeZone != "" {
+		if loc, err := time.LoadLocation(prefs.TimeZone); err == nil {
+			m.timeZone = loc
+		}
+	}
+	m.applyTimeZone()
+	m.redact = prefs.Redact
+	m.hideUnparsed = prefs.HideUnparsed
+	m.pauseOnError = prefs.PauseOnError
+	m.exportFormat = prefs.ExportFormat
+	if m.exportFormat == "" && prefs.ThreadtimeExport {
+		m.exportFormat = "logcat"
+	}
+	m.bookmarkFatal = prefs.BookmarkFatal
+	m.bookmarkErrors = prefs.BookmarkErrors
+	m.lowPower = prefs.LowPower
+	m.renderInterval = time.Duration(prefs.RenderIntervalMs) * time.Millisecond
+	m.syncOutput = prefs.SynchronizedOutput
+	m.readInterval = time.Duration(prefs.ReadIntervalMs) * time.Millisecond
+	m.applyReadInterval()
+	m.freezeOnError = prefs.FreezeOnError
+	if prefs.ContextLines > 0 {
+		m.contextLines = prefs.ContextLines
+	}
+	if prefs.NarrowWidth > 0 {
+		m.narrowWidth = prefs.NarrowWidth
+	}
+	m.setRedactions(prefs.Redactions)
+	m.gistToken = prefs.GistToken
+	m.searchURLTemplate = prefs.SearchURL
+	m.memoryLimitMB = prefs.MemoryLimitMB
+	m.deviceAliases = prefs.DeviceAliases
+	m.tagBudgets = prefs.TagBudgets
+	m.monkeyEvents = prefs.MonkeyEvents
+	m.monkeySeed = prefs.MonkeySeed
+	m.autosaveOnSignal = prefs.AutosaveOnSignal
+	SetColorTheme(prefs.ColorTheme)
+	SetTagColors(prefs.TagColors)
+	SetPriorityStyles(prefs.PriorityStyles)
+	m.logLevelList.Styles.Title = m.logLevelList.Styles.Title.Foreground(GetAccentColor())
 	m.wrapLines = prefs.WrapLines
 	if prefs.LogLevelBackground != nil {
 		m.logLevelBackground = *prefs.LogLevelBackground
 	} else {
 		m.logLevelBackground = false
 	}
+	if prefs.StickyHeader != nil {
+		m.stickyHeader = *prefs.StickyHeader
+	}
 	if prefs.ColoredMessages != nil {
 		m.coloredMessages = *prefs.ColoredMessages
 	} else {
 		m.coloredMessages = true
 	}
 
+	SetTimestampFormat(prefs.TimestampFormat)
+	SetMaxLineLength(prefs.MaxLineLength)
 	if prefs.TagColumnWidth > 0 {
 		SetTagColumnWidth(prefs.TagColumnWidth)
 	} else {
 		SetTagColumnWidth(DefaultTagColumnWidth)
 	}
 
+	// Preferences can be applied again, so the previous sinks are closed first
+	m.forwarder.Close()
+	forwarder, err := newForwarder(prefs.Sinks)
+	m.forwarder = forwarder
+	if err != nil {
+		m.statusMessage = "sink failed: " + strings.ReplaceAll(err.Error(), "\n", "; ")
+	}
+	m.triggers = newTriggers(prefs.Triggers)
+	m.setExtractors(prefs.Extractors)
+	m.setWatches(prefs.Watches)
+	if prefs.ReorderWindowMs > 0 {
+		m.reorderer = logcat.NewReorderer(time.Duration(prefs.ReorderWindowMs) * time.Millisecond)
+	}
+
 	if len(prefs.Filters) == 0 {
 		m.filters = []Filter{}
 		m.filterInput.SetValue("")
@@ -386,6 +698,8 @@ func (m *Model) applyPreferences(prefs config.Preferences) {
 
 		m.filters = append(m.filters, Filter{
 			isTag:   pref.IsTag,
+			field:   pref.Field,
+			exclude: pref.Exclude,
 			pattern: pref.Pattern,
 			regex:   regex,
 		})
@@ -399,11 +713,188 @@ func (m *Model) applyPreferences(prefs config.Preferences) {
 	}
 }
 
+// newForwarder builds the sink forwarder from config. Rules with invalid
+// patterns and targets that fail to open are skipped and reported in the error.
+func newForwarder(sinks []config.SinkPreference) (*logcat.Forwarder, error) {
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	rules := make([]logcat.SinkRule, 0, len(sinks))
+	var errs []error
+	for _, sink := range sinks {
+		if sink.Target == "" {
+			continue
+		}
+		rule := logcat.SinkRule{Target: sink.Target, MinPriority: logcat.Verbose}
+		if priority, ok := priorityFromConfig(sink.MinLevel); ok {
+			rule.MinPriority = priority
+		}
+		if sink.Pattern != "" {
+			regex, err := regexp.Compile("(?i)" + sink.Pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid pattern: %w", sink.Target, err))
+				continue
+			}
+			rule.Pattern = regex
+		}
+		rules = append(rules, rule)
+	}
+
+	forwarder, err := logcat.NewForwarder(rules)
+	return forwarder, errors.Join(append(errs, err)...)
+}
+
+// setExtractors compiles the configured field extractors and exposes their fields as columns.
+func (m *Model) setExtractors(patterns []string) {
+	m.extractors = nil
+	var columns []string
+	for _, pattern := range patterns {
+		extractor, err := logcat.NewExtractor(pattern)
+		if err != nil {
+			continue
+		}
+		m.extractors = append(m.extractors, extractor)
+		columns = append(columns, extractor.Names()...)
+	}
+	SetExtraColumns(columns)
+}
+
+// appendEntry stores a newly parsed entry and runs per-entry processing on it.
+func (m *Model) appendEntry(entry *logcat.Entry) {
+	m.nextEntryID++
+	entry.ID = m.nextEntryID
+	if prev := m.lastEntry(); entry.Priority == logcat.Unknown && prev != nil {
+		if prev.Priority != logcat.Unknown && prev.Source == entry.Source && isContinuationText(entry.Message) {
+			entry.AttachTo(prev)
+		}
+	}
+	// Fields and levels from JSON and extractors are in place before anything counts the entry
+	logcat.ApplyJSONMessage(entry)
+	for _, extractor := range m.extractors {
+		extractor.Apply(entry)
+	}
+	if entry.Priority >= logcat.Error && entry.Priority != logcat.Unknown {
+		m.errorsSeen++
+	}
+	m.recordParseFailure(entry)
+	m.countWatches(entry)
+	for i := range m.filters {
+		if f := &m.filters[i]; f.counted && m.filterHits(*f, entry) {
+			f.matches++
+		}
+	}
+	m.trackPower(entry)
+	m.trackIntent(entry)
+	m.trackJob(entry)
+	if entry.Tag != "" && countTag(entry.Tag) {
+		m.resetRenderCache()
+	}
+	m.forwarder.Forward(entry)
+	m.checkTriggers(entry)
+	m.autoBookmark(entry)
+	m.checkTagBudget(entry)
+	m.bufferBytes += entry.Size()
+	m.levelCounts.add(entry, 1)
+	if m.frozen {
+		m.heldEntries = append(m.heldEntries, entry)
+		return
+	}
+	m.parsedEntries = append(m.parsedEntries, entry)
+	m.checkPauseOnError(entry)
+}
+
+// cycleTimestampFormat steps through the full, time-only and seconds-only timestamp formats.
+func (m *Model) cycleTimestampFormat() {
+	currentTimestampFormat = (currentTimestampFormat + 1) % timestampFormatCount
+	m.statusMessage = "timestamp format: " + currentTimestampFormat.String()
+	if !m.showTimestamp {
+		m.statusMessage += " (timestamps are hidden, enable them in settings)"
+	}
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// toggleRawMode switches between formatted entries and the lines exactly as received.
+func (m *Model) toggleRawMode() {
+	m.rawMode = !m.rawMode
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// isHighlighted reports whether entry is the highlighted entry.
+func (m *Model) isHighlighted(entry *logcat.Entry) bool {
+	return m.highlightedEntry != nil && entry.ID == m.highlightedEntry.ID
+}
+
+// lastEntry returns the most recently received entry, including held ones.
+func (m *Model) lastEntry() *logcat.Entry {
+	if len(m.heldEntries) > 0 {
+		return m.heldEntries[len(m.heldEntries)-1]
+	}
+	if len(m.parsedEntries) > 0 {
+		return m.parsedEntries[len(m.parsedEntries)-1]
+	}
+	return nil
+}
+
+// checkPauseOnError stops following at the first visible Error or worse entry,
+// highlighting it and, if configured, holding back further entries until resumed.
+func (m *Model) checkPauseOnError(entry *logcat.Entry) {
+	if !m.pauseOnError || m.errorPaused || !m.autoScroll {
+		return
+	}
+	if entry.Priority < logcat.Error || entry.Priority > logcat.Assert || !m.isVisible(entry) {
+		return
+	}
+	m.errorPaused = true
+	m.autoScroll = false
+	m.highlightedEntry = entry
+	m.pausedOn = entry
+	m.frozen = m.freezeOnError
+}
+
+// releaseReordered appends entries whose reordering window has elapsed and
+// schedules another flush while entries are still held back.
+// ingestLines parses streamed lines from source, "" for the primary stream,
+// and adds them to the log, through the reorderer when one is set.
+func (m *Model) ingestLines(lines []string, source string, now time.Time) {
+	if source == "" && m.multiSource() {
+		source = m.sources[0].serial
+	}
+	for _, line := range lines {
+		entry := m.parseLine(line)
+		if entry == nil {
+			continue
+		}
+		entry.Source = source
+		if m.reorderer != nil {
+			m.reorderer.Push(entry, now)
+		} else {
+			m.appendEntry(entry)
+		}
+	}
+}
+
+func (m *Model) releaseReordered(now time.Time) []tea.Cmd {
+	for _, entry := range m.reorderer.Release(now) {
+		m.appendEntry(entry)
+	}
+	if m.reorderer.Pending() == 0 || m.reorderScheduled {
+		return nil
+	}
+	m.reorderScheduled = true
+	return []tea.Cmd{tea.Tick(m.reorderer.Window(), func(time.Time) tea.Msg {
+		return reorderFlushMsg{}
+	})}
+}
+
 func (m *Model) resetRenderCache() {
-	m.renderedLines = nil
+	if m.renderAnchor.id == 0 {
+		m.renderAnchor = m.captureAnchor()
+	}
 	m.lineEntries = nil
 	m.entryLineRanges = nil
-	m.viewportContent = ""
 	m.renderedUpTo = 0
 	m.lastRenderedTag = ""
 	m.lastRenderedTime = ""
@@ -416,6 +907,40 @@ func (m *Model) resetRenderCache() {
 	m.renderReset = true
 }
 
+// resizeTagColumn grows or shrinks the tag column; the width is saved with the other preferences.
+func (m *Model) resizeTagColumn(delta int) {
+	width := TagColumnWidth() + delta
+	if width < minTagColumnWidth || width > maxTagColumnWidth {
+		return
+	}
+	SetTagColumnWidth(width)
+	m.statusMessage = fmt.Sprintf("tag column width: %d", width)
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// setLevels changes the visible priorities and re-renders the log.
+func (m *Model) setLevels(levels levelSet) {
+	m.levels = levels
+	*m.pendingLevels = levels
+	m.recordHistory("level: " + levels.label())
+	m.resetRenderCache()
+	m.updateViewport()
+}
+
+// levelPreview describes how many buffered entries the pending level choice
+// would show, against the current one, before it is applied. Filters are not
+// taken into account.
+func (m *Model) levelPreview() string {
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245")).PaddingLeft(2)
+	total := m.levelCounts.total()
+	preview := fmt.Sprintf("%d of %d entries (%s)", m.levelCounts.shown(*m.pendingLevels), total, m.pendingLevels.label())
+	if *m.pendingLevels != m.levels {
+		preview += fmt.Sprintf(", %d now", m.levelCounts.shown(m.levels))
+	}
+	return helpStyle.Render(preview) + "\n" + helpStyle.Render("enter: apply | esc: cancel")
+}
+
 func priorityFromConfig(value string) (logcat.Priority, bool) {
 	trimmed := strings.TrimSpace(value)
 	if trimmed == "" {
@@ -435,6 +960,8 @@ func priorityFromConfig(value string) (logcat.Priority, bool) {
 		return logcat.Error, true
 	case "F", "FATAL":
 		return logcat.Fatal, true
+	case "A", "ASSERT":
+		return logcat.Assert, true
 	default:
 		return 0, false
 	}
@@ -442,10 +969,21 @@ func priorityFromConfig(value string) (logcat.Priority, bool) {
 
 func formatFilterPreference(pref config.FilterPreference) string {
 	pattern := strings.ReplaceAll(pref.Pattern, ",", "\\,")
+	prefix := ""
+	if pref.Exclude {
+		prefix = "-"
+	}
+	if pref.Field != "" {
+		return prefix + "field:" + pref.Field + "=" + pattern
+	}
 	if pref.IsTag {
-		return "tag:" + pattern
+		return prefix + "tag:" + pattern
+	}
+	if prefix == "" && (strings.HasPrefix(pattern, "-") || strings.HasPrefix(pattern, "!")) {
+		// Escaped so it isn't read back as an exclusion
+		prefix = "\\"
 	}
-	return pattern
+	return prefix + pattern
 }
 
 func isStackTraceLine(message string) bool {
@@ -471,6 +1009,15 @@ func isStackTraceLine(message string) bool {
 	return false
 }
 
+// isContinuationText reports whether an unparsed line looks like the wrapped
+// tail of the preceding entry rather than a separator or stray output.
+func isContinuationText(message string) bool {
+	if strings.HasPrefix(message, " ") || strings.HasPrefix(message, "\t") {
+		return true
+	}
+	return isStackTraceLine(message)
+}
+
 func sameEntryMeta(a, b *logcat.Entry) bool {
 	if a == nil || b == nil {
 		return false
@@ -486,24 +1033,41 @@ func shouldContinue(prev, curr, next *logcat.Entry) bool {
 	if !sameEntryMeta(prev, curr) {
 		return false
 	}
-	if isStackTraceLine(curr.Message) {
+	if curr.Unparsed || isStackTraceLine(curr.Message) {
 		return true
 	}
-	if sameEntryMeta(curr, next) && isStackTraceLine(next.Message) {
+	if sameEntryMeta(curr, next) && (next.Unparsed || isStackTraceLine(next.Message)) {
 		return true
 	}
 	return false
 }
 
 func (m Model) Init() tea.Cmd {
+	// A log file is read like a device stream, without any device queries
+	if m.logManager != nil && m.logManager.FilePath() != "" {
+		return startFile(m.logManager)
+	}
+	// Imported text is all there is to show
+	if m.importName != "" {
+		return nil
+	}
+
+	var execCmd tea.Cmd
+	if m.exec.running {
+		execCmd = startExec()
+	}
+
 	// If showing device selector, don't start logcat yet
 	if m.showDeviceSelect {
-		return nil
+		return tea.Batch(scheduleDeviceRefresh(), execCmd)
 	}
 
 	cmds := []tea.Cmd{
+		execCmd,
 		startLogcat(m.logManager, m.lineChan),
 		waitForLogLine(m.lineChan),
+		measureClockSkew(m.logManager),
+		pollActivity(m.logManager, 0),
 	}
 
 	// If filtering by app, listen for status updates
@@ -526,7 +1090,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Calculate header height based on what will be shown
 		headerHeight, footerHeight := m.layoutHeights()
 		verticalMargin := headerHeight + footerHeight
-		viewportHeight := msg.Height - verticalMargin
+		viewportHeight := msg.Height - verticalMargin - m.stickyRows()
+		viewportHeight -= m.snapshotRows(viewportHeight) + m.execRows(viewportHeight)
 		if viewportHeight < 0 {
 			viewportHeight = 0
 		}
@@ -541,77 +1106,279 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.YPosition = 0
 		}
 
+		if msg.Width != m.width {
+			m.lineCache.clear()
+		}
 		m.width = msg.Width
 		m.height = msg.Height
-		m.renderReset = true
-		m.needsUpdate = true
-		if !m.renderScheduled {
-			m.renderScheduled = true
-			cmds = append(cmds, scheduleViewportUpdate())
+		SetNarrowLayout(msg.Width < m.narrowWidth)
+		if m.showDetail {
+			m.resizeDetail()
+		}
+		if m.pendingJump != nil {
+			// Entries can only be scrolled to once the view has a size
+			m.jumpToEntry(m.pendingJump)
+			m.pendingJump = nil
 		}
+		m.renderReset = true
+		cmds = append(cmds, m.requestRender())
 
 	case logLineMsg:
-		for _, line := range msg.lines {
-			entry, _ := logcat.ParseLine(line)
-			if entry != nil {
-				m.parsedEntries = append(m.parsedEntries, entry)
+		now := time.Now()
+		m.ingestLines(msg.lines, msg.source, now)
+		if m.reorderer != nil {
+			cmds = append(cmds, m.releaseReordered(now)...)
+		}
+		if m.autoScroll && m.frozen {
+			// Following resumed some other way, e.g. clearing the search
+			m.releaseHeld()
+		}
+		m.enforceMemoryLimit()
+		m.streamActive = now.Sub(m.lastLinesAt) < streamIdleAfter
+		m.lastLinesAt = now
+		cmds = append(cmds, m.requestRender())
+
+		if !m.terminating {
+			if src := m.sourceBySerial(msg.source); msg.source != "" && src != nil {
+				cmds = append(cmds, waitForSourceLine(src.serial, src.lineChan))
+			} else {
+				cmds = append(cmds, waitForLogLine(m.lineChan))
 			}
 		}
-		m.needsUpdate = true
-		if !m.renderScheduled {
-			m.renderScheduled = true
-			cmds = append(cmds, scheduleViewportUpdate())
+
+	case clockSkewMsg:
+		if msg.err == nil {
+			m.clockSkew = msg.skew
+			m.clockSkewKnown = true
+			if m.hostTime {
+				m.applyTimestampShift()
+				m.resetRenderCache()
+				m.updateViewportWithScroll(m.autoScroll)
+			}
 		}
 
+	case activityMsg:
+		// A failed query keeps the last activity; the device may just be reconnecting
+		if msg.err == nil {
+			m.activity = msg.activity
+		}
 		if !m.terminating {
-			cmds = append(cmds, waitForLogLine(m.lineChan))
+			cmds = append(cmds, pollActivity(m.logManager, m.activityInterval()))
+		}
+
+	case devicesMsg:
+		if !m.showDeviceSelect {
+			return m, nil
+		}
+		if msg.err == nil {
+			m.devices = msg.devices
+			for serial := range m.checkedDevices {
+				if !m.deviceOnline(serial) {
+					delete(m.checkedDevices, serial)
+				}
+			}
+			index := m.deviceList.Index()
+			m.deviceList.SetItems(deviceItems(msg.devices))
+			m.deviceList.SetHeight(len(msg.devices) + 4)
+			if index >= len(msg.devices) {
+				index = len(msg.devices) - 1
+			}
+			if index >= 0 {
+				m.deviceList.Select(index)
+			}
+		}
+		return m, scheduleDeviceRefresh()
+
+	case sourceErrMsg:
+		m.statusMessage = fmt.Sprintf("source %s failed: %v", msg.serial, msg.err)
+
+	case monkeyDoneMsg:
+		m.finishMonkey(msg)
+
+	case testsStartedMsg:
+		cmds = append(cmds, m.testsStarted(msg))
+
+	case testStatusMsg:
+		m.recordTestStatus(logcat.InstrumentStatus(msg))
+		cmds = append(cmds, waitForTestStatus(m.tests.statuses, m.tests.done))
+
+	case testsDoneMsg:
+		m.finishTests(msg.err)
+
+	case execStartedMsg:
+		cmds = append(cmds, m.execStarted(msg))
+
+	case execLineMsg:
+		m.appendExecOutput(msg.lines)
+		cmds = append(cmds, waitForExecLines(m.exec.lines, m.exec.done))
+
+	case execDoneMsg:
+		m.execDone(msg.err)
+
+	case SignalMsg:
+		return m, m.handleSignal()
+
+	case monkeyStopMsg:
+		m.statusMessage = msg.err.Error()
+
+	case reportMsg:
+		if msg.err != nil {
+			m.statusMessage = "report failed: " + msg.err.Error()
+		} else {
+			m.statusMessage = "report saved: " + msg.path
+			m.exportPaths = append(m.exportPaths, msg.path)
+		}
+
+	case webSearchMsg:
+		if msg.err != nil {
+			m.statusMessage = "web search failed: " + msg.err.Error()
 		}
 
+	case gistMsg:
+		if msg.err != nil {
+			m.statusMessage = "gist failed: " + msg.err.Error()
+		} else if err := copyToClipboard(msg.url); err != nil {
+			m.statusMessage = "gist created: " + msg.url
+		} else {
+			m.statusMessage = "gist URL copied: " + msg.url
+		}
+
+	case externalDoneMsg:
+		if msg.err != nil {
+			m.statusMessage = "external viewer failed: " + msg.err.Error()
+		}
+
+	case reorderFlushMsg:
+		m.reorderScheduled = false
+		cmds = append(cmds, m.releaseReordered(time.Now())...)
+		cmds = append(cmds, m.requestRender())
+
 	case appStatusMsg:
-		m.appStatus = string(msg)
+		m.appStatus = msg.State
+		m.appStatusErr = msg.Err
+		m.appStatusSince = msg.At
+		if msg.PID != "" {
+			m.appPID = msg.PID
+		}
+		if msg.MainPID != "" {
+			// Only a new main process is a restart; secondary processes such
+			// as ":remote" services come and go on their own
+			if m.appMainPID != "" && msg.MainPID != m.appMainPID {
+				m.appRestarts++
+				m.appRestartedAt = msg.At
+			}
+			m.appMainPID = msg.MainPID
+		}
 		if !m.terminating {
 			cmds = append(cmds, waitForStatus(m.logManager.StatusChan()))
 		}
+		if m.statusDegraded() {
+			cmds = append(cmds, m.startStatusClock())
+		}
 	case deviceStatusMsg:
-		m.deviceStatus = string(msg)
+		m.deviceStatus = msg.State
+		m.deviceStatusSince = msg.At
 		if !m.terminating {
 			cmds = append(cmds, waitForDeviceStatus(m.logManager.DeviceStatusChan()))
 		}
+		if m.statusDegraded() {
+			cmds = append(cmds, m.startStatusClock())
+		}
+	case statusClockMsg:
+		// Re-render so "… ago" in the header stays current; keep ticking while degraded
+		m.statusClockScheduled = false
+		if m.statusDegraded() && !m.terminating {
+			cmds = append(cmds, m.startStatusClock())
+		}
 
 	case updateViewportMsg:
 		m.renderScheduled = false
-		if m.needsUpdate && m.ready {
+		if m.needsUpdate && m.ready && !m.logHidden() {
 			m.updateViewportWithScroll(m.autoScroll)
 			m.needsUpdate = false
+			if m.pausedOn != nil {
+				m.ensureEntryVisible(m.pausedOn)
+				m.pausedOn = nil
+			}
+		}
+		if m.needsUpdate {
+			// Still stale while an overlay hides the log; check again later
+			cmds = append(cmds, m.requestRender())
 		}
-		if m.needsUpdate && !m.renderScheduled {
-			m.renderScheduled = true
-			cmds = append(cmds, scheduleViewportUpdate())
+
+	case backfillMsg:
+		if msg.err != nil {
+			m.statusMessage = "reload failed: " + msg.err.Error()
+			return m, nil
 		}
+		m.applyBackfill(msg.lines)
+		return m, nil
+
+	case duplicateMsg:
+		m.statusMessage = "warning: " + msg.String()
+		return m, nil
+
+	case fileStartedMsg:
+		return m, m.readFile(msg)
 
 	case errMsg:
-		// Handle errors from logcat start
+		// Handle errors from logcat start, recovering where the error allows it
+		if cmd, ok := m.recoverFrom(msg.err); ok {
+			return m, cmd
+		}
 		m.errorMessage = msg.Error()
+		if hint := adb.Hint(msg.err); hint != "" {
+			m.errorMessage += " (" + hint + ")"
+		}
 		m.terminating = true
 		return m, tea.Quit
 
 	case tea.KeyMsg:
+		m.statusMessage = ""
+		m.recordMacroKey(msg)
 		if m.showDeviceSelect {
 			switch msg.String() {
 			case "q", "ctrl+c", "esc":
 				m.terminating = true
 				return m, tea.Quit
+			case " ":
+				if i, ok := m.deviceList.SelectedItem().(deviceItem); ok && adb.Device(i).Status == "device" {
+					serial := adb.Device(i).Serial
+					if m.checkedDevices[serial] {
+						delete(m.checkedDevices, serial)
+					} else {
+						m.checkedDevices[serial] = true
+					}
+				}
+				return m, nil
 			case "enter":
 				if i, ok := m.deviceList.SelectedItem().(deviceItem); ok {
 					device := adb.Device(i)
+					if device.Status != "device" && len(m.checkedDevices) == 0 {
+						m.statusMessage = fmt.Sprintf("%s is %s", device.Serial, deviceStatusBadge(device.Status))
+						return m, nil
+					}
+					var extra []adb.Device
+					if len(m.checkedDevices) > 0 {
+						var checked []adb.Device
+						for _, d := range m.devices {
+							if m.checkedDevices[d.Serial] {
+								checked = append(checked, d)
+							}
+						}
+						device, extra = checked[0], checked[1:]
+					}
 					m.logManager.SetDevice(device.Serial)
-					m.selectedDevice = device.Model
-					m.deviceStatus = "connected"
+					m.selectedDevice = device.DisplayName()
+					m.deviceStatus = logcat.StateConnected
 					m.showDeviceSelect = false
+					m.recordHistory("device: " + m.selectedDevice)
 					// Start logcat now that device is selected
 					cmds := []tea.Cmd{
 						startLogcat(m.logManager, m.lineChan),
 						waitForLogLine(m.lineChan),
+						measureClockSkew(m.logManager),
+						pollActivity(m.logManager, 0),
 					}
 					if m.appID != "" {
 						cmds = append(cmds, waitForStatus(m.logManager.StatusChan()))
@@ -619,6 +1386,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if m.selectedDevice != "" {
 						cmds = append(cmds, waitForDeviceStatus(m.logManager.DeviceStatusChan()))
 					}
+					if len(extra) > 0 {
+						m.addPrimarySource(device)
+						for _, d := range extra {
+							cmds = append(cmds, m.addSource(d))
+						}
+					}
 					return m, tea.Batch(cmds...)
 				}
 				return m, nil
@@ -628,56 +1401,68 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "esc":
 				m.showLogLevel = false
 				return m, nil
-			case "enter":
+			case " ":
 				if i, ok := m.logLevelList.SelectedItem().(logLevelItem); ok {
-					m.minLogLevel = logcat.Priority(i)
-					m.showLogLevel = false
-					m.resetRenderCache()
-					m.updateViewport()
+					*m.pendingLevels = m.pendingLevels.toggle(logcat.Priority(i))
 				}
 				return m, nil
-			case "v":
-				m.minLogLevel = logcat.Verbose
-				m.showLogLevel = false
-				m.resetRenderCache()
-				m.updateViewport()
-				return m, nil
-			case "d":
-				m.minLogLevel = logcat.Debug
-				m.showLogLevel = false
-				m.resetRenderCache()
-				m.updateViewport()
-				return m, nil
-			case "i":
-				m.minLogLevel = logcat.Info
+			case "enter":
+				if *m.pendingLevels != m.levels {
+					m.setLevels(*m.pendingLevels)
+				} else if i, ok := m.logLevelList.SelectedItem().(logLevelItem); ok {
+					m.setLevels(levelsFrom(logcat.Priority(i)))
+				}
 				m.showLogLevel = false
-				m.resetRenderCache()
-				m.updateViewport()
 				return m, nil
-			case "w":
-				m.minLogLevel = logcat.Warn
-				m.showLogLevel = false
-				m.resetRenderCache()
-				m.updateViewport()
+			case "v", "d", "i", "w", "e", "f", "a":
+				priority, _ := priorityFromConfig(msg.String())
+				*m.pendingLevels = m.pendingLevels.toggle(priority)
+				m.logLevelList.Select(int(priority))
 				return m, nil
-			case "e":
-				m.minLogLevel = logcat.Error
-				m.showLogLevel = false
-				m.resetRenderCache()
-				m.updateViewport()
-				return m, nil
-			case "f":
-				m.minLogLevel = logcat.Fatal
-				m.showLogLevel = false
-				m.resetRenderCache()
-				m.updateViewport()
+			case "V", "D", "I", "W", "E", "F", "A":
+				priority, _ := priorityFromConfig(msg.String())
+				*m.pendingLevels = levelsFrom(priority)
+				m.logLevelList.Select(int(priority))
 				return m, nil
 			}
+		} else if m.showSources {
+			m.handleSourcesKey(msg.String())
+			return m, nil
+		} else if m.showHistory {
+			m.handleHistoryKey(msg.String())
+			return m, nil
+		} else if m.showParseErrors {
+			m.handleParseErrorsKey(msg.String())
+			return m, nil
+		} else if m.showExportPicker {
+			m.handleExportPickerKey(msg.String())
+			return m, nil
+		} else if m.showPower {
+			if msg.String() == "esc" || msg.String() == "W" {
+				m.showPower = false
+			}
+			return m, nil
+		} else if m.showIntents {
+			m.handleIntentsKey(msg.String())
+			return m, nil
+		} else if m.showJobs {
+			m.handleJobsKey(msg.String())
+			return m, nil
+		} else if m.showStats {
+			m.handleStatsKey(msg.String())
+			return m, nil
+		} else if m.showTests {
+			return m, m.handleTestsKey(msg.String())
+		} else if m.showDetail {
+			if !m.handleDetailKey(msg.String()) {
+				m.detailViewport, cmd = m.detailViewport.Update(msg)
+			}
+			return m, cmd
 		} else if m.showSettings {
 			switch msg.String() {
 			case "q", "ctrl+c":
 				m.terminating = true
-				m.logManager.Stop()
+				m.stopLogging()
 				return m, tea.Quit
 			case "esc", "s":
 				m.showSettings = false
@@ -694,6 +1479,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case " ", "enter":
 				m.toggleSetting(m.settingsIndex)
 				return m, nil
+			case "h", "left", "l", "right":
+				delta := 1
+				if msg.String() == "h" || msg.String() == "left" {
+					delta = -1
+				}
+				m.stepTailSize(delta)
+				return m, nil
+			case "r":
+				m.showSettings = false
+				return m, m.reloadHistory()
 			}
 		} else if m.showFilter {
 			switch msg.String() {
@@ -702,13 +1497,68 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.filterInput.Blur()
 				return m, nil
 			case "enter":
+				if len(m.filters) > 0 {
+					m.lastFilters = m.filters
+				}
 				m.parseFilters(m.filterInput.Value())
+				m.filtersOff = false
+				m.recordHistory("filters: " + m.historyFilters())
 				m.showFilter = false
 				m.filterInput.Blur()
+				m.lineCache.clear()
 				m.resetRenderCache()
 				m.updateViewport()
 				return m, nil
 			}
+		} else if m.showUntilInput {
+			switch msg.String() {
+			case "esc":
+				m.showUntilInput = false
+				m.untilInput.Blur()
+				return m, nil
+			case "enter":
+				m.applyUntilPrompt()
+				m.renderReset = true
+				m.updateViewportWithScroll(false)
+				return m, nil
+			}
+		} else if m.showSearchInput {
+			switch msg.String() {
+			case "esc":
+				m.cancelSearch()
+				m.updateViewportWithScroll(m.autoScroll)
+				return m, nil
+			case "enter":
+				m.applySearch()
+				return m, nil
+			}
+		} else if m.showWatchInput {
+			switch msg.String() {
+			case "esc":
+				m.showWatchInput = false
+				m.watchInput.Blur()
+				return m, nil
+			case "enter":
+				m.applyWatchPrompt()
+				return m, nil
+			}
+		} else if m.showAnnotate {
+			switch msg.String() {
+			case "esc":
+				m.showAnnotate = false
+				m.annotateInput.Blur()
+				return m, nil
+			case "enter":
+				m.saveAnnotation()
+				m.showAnnotate = false
+				m.annotateInput.Blur()
+				m.resetRenderCache()
+				m.updateViewportWithScroll(false)
+				return m, nil
+			}
+		} else if m.showSavePrompt {
+			m.handleSaveKey(msg.String())
+			return m, nil
 		} else if m.showClearConfirm {
 			switch msg.String() {
 			case "esc":
@@ -721,8 +1571,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if input == "y" || input == "yes" {
 					// Clear the log display
 					m.parsedEntries = make([]*logcat.Entry, 0, 10000)
+					m.bufferBytes = 0
+					*m.levelCounts = levelCounts{}
+					m.evictedEntries = 0
+					m.resetFilterCounts()
+					m.annotations = make(map[uint64]string)
+					m.flags = make(map[uint64]entryFlags)
+					m.parseSamples = nil
 					m.highlightedEntry = nil
+					m.contextEntry = nil
+					m.heldEntries = nil
 					m.clearSelection()
+					m.lineCache.clear()
 					m.resetRenderCache()
 					m.updateViewport()
 				}
@@ -731,24 +1591,192 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.clearInput.SetValue("")
 				return m, nil
 			}
+		} else if m.quickToken != "" && m.applyTokenAction(msg.String()) {
+			return m, nil
 		} else {
 			switch msg.String() {
 			case "q", "ctrl+c":
 				m.terminating = true
-				m.logManager.Stop()
+				m.stopLogging()
 				return m, tea.Quit
 			case "l":
 				m.showLogLevel = true
+				*m.pendingLevels = m.levels
 				return m, nil
 			case "s":
 				m.showSettings = true
 				m.settingsIndex = 0
 				return m, nil
+			case "<", ">":
+				delta := 1
+				if msg.String() == "<" {
+					delta = -1
+				}
+				m.resizeTagColumn(delta)
+				return m, nil
+			case "]", "+":
+				m.setLevels(m.levels.step(1))
+				return m, nil
+			case "[", "-":
+				m.setLevels(m.levels.step(-1))
+				return m, nil
 			case "f":
 				m.showFilter = true
 				m.filterInput.Focus()
 				return m, textinput.Blink
+			case "t":
+				m.toggleFilters()
+				return m, nil
+			case "*", "x":
+				flag := flagImportant
+				if msg.String() == "x" {
+					flag = flagReviewed
+				}
+				m.toggleFlag(flag)
+				m.resetRenderCache()
+				m.updateViewportWithScroll(false)
+				return m, nil
+			case "S":
+				m.toggleSnapshot()
+				return m, nil
+			case "shift+up", "shift+down":
+				if m.showSnapshot {
+					delta := 1
+					if msg.String() == "shift+up" {
+						delta = -1
+					}
+					m.scrollSnapshot(delta)
+				}
+				return m, nil
+			case "W":
+				m.showPower = true
+				return m, nil
+			case "I":
+				m.showIntents = true
+				m.intentsIndex = max(0, len(m.intents)-1)
+				return m, nil
+			case "M":
+				return m, m.toggleMonkey()
+			case "i":
+				m.showTests = true
+				return m, nil
+			case "e":
+				return m, m.rerunExec()
+			case "w":
+				m.showJobs = true
+				m.jobsIndex = max(0, len(m.jobs)-1)
+				return m, nil
+			case "m":
+				m.openStats()
+				return m, nil
+			case "L":
+				m.toggleSpotlight()
+				return m, nil
+			case "U":
+				m.showParseErrors = true
+				m.parseErrorsIndex = len(m.parseSamples) - 1
+				return m, nil
+			case "X":
+				m.excludeBudgetTag()
+				return m, nil
+			case "J", "K":
+				step := 1
+				if msg.String() == "K" {
+					step = -1
+				}
+				m.jumpToBookmark(step)
+				m.renderReset = true
+				m.updateViewportWithScroll(false)
+				return m, nil
+			case "F":
+				m.toggleFollow()
+				return m, nil
+			case "R":
+				m.toggleRawMode()
+				return m, nil
+			case "T":
+				m.cycleTimestampFormat()
+				return m, nil
+			case "enter":
+				if m.highlightedEntry != nil {
+					m.openDetail(m.highlightedEntry)
+				} else {
+					m.statusMessage = "highlight an entry (j/k) to view it"
+				}
+				return m, nil
+			case "Q":
+				m.toggleMacroRecording()
+				return m, nil
+			case "@":
+				return m.replayMacro()
+			case "H":
+				m.showHistory = true
+				m.historyIndex = len(m.history) - 1
+				return m, nil
+			case "D":
+				if m.multiSource() {
+					m.showSources = true
+					m.sourcesIndex = 0
+				}
+				return m, nil
+			case "y":
+				if m.highlightedEntry == nil {
+					m.statusMessage = "highlight an entry to search the web for it"
+					return m, nil
+				}
+				return m, m.webSearch(searchQuery(m.highlightedEntry))
+			case "Y":
+				m.copyPermalink()
+				return m, nil
+			case "/":
+				m.startSearch()
+				return m, nil
+			case "n", "N":
+				step := 1
+				if msg.String() == "N" {
+					step = -1
+				}
+				m.jumpToMatch(step)
+				m.renderReset = true
+				m.updateViewportWithScroll(false)
+				return m, nil
+			case "a":
+				if m.startAnnotation() {
+					return m, textinput.Blink
+				}
+				return m, nil
+			case "#":
+				m.startWatchPrompt()
+				return m, textinput.Blink
+			case "o":
+				m.sortMode = nextSortMode(m.sortMode)
+				if m.sortMode.kind != sortArrival {
+					m.autoScroll = false
+				}
+				m.resetRenderCache()
+				m.updateViewportWithScroll(m.autoScroll)
+				return m, nil
+			case "O":
+				if m.sortMode.kind != sortArrival {
+					m.sortMode = sortMode{}
+					m.autoScroll = true
+					m.releaseHeld()
+					m.resetRenderCache()
+					m.updateViewport()
+				}
+				return m, nil
+			case "z":
+				if m.contextEntry != nil {
+					m.exitContext()
+				} else {
+					m.enterContext()
+				}
+				return m, nil
 			case "esc":
+				if m.contextEntry != nil {
+					m.exitContext()
+					return m, nil
+				}
 				if m.selectionMode {
 					m.selectionMode = false
 					m.clearSelection()
@@ -777,6 +1805,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, textinput.Blink
 				}
 				return m, nil
+			case "g": // g to share selection as a secret gist
+				if m.selectionMode && len(m.selectedEntries) > 0 {
+					filename := "logdog." + m.exporter().Extension()
+					m.statusMessage = "uploading gist..."
+					return m, shareGist(gist.Token(m.gistToken), filename, m.export(m.selection()))
+				}
+				return m, nil
+			case "b", "B": // b/B to create a report bundle, B with a screenshot
+				m.statusMessage = "creating report bundle..."
+				return m, m.createReport(msg.String() == "B")
+			case "P":
+				m.startExportPicker()
+				return m, nil
+			case "ctrl+s":
+				m.startSavePrompt()
+				return m, nil
+			case "p", "E": // p/E to open the selection or filtered view in $PAGER/$EDITOR
+				command := pagerCommand()
+				if msg.String() == "E" {
+					command = editorCommand()
+				}
+				return m, openExternal(command, m.export(m.exportEntries()), m.exporter().Extension())
+			case "u": // u to extend the selection to the next match of a regex
+				if m.selectionMode && len(m.selectedEntries) > 0 {
+					m.startUntilPrompt()
+					return m, textinput.Blink
+				}
+				return m, nil
+			case "%": // % to select the whole stack trace at the selection
+				if m.selectionMode {
+					m.selectStackTrace()
+					m.renderReset = true
+					m.updateViewportWithScroll(false)
+				}
+				return m, nil
 			case "C": // C to copy message only in selection mode
 				if m.selectionMode && len(m.selectedEntries) > 0 {
 					m.copySelectedMessagesOnly()
@@ -810,13 +1873,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.MouseMsg:
-		// Only handle mouse release (not drag) to avoid performance issues
-		if msg.Type == tea.MouseRelease && msg.Button == tea.MouseButtonLeft && !m.showLogLevel && !m.showFilter && !m.showDeviceSelect && !m.showSettings {
-			m.autoScroll = false
-			m.handleMouseClick(msg.Y)
-			m.renderReset = true
-			m.updateViewportWithScroll(false)
-			return m, nil
+		// Only handle clicks and alt-drags; plain motion is ignored to avoid performance issues
+		if !m.showLogLevel && !m.showFilter && !m.showDeviceSelect && !m.showSettings && !m.showAnnotate && !m.showWatchInput && !m.showUntilInput && !m.showSearchInput && !m.showSources && !m.showDetail && !m.showHistory && !m.showParseErrors && !m.showPower && !m.showIntents && !m.showJobs && !m.showStats && !m.showTests && !m.showExportPicker {
+			if m.handleMouse(msg) {
+				m.renderReset = true
+				m.updateViewportWithScroll(false)
+				return m, nil
+			}
+			if m.columnDrag != nil || msg.Alt {
+				return m, nil
+			}
 		}
 	}
 
@@ -828,22 +1894,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	} else if m.showSettings {
 		// no component update
+	} else if m.showDetail {
+		m.detailViewport, cmd = m.detailViewport.Update(msg)
+		cmds = append(cmds, cmd)
 	} else if m.showFilter {
 		m.filterInput, cmd = m.filterInput.Update(msg)
 		cmds = append(cmds, cmd)
+	} else if m.showAnnotate {
+		m.annotateInput, cmd = m.annotateInput.Update(msg)
+		cmds = append(cmds, cmd)
+	} else if m.showWatchInput {
+		m.watchInput, cmd = m.watchInput.Update(msg)
+		cmds = append(cmds, cmd)
+	} else if m.showUntilInput {
+		m.untilInput, cmd = m.untilInput.Update(msg)
+		cmds = append(cmds, cmd)
+	} else if m.showSearchInput {
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		cmds = append(cmds, cmd)
+		if m.searchInput.Value() != m.searchText {
+			m.updateSearch()
+			m.updateViewportWithScroll(m.autoScroll)
+		}
 	} else if m.showClearConfirm {
 		m.clearInput, cmd = m.clearInput.Update(msg)
 		cmds = append(cmds, cmd)
 	} else {
 		// Track viewport position before update
-		wasAtBottom := m.viewport.AtBottom()
-		m.viewport, cmd = m.viewport.Update(msg)
-		cmds = append(cmds, cmd)
+		wasAtBottom := m.atBottom()
+		m.scrollLog(msg)
 
 		// Re-enable auto-scroll if user scrolled to bottom
-		if !wasAtBottom && m.viewport.AtBottom() {
+		if !wasAtBottom && m.atBottom() {
 			m.autoScroll = true
-		} else if wasAtBottom && !m.viewport.AtBottom() {
+			m.releaseHeld()
+			m.updateViewport()
+		} else if wasAtBottom && !m.atBottom() {
 			// Disable auto-scroll if user scrolled away from bottom
 			m.autoScroll = false
 		}
@@ -852,13 +1938,48 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// toggleFollow switches between following new entries and a paused view.
+// Resuming jumps to the newest entry and drops the highlight outside selection mode.
+func (m *Model) toggleFollow() {
+	if m.autoScroll {
+		m.autoScroll = false
+		return
+	}
+	m.autoScroll = true
+	m.releaseHeld()
+	if !m.selectionMode {
+		m.highlightedEntry = nil
+	}
+	m.renderReset = true
+	m.updateViewportWithScroll(true)
+}
+
+// releaseHeld ends a pause on an error, appending the entries held back while
+// frozen. Every way of following again goes through it, so entries don't keep
+// piling up behind a view that already follows.
+func (m *Model) releaseHeld() {
+	m.errorPaused = false
+	if !m.frozen {
+		return
+	}
+	m.frozen = false
+	m.parsedEntries = append(m.parsedEntries, m.heldEntries...)
+	m.heldEntries = nil
+	m.enforceMemoryLimit()
+}
+
+// footerPromptActive reports whether a text prompt occupies the footer.
+func (m Model) footerPromptActive() bool {
+	return m.showFilter || m.showClearConfirm || m.showSavePrompt || m.showAnnotate || m.showWatchInput || m.showUntilInput || m.showSearchInput
+}
+
 func (m Model) layoutHeights() (int, int) {
 	headerHeight := 3
-	if !m.showFilter && !m.showClearConfirm {
+	if !m.footerPromptActive() {
 		headerHeight = 4
 	}
 	footerHeight := 2
-	if m.showFilter || m.showClearConfirm {
+	if m.footerPromptActive() {
 		footerHeight = 3
 	}
 	return headerHeight, footerHeight
@@ -874,6 +1995,26 @@ func (m *Model) settingLabel(index int) string {
 		return "Log level background"
 	case settingColoredMessages:
 		return "Colored messages"
+	case settingHostTime:
+		return "Show timestamps in host time"
+	case settingZoneTime:
+		return "Show timestamps in " + m.displayZone().String()
+	case settingRedact:
+		return "Redact copied and exported text"
+	case settingShowUnparsed:
+		return "Show unparsed lines"
+	case settingStickyHeader:
+		return "Sticky context line"
+	case settingPauseOnError:
+		return "Pause on first error"
+	case settingRawMode:
+		return "Show raw lines"
+	case settingBookmarkFatal:
+		return "Bookmark fatal entries"
+	case settingBookmarkErrors:
+		return "Bookmark errors too"
+	case settingLowPower:
+		return "Low-power mode (slower refresh)"
 	default:
 		return ""
 	}
@@ -889,6 +2030,26 @@ func (m *Model) settingValue(index int) bool {
 		return m.logLevelBackground
 	case settingColoredMessages:
 		return m.coloredMessages
+	case settingHostTime:
+		return m.hostTime
+	case settingZoneTime:
+		return m.zoneTime
+	case settingRedact:
+		return m.redact
+	case settingShowUnparsed:
+		return !m.hideUnparsed
+	case settingStickyHeader:
+		return m.stickyHeader
+	case settingPauseOnError:
+		return m.pauseOnError
+	case settingRawMode:
+		return m.rawMode
+	case settingBookmarkFatal:
+		return m.bookmarkFatal
+	case settingBookmarkErrors:
+		return m.bookmarkErrors
+	case settingLowPower:
+		return m.lowPower
 	default:
 		return false
 	}
@@ -912,6 +2073,92 @@ func (m *Model) toggleSetting(index int) {
 		m.coloredMessages = !m.coloredMessages
 		m.resetRenderCache()
 		m.updateViewportWithScroll(false)
+	case settingHostTime:
+		m.hostTime = !m.hostTime
+		m.applyTimestampShift()
+		m.resetRenderCache()
+		m.updateViewportWithScroll(false)
+	case settingZoneTime:
+		m.zoneTime = !m.zoneTime
+		m.applyTimeZone()
+		m.resetRenderCache()
+		m.updateViewportWithScroll(false)
+	case settingRedact:
+		m.redact = !m.redact
+	case settingStickyHeader:
+		m.stickyHeader = !m.stickyHeader
+		m.resizeViewport()
+	case settingPauseOnError:
+		m.pauseOnError = !m.pauseOnError
+		m.releaseHeld()
+		m.updateViewportWithScroll(m.autoScroll)
+	case settingShowUnparsed:
+		m.hideUnparsed = !m.hideUnparsed
+		m.resetRenderCache()
+		m.updateViewportWithScroll(m.autoScroll)
+	case settingRawMode:
+		m.toggleRawMode()
+	case settingBookmarkFatal:
+		m.bookmarkFatal = !m.bookmarkFatal
+	case settingBookmarkErrors:
+		m.bookmarkErrors = !m.bookmarkErrors
+	case settingLowPower:
+		m.lowPower = !m.lowPower
+		m.applyReadInterval()
+	}
+}
+
+// defaultRedactions are used when the config does not list any redaction rules.
+var defaultRedactions = []string{"email", "uuid", "token"}
+
+// setRedactions compiles the redaction rules, or the defaults when there are
+// none. A rule that doesn't compile is skipped and reported in the status bar,
+// so the others still apply.
+func (m *Model) setRedactions(rules []string) {
+	if len(rules) == 0 {
+		rules = defaultRedactions
+	}
+	redactor, err := logcat.NewRedactor(rules)
+	if err != nil {
+		m.statusMessage = strings.ReplaceAll(err.Error(), "\n", "; ")
+	}
+	m.redactor = redactor
+}
+
+// redactText applies the redaction rules to text leaving logdog when redaction is enabled.
+func (m *Model) redactText(text string) string {
+	if !m.redact {
+		return text
+	}
+	if m.redactor == nil {
+		m.setRedactions(nil)
+	}
+	return m.redactor.Redact(text)
+}
+
+// displayZone returns the configured display time zone, defaulting to UTC.
+func (m *Model) displayZone() *time.Location {
+	if m.timeZone != nil {
+		return m.timeZone
+	}
+	return time.UTC
+}
+
+// applyTimeZone converts displayed timestamps to the display zone when enabled.
+func (m *Model) applyTimeZone() {
+	if m.zoneTime {
+		SetDisplayLocation(m.displayZone())
+	} else {
+		SetDisplayLocation(nil)
+	}
+}
+
+// applyTimestampShift shifts displayed timestamps by the clock skew when host time is enabled.
+func (m *Model) applyTimestampShift() {
+	if m.hostTime && m.clockSkewKnown {
+		SetTimestampShift(m.clockSkew)
+	} else {
+		SetTimestampShift(0)
 	}
 }
 
@@ -943,6 +2190,8 @@ func (m *Model) settingsView() string {
 		lines = append(lines, style.Render(line))
 	}
 
+	lines = append(lines, "", itemStyle.Render("  Tail size: "+tailSizeLabel(m.tailSize)+" lines (h/l: change, r: reload history)"))
+
 	help := helpStyle.Render("space: toggle | j/k: move | esc: back")
 	lines = append(lines, "", help)
 
@@ -956,7 +2205,11 @@ func (m *Model) settingsView() string {
 
 func (m Model) View() string {
 	if m.showDeviceSelect {
-		return "\n" + m.deviceList.View()
+		view := "\n" + m.deviceList.View()
+		if m.statusMessage != "" {
+			view += "\n  " + lipgloss.NewStyle().Foreground(GetWarnColor()).Render(m.statusMessage)
+		}
+		return view
 	}
 
 	if !m.ready {
@@ -964,13 +2217,53 @@ func (m Model) View() string {
 	}
 
 	if m.showLogLevel {
-		return "\n" + m.logLevelList.View()
+		return "\n" + m.logLevelList.View() + "\n" + m.levelPreview()
 	}
 
 	if m.showSettings {
 		return m.settingsView()
 	}
 
+	if m.showSources {
+		return m.sourcesView()
+	}
+
+	if m.showDetail {
+		return m.detailView()
+	}
+
+	if m.showHistory {
+		return m.historyView()
+	}
+
+	if m.showParseErrors {
+		return m.parseErrorsView()
+	}
+
+	if m.showPower {
+		return m.powerView()
+	}
+
+	if m.showIntents {
+		return m.intentsView()
+	}
+
+	if m.showJobs {
+		return m.jobsView()
+	}
+
+	if m.showStats {
+		return m.statsView()
+	}
+
+	if m.showTests {
+		return m.testsView()
+	}
+
+	if m.showExportPicker {
+		return m.exportPickerView()
+	}
+
 	headerStyle := lipgloss.NewStyle().
 		BorderStyle(lipgloss.NormalBorder()).
 		BorderTop(true).
@@ -983,26 +2276,18 @@ func (m Model) View() string {
 		Width(m.width)
 
 	filterInfo := ""
-	if len(m.filters) > 0 {
+	if len(m.filters) > 0 && m.filtersOff {
+		offStyle := lipgloss.NewStyle().Foreground(GetWarnColor())
+		filterInfo = " | " + offStyle.Render(fmt.Sprintf("filters off (%d, t: restore)", len(m.filters)))
+	} else if len(m.filters) > 0 {
 		var filterStrs []string
-		for _, f := range m.filters {
-			var filterText string
-			if f.isTag {
-				filterText = "tag:" + f.pattern
-			} else {
-				filterText = f.pattern
-			}
-
-			// Use filter colors for filter badges
-			filterColor := FilterColor(filterText)
-			filterBadge := lipgloss.NewStyle().
-				Background(filterColor).
-				Foreground(lipgloss.AdaptiveColor{Light: "0", Dark: "0"}).
-				Padding(0, 1).
-				Render(filterText)
-			filterStrs = append(filterStrs, filterBadge)
+		for i := range m.filters {
+			filterStrs = append(filterStrs, m.filterBadge(i))
+		}
+		filterInfo = filterBadgesPrefix + strings.Join(filterStrs, " ")
+		if m.spotlight {
+			filterInfo += " " + lipgloss.NewStyle().Foreground(GetAccentColor()).Render("spotlight (L: hide)")
 		}
-		filterInfo = " | filters: " + strings.Join(filterStrs, " ")
 	}
 
 	appInfo := m.appID
@@ -1014,74 +2299,126 @@ func (m Model) View() string {
 	var statusText string
 
 	switch m.appStatus {
-	case "stopped":
+	case logcat.StateStopped, logcat.StateReconnecting:
 		statusStyle = statusStyle.Foreground(lipgloss.AdaptiveColor{Light: "172", Dark: "215"}) // Orange
 		statusText = "not running"
-	case "reconnecting":
-		statusStyle = statusStyle.Foreground(lipgloss.AdaptiveColor{Light: "172", Dark: "215"}) // Orange
-		statusText = "not running"
-	case "error":
+	case logcat.StateError:
 		statusStyle = statusStyle.Foreground(GetErrorColor())
 		statusText = "error"
+		if m.appStatusErr != nil {
+			statusText += ": " + m.appStatusErr.Error()
+		}
+	case logcat.StateWaiting:
+		statusStyle = statusStyle.Foreground(lipgloss.AdaptiveColor{Light: "172", Dark: "215"}) // Orange
+		statusText = "waiting for app to start"
+	}
+	if statusText != "" {
+		statusText += formatSince(m.appStatusSince)
 	}
 
-	deviceStatusStyle := lipgloss.NewStyle()
-	var deviceStatusText string
-	if m.deviceStatus == "disconnected" {
-		deviceStatusStyle = deviceStatusStyle.Foreground(lipgloss.AdaptiveColor{Light: "172", Dark: "215"}) // Orange
-		deviceStatusText = "disconnected"
-	}
-
-	// Get color for current log level
-	var logLevelColor lipgloss.TerminalColor
-	switch m.minLogLevel {
-	case logcat.Verbose:
-		logLevelColor = GetVerboseColor()
-	case logcat.Debug:
-		logLevelColor = GetDebugColor()
-	case logcat.Info:
-		logLevelColor = GetInfoColor()
-	case logcat.Warn:
-		logLevelColor = GetWarnColor()
-	case logcat.Error:
-		logLevelColor = GetErrorColor()
-	case logcat.Fatal:
-		logLevelColor = GetFatalColor()
-	default:
-		logLevelColor = GetVerboseColor()
-	}
-
-	logLevelStyle := lipgloss.NewStyle().Foreground(logLevelColor)
+	deviceStatusStyle := lipgloss.NewStyle()
+	var deviceStatusText string
+	if m.deviceStatus == logcat.StateDisconnected {
+		deviceStatusStyle = deviceStatusStyle.Foreground(lipgloss.AdaptiveColor{Light: "172", Dark: "215"}) // Orange
+		deviceStatusText = "disconnected" + formatSince(m.deviceStatusSince)
+	}
 
 	// Build header lines
 	var headerLines []string
 
-	// First line: log level and filters
-	logLevelLine := fmt.Sprintf("log level: %s%s",
-		logLevelStyle.Render(strings.ToLower(m.minLogLevel.Name())), filterInfo)
+	sortInfo := ""
+	if m.sortMode.kind != sortArrival {
+		sortStyle := lipgloss.NewStyle().Foreground(GetWarnColor()).Bold(true)
+		sortInfo = " | " + sortStyle.Render("sorted by "+m.sortMode.label()) + " (O: live order)"
+	}
+
+	redactInfo := ""
+	if m.redact {
+		redactInfo = " | " + lipgloss.NewStyle().Foreground(GetAccentColor()).Render("redacting")
+	}
+	if m.rawMode {
+		redactInfo += " | " + lipgloss.NewStyle().Foreground(GetWarnColor()).Bold(true).Render("RAW") + " (R: formatted)"
+	}
+	if m.budgetAlert != "" {
+		redactInfo += " | " + lipgloss.NewStyle().Foreground(GetWarnColor()).Bold(true).Render(m.budgetInfo())
+	}
+	if m.search != nil && !m.showSearchInput {
+		redactInfo += " | " + lipgloss.NewStyle().Foreground(GetAccentColor()).Render(m.searchInfo())
+	}
+	if m.recordingMacro {
+		redactInfo += " | " + lipgloss.NewStyle().Foreground(GetErrorColor()).Bold(true).Render("REC") + " (Q: stop)"
+	}
+
+	// First line: follow state, log level and filters
+	logLevelLine := fmt.Sprintf("%s | %s%s%s%s",
+		m.followInfo(), m.levelInfo(), filterInfo, sortInfo, redactInfo)
 	headerLines = append(headerLines, headerStyle.Render(logLevelLine))
 
 	// Second line: app and device info (always show)
-	if !m.showFilter && !m.showClearConfirm {
+	if !m.footerPromptActive() {
 		var infoParts []string
 		appStyle := lipgloss.NewStyle().Foreground(GetAccentColor())
 		deviceStyle := lipgloss.NewStyle().Foreground(GetAccentColor())
-		if m.appID != "" {
+		if m.importName != "" {
+			infoParts = append(infoParts, "imported: "+appStyle.Render(m.importName))
+		} else if m.appID != "" {
 			appInfoText := fmt.Sprintf("app: %s", appStyle.Render(appInfo))
-			if statusText != "" && m.deviceStatus != "disconnected" {
-				appInfoText = fmt.Sprintf("app: %s (%s)", appStyle.Render(appInfo), statusStyle.Render(statusText))
+			if m.appPID != "" {
+				pidLabel := " pid "
+				if strings.Contains(m.appPID, ",") {
+					pidLabel = " pids "
+				}
+				appInfoText += pidLabel + appStyle.Render(m.appPID)
+			}
+			if m.appRestarts > 0 {
+				appInfoText += fmt.Sprintf(", %d restarts (last %s)", m.appRestarts, m.appRestartedAt.Format("15:04:05"))
+			}
+			if statusText != "" && m.deviceStatus != logcat.StateDisconnected {
+				appInfoText += " (" + statusStyle.Render(statusText) + ")"
 			}
 			infoParts = append(infoParts, appInfoText)
 		} else {
 			infoParts = append(infoParts, "app: all")
 		}
-		if m.selectedDevice != "" {
+		if m.multiSource() {
+			shown := 0
+			for _, src := range m.sources {
+				if !src.hidden {
+					shown++
+				}
+			}
+			infoParts = append(infoParts, fmt.Sprintf("sources: %s (D: toggle)", deviceStyle.Render(fmt.Sprintf("%d/%d", shown, len(m.sources)))))
+		} else if m.selectedDevice != "" {
 			deviceInfo := fmt.Sprintf("device: %s", deviceStyle.Render(m.selectedDevice))
 			if deviceStatusText != "" {
 				deviceInfo = fmt.Sprintf("device: %s (%s)", deviceStyle.Render(m.selectedDevice), deviceStatusStyle.Render(deviceStatusText))
 			}
 			infoParts = append(infoParts, deviceInfo)
 		}
+		if m.activity != "" && m.importName == "" {
+			infoParts = append(infoParts, "activity: "+appStyle.Render(m.activityText()))
+		}
+		if m.clockSkewKnown {
+			skewInfo := "clock skew: " + formatSkew(m.clockSkew)
+			if m.hostTime {
+				skewInfo += " (host time)"
+			}
+			infoParts = append(infoParts, skewInfo)
+		}
+		if m.zoneTime {
+			infoParts = append(infoParts, "time zone: "+m.displayZone().String())
+		}
+		if scanErrs := m.scanErrors(); len(scanErrs) > 0 {
+			infoParts = append(infoParts, lipgloss.NewStyle().Foreground(GetErrorColor()).Render("stream read error")+" (U: details)")
+		} else if m.parseFailures > 0 {
+			infoParts = append(infoParts, fmt.Sprintf("parse errors: %d (U)", m.parseFailures))
+		}
+		if len(m.watches) > 0 {
+			infoParts = append(infoParts, m.watchInfo())
+		}
+		if m.importName == "" {
+			infoParts = append(infoParts, m.memoryInfo())
+		}
 		infoLine := strings.Join(infoParts, " | ")
 		headerLines = append(headerLines, headerStyleNoBorder.Render(infoLine))
 	}
@@ -1109,11 +2446,14 @@ func (m Model) View() string {
 
 		filterHelp := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("245")).
-			Render("comma-separated, tag: prefix for tags | enter: apply | esc: cancel")
+			Render("comma-separated, tag: prefix for tags, -/! to exclude, \\-/\\! for a literal - or ! | enter: apply | esc: cancel")
 
 		filterLine := footerStyleNoBorder.Render(filterLabel + m.filterInput.View())
 		helpLine := footerStyle.Render(filterHelp)
 		footer = lipgloss.JoinVertical(lipgloss.Left, filterLine, helpLine)
+	} else if m.showSavePrompt {
+		saveLine, saveHelp := m.savePromptView()
+		footer = lipgloss.JoinVertical(lipgloss.Left, footerStyleNoBorder.Render(saveLine), footerStyle.Render(saveHelp))
 	} else if m.showClearConfirm {
 		clearLabel := lipgloss.NewStyle().
 			Foreground(GetAccentColor()).
@@ -1127,20 +2467,92 @@ func (m Model) View() string {
 		clearLine := footerStyleNoBorder.Render(clearLabel + m.clearInput.View())
 		helpLine := footerStyle.Render(clearHelp)
 		footer = lipgloss.JoinVertical(lipgloss.Left, clearLine, helpLine)
+	} else if m.showAnnotate {
+		noteLabel := lipgloss.NewStyle().
+			Foreground(GetAccentColor()).
+			Bold(true).
+			Render("note: ")
+
+		noteHelp := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245")).
+			Render("enter: save | esc: cancel")
+
+		noteLine := footerStyleNoBorder.Render(noteLabel + m.annotateInput.View())
+		helpLine := footerStyle.Render(noteHelp)
+		footer = lipgloss.JoinVertical(lipgloss.Left, noteLine, helpLine)
+	} else if m.showWatchInput {
+		watchLabel := lipgloss.NewStyle().
+			Foreground(GetAccentColor()).
+			Bold(true).
+			Render("watch: ")
+
+		watchHelp := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245")).
+			Render("label=regex: add or replace | label=: remove | enter: apply | esc: cancel")
+
+		watchLine := footerStyleNoBorder.Render(watchLabel + m.watchInput.View())
+		helpLine := footerStyle.Render(watchHelp)
+		footer = lipgloss.JoinVertical(lipgloss.Left, watchLine, helpLine)
+	} else if m.showUntilInput {
+		untilLabel := lipgloss.NewStyle().
+			Foreground(GetAccentColor()).
+			Bold(true).
+			Render("select until: ")
+
+		untilHelp := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245")).
+			Render("regex on tag or message | enter: extend selection | esc: cancel")
+
+		untilLine := footerStyleNoBorder.Render(untilLabel + m.untilInput.View())
+		helpLine := footerStyle.Render(untilHelp)
+		footer = lipgloss.JoinVertical(lipgloss.Left, untilLine, helpLine)
+	} else if m.showSearchInput {
+		searchLabel := lipgloss.NewStyle().
+			Foreground(GetAccentColor()).
+			Bold(true).
+			Render("/")
+
+		searchHelp := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245")).
+			Render("regex on tag or message, case-insensitive | enter: keep search | esc: clear search")
+
+		searchLine := footerStyleNoBorder.Render(searchLabel + m.searchInput.View())
+		helpLine := footerStyle.Render(searchHelp)
+		footer = lipgloss.JoinVertical(lipgloss.Left, searchLine, helpLine)
+	} else if m.quickToken != "" {
+		tokenStyle := lipgloss.NewStyle().Foreground(GetAccentColor()).Bold(true)
+		tokenInfo := tokenStyle.Render(m.quickToken) + " | f: filter | x: exclude | n: find next | c: copy | esc: cancel"
+		footer = footerStyle.Render(tokenInfo)
 	} else if m.selectionMode {
-		selectionInfo := "SELECTION | j/k: extend | c: copy lines | C: copy messages | esc: cancel"
+		selectionInfo := "SELECTION | j/k: extend | u: until match | %: stack trace | c: copy lines | C: copy messages | */x: flag | g: share gist | p/E: pager/editor | esc: cancel"
 		footer = footerStyle.Render(selectionInfo)
 	} else {
-		baseHelp := "q: quit | c: clear | click: highlight | v: select | l: log level | f: filter | s: settings"
+		baseHelp := "q: quit | c: clear | click: highlight | enter: detail | H: history | W: power | I: intents | w: jobs | m: stats | M: monkey | e: rerun exec | i: tests | Q/@: macro | v: select | z: context | /: search | n/N: next/prev match | a: annotate | y: web search | Y: copy link | #: watch | J/K: bookmarks | S: snapshot | F: follow | l/[/]: log level | f: filter | t: filters on/off | L: spotlight | o: sort | p/E: pager/editor | P: export | ctrl+s: save | b/B: report | s: settings"
 		footer = footerStyle.Render(baseHelp)
 	}
+	if !m.footerPromptActive() {
+		statusStyle := footerStyle.Foreground(GetAccentColor())
+		if m.statusMessage != "" {
+			footer = statusStyle.Render(m.statusMessage)
+		} else if m.highlightedEntry != nil {
+			if note, ok := m.annotations[m.highlightedEntry.ID]; ok {
+				footer = statusStyle.Render("✎ " + note)
+			}
+		}
+	}
 
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		m.viewport.View(),
-		header,
-		footer,
-	)
+	var panes []string
+	if m.stickyHeader {
+		panes = append(panes, m.stickyView())
+	}
+	panes = append(panes, m.logView())
+	if m.showSnapshot {
+		panes = append(panes, m.snapshotView())
+	}
+	if m.execPaneRows() > 0 {
+		panes = append(panes, m.execView())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(panes, header, footer)...)
 }
 
 func (m *Model) updateViewport() {
@@ -1148,6 +2560,7 @@ func (m *Model) updateViewport() {
 }
 
 func (m *Model) updateViewportWithScroll(scrollToBottom bool) {
+	m.countFilterMatches()
 	if m.renderReset || m.renderedUpTo > len(m.parsedEntries) {
 		m.rebuildViewport(scrollToBottom)
 		m.renderReset = false
@@ -1156,7 +2569,7 @@ func (m *Model) updateViewportWithScroll(scrollToBottom bool) {
 
 	if m.renderedUpTo == len(m.parsedEntries) {
 		if scrollToBottom {
-			m.viewport.GotoBottom()
+			m.gotoBottom()
 		}
 		return
 	}
@@ -1164,34 +2577,17 @@ func (m *Model) updateViewportWithScroll(scrollToBottom bool) {
 	m.appendViewport(scrollToBottom)
 }
 
-func joinLines(lines []string) string {
-	if len(lines) == 0 {
-		return ""
-	}
-	var b strings.Builder
-	for i, line := range lines {
-		if i > 0 {
-			b.WriteByte('\n')
-		}
-		b.WriteString(line)
-	}
-	return b.String()
-}
-
 func (m *Model) rebuildViewport(scrollToBottom bool) {
-	lines := make([]string, 0, len(m.parsedEntries))
-	lineEntries := make([]*logcat.Entry, 0, len(m.parsedEntries))
-	entryLineRanges := make(map[*logcat.Entry]entryLineRange, len(m.parsedEntries))
-	maxWidth := 0
-	if m.wrapLines {
-		maxWidth = m.viewport.Width
-	}
-	visible := make([]*logcat.Entry, 0, len(m.parsedEntries))
-	for _, entry := range m.parsedEntries {
-		if entry.Priority >= m.minLogLevel && m.matchesFilters(entry) {
-			visible = append(visible, entry)
-		}
+	anchor := m.renderAnchor
+	if anchor.id == 0 {
+		anchor = m.captureAnchor()
 	}
+	m.renderAnchor = viewAnchor{}
+	lineEntries := make([]*logcat.Entry, 0, len(m.parsedEntries))
+	entryLineRanges := make(map[uint64]entryLineRange, len(m.parsedEntries))
+	maxWidth := m.contentWidth()
+	visible := m.getVisibleEntries()
+	m.lineCache.sync(m.lineStyleState())
 
 	var lastTag string
 	var lastTimestamp string
@@ -1202,9 +2598,6 @@ func (m *Model) rebuildViewport(scrollToBottom bool) {
 	var lastPrevEntry *logcat.Entry
 	var lastEntry *logcat.Entry
 
-	selectedStyle := lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "251", Dark: "240"})
-	highlightStyle := lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "254", Dark: "237"})
-
 	for i, entry := range visible {
 		var prev *logcat.Entry
 		if i > 0 {
@@ -1225,23 +2618,11 @@ func (m *Model) rebuildViewport(scrollToBottom bool) {
 			}
 		}
 
-		var entryLines []string
-		if m.selectedEntries[entry] {
-			entryLines = m.formatEntryWithAllColumnsSelectedLines(entry, showTag, selectedStyle, continuation, maxWidth)
-		} else if entry == m.highlightedEntry {
-			entryLines = m.formatEntryWithAllColumnsSelectedLines(entry, showTag, highlightStyle, continuation, maxWidth)
-		} else {
-			entryLines = FormatEntryLines(entry, lipgloss.NewStyle(), showTag, m.showTimestamp, m.logLevelBackground, m.coloredMessages, continuation, maxWidth)
-		}
-
 		startLine := len(lineEntries)
-		lines = append(lines, entryLines...)
-		for range entryLines {
+		for range m.entryLineCount(entry, showTag, continuation, maxWidth) {
 			lineEntries = append(lineEntries, entry)
 		}
-		if len(entryLines) > 0 {
-			entryLineRanges[entry] = entryLineRange{start: startLine, end: len(lineEntries) - 1}
-		}
+		entryLineRanges[entry.ID] = entryLineRange{start: startLine, end: len(lineEntries) - 1, showTag: showTag, continuation: continuation}
 		lastPrevEntry = lastEntry
 		lastEntry = entry
 		lastTag = entry.Tag
@@ -1252,7 +2633,6 @@ func (m *Model) rebuildViewport(scrollToBottom bool) {
 		lastTID = entry.TID
 	}
 
-	m.renderedLines = lines
 	m.lineEntries = lineEntries
 	m.entryLineRanges = entryLineRanges
 	m.lastRenderedTag = lastTag
@@ -1264,27 +2644,28 @@ func (m *Model) rebuildViewport(scrollToBottom bool) {
 	m.lastRenderedPrev = lastPrevEntry
 	m.lastRenderedLast = lastEntry
 	m.renderedUpTo = len(m.parsedEntries)
-	m.viewportContent = joinLines(lines)
-	m.viewport.SetContent(m.viewportContent)
+	m.setYOffset(m.viewport.YOffset)
 
 	if scrollToBottom {
-		m.viewport.GotoBottom()
+		m.gotoBottom()
+	} else {
+		m.restoreAnchor(anchor)
 	}
+	m.prerenderWindow()
 }
 
 func (m *Model) appendViewport(scrollToBottom bool) {
-	if m.entryLineRanges == nil {
-		m.entryLineRanges = make(map[*logcat.Entry]entryLineRange)
+	if m.sortMode.kind != sortArrival || m.contextEntry != nil {
+		// New entries can land anywhere in a sorted view
+		m.rebuildViewport(scrollToBottom)
+		return
 	}
-	maxWidth := 0
-	if m.wrapLines {
-		maxWidth = m.viewport.Width
+	if m.entryLineRanges == nil {
+		m.entryLineRanges = make(map[uint64]entryLineRange)
 	}
+	maxWidth := m.contentWidth()
+	m.lineCache.sync(m.lineStyleState())
 
-	selectedStyle := lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "251", Dark: "240"})
-	highlightStyle := lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "254", Dark: "237"})
-
-	newLines := make([]string, 0)
 	lastTag := m.lastRenderedTag
 	lastTimestamp := m.lastRenderedTime
 	lastWasContinuation := m.lastRenderedCont
@@ -1297,7 +2678,7 @@ func (m *Model) appendViewport(scrollToBottom bool) {
 	pendingVisible := make([]*logcat.Entry, 0)
 	for i := m.renderedUpTo; i < len(m.parsedEntries); i++ {
 		entry := m.parsedEntries[i]
-		if entry.Priority >= m.minLogLevel && m.matchesFilters(entry) {
+		if m.isVisible(entry) {
 			pendingVisible = append(pendingVisible, entry)
 		}
 	}
@@ -1331,24 +2712,11 @@ func (m *Model) appendViewport(scrollToBottom bool) {
 			}
 		}
 
-		var entryLines []string
-		if m.selectedEntries[entry] {
-			entryLines = m.formatEntryWithAllColumnsSelectedLines(entry, showTag, selectedStyle, continuation, maxWidth)
-		} else if entry == m.highlightedEntry {
-			entryLines = m.formatEntryWithAllColumnsSelectedLines(entry, showTag, highlightStyle, continuation, maxWidth)
-		} else {
-			entryLines = FormatEntryLines(entry, lipgloss.NewStyle(), showTag, m.showTimestamp, m.logLevelBackground, m.coloredMessages, continuation, maxWidth)
-		}
-
 		startLine := len(m.lineEntries)
-		newLines = append(newLines, entryLines...)
-		m.renderedLines = append(m.renderedLines, entryLines...)
-		for range entryLines {
+		for range m.entryLineCount(entry, showTag, continuation, maxWidth) {
 			m.lineEntries = append(m.lineEntries, entry)
 		}
-		if len(entryLines) > 0 {
-			m.entryLineRanges[entry] = entryLineRange{start: startLine, end: len(m.lineEntries) - 1}
-		}
+		m.entryLineRanges[entry.ID] = entryLineRange{start: startLine, end: len(m.lineEntries) - 1, showTag: showTag, continuation: continuation}
 
 		lastPrevEntry = lastEntry
 		lastEntry = entry
@@ -1369,133 +2737,12 @@ func (m *Model) appendViewport(scrollToBottom bool) {
 	m.lastRenderedPrev = lastPrevEntry
 	m.lastRenderedLast = lastEntry
 	m.renderedUpTo = len(m.parsedEntries)
-
-	if len(newLines) > 0 {
-		chunk := joinLines(newLines)
-		if m.viewportContent == "" {
-			m.viewportContent = chunk
-		} else {
-			m.viewportContent += "\n" + chunk
-		}
-		m.viewport.SetContent(m.viewportContent)
-	}
+	m.setYOffset(m.viewport.YOffset)
 
 	if scrollToBottom {
-		m.viewport.GotoBottom()
-	}
-}
-
-// formatEntryWithAllColumnsSelected formats an entry with background applied to all columns while preserving colors.
-// When continuation is true, timestamp, tag, and priority columns are rendered as blank spaces to visually
-// connect entries sharing the same timestamp.
-func (m *Model) formatEntryWithAllColumnsSelectedLines(entry *logcat.Entry, showTag bool, bgStyle lipgloss.Style, continuation bool, maxWidth int) []string {
-	// Get colors for this priority
-	var priorityColor lipgloss.TerminalColor
-	var priorityBgColor lipgloss.TerminalColor
-	switch entry.Priority {
-	case logcat.Verbose:
-		priorityColor = GetVerboseColor()
-		priorityBgColor = GetVerboseBgColor()
-	case logcat.Debug:
-		priorityColor = GetDebugColor()
-		priorityBgColor = GetDebugBgColor()
-	case logcat.Info:
-		priorityColor = GetInfoColor()
-		priorityBgColor = GetInfoBgColor()
-	case logcat.Warn:
-		priorityColor = GetWarnColor()
-		priorityBgColor = GetWarnBgColor()
-	case logcat.Error:
-		priorityColor = GetErrorColor()
-		priorityBgColor = GetErrorBgColor()
-	case logcat.Fatal:
-		priorityColor = GetFatalColor()
-		priorityBgColor = GetFatalBgColor()
-	default:
-		priorityColor = GetVerboseColor()
-		priorityBgColor = GetVerboseBgColor()
+		m.gotoBottom()
 	}
-
-	priorityStyle := lipgloss.NewStyle().Bold(true)
-	if m.logLevelBackground {
-		priorityStyle = priorityStyle.
-			Foreground(lipgloss.AdaptiveColor{Light: "255", Dark: "0"}).
-			Background(priorityBgColor)
-	} else {
-		priorityStyle = priorityStyle.
-			Foreground(priorityColor).
-			Background(bgStyle.GetBackground())
-	}
-
-	tagStyle := lipgloss.NewStyle().
-		Foreground(TagColor(entry.Tag)).
-		Background(bgStyle.GetBackground())
-
-	messageColor := lipgloss.TerminalColor(lipgloss.AdaptiveColor{Light: "0", Dark: "254"})
-	if m.coloredMessages {
-		messageColor = priorityColor
-	}
-	messageStyle := lipgloss.NewStyle().
-		Foreground(messageColor).
-		Background(bgStyle.GetBackground())
-
-	var tagStr string
-	if showTag && !continuation {
-		tagText := truncateString(entry.Tag, TagColumnWidth())
-		tagStr = tagStyle.Render(fmt.Sprintf("%*s", TagColumnWidth(), tagText))
-	} else {
-		tagStr = bgStyle.Render(strings.Repeat(" ", TagColumnWidth()))
-	}
-
-	message := entry.Message
-
-	priorityWidth := len(entry.Priority.String()) + 2
-	priorityStr := bgStyle.Render(strings.Repeat(" ", priorityWidth))
-	if !continuation {
-		priorityStr = priorityStyle.Render(" " + entry.Priority.String() + " ")
-	}
-	if m.showTimestamp {
-		sep := bgStyle.Render(" ")
-		timestampStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "238", Dark: "250"}).
-			Background(bgStyle.GetBackground())
-		timestampContent := strings.Repeat(" ", timestampColumnWidth)
-		if !continuation {
-			timestampContent = fmt.Sprintf("%-*s", timestampColumnWidth, entry.Timestamp)
-		}
-		timestampStr := timestampStyle.Render(timestampContent)
-		prefix := timestampStr + sep + tagStr + sep + priorityStr + sep
-		contPrefix := timestampStyle.Render(strings.Repeat(" ", timestampColumnWidth)) +
-			sep +
-			bgStyle.Render(strings.Repeat(" ", TagColumnWidth())) +
-			sep +
-			bgStyle.Render(strings.Repeat(" ", priorityWidth)) +
-			sep
-		renderOne := func(s string) string { return messageStyle.Render(s) }
-		return wrapWithPrefix(message, renderOne, prefix, contPrefix, maxWidth)
-	}
-
-	sep := bgStyle.Render(" ")
-	prefix := tagStr + sep + priorityStr + sep
-	contPrefix := bgStyle.Render(strings.Repeat(" ", TagColumnWidth())) +
-		sep +
-		bgStyle.Render(strings.Repeat(" ", priorityWidth)) +
-		sep
-	renderOne := func(s string) string { return messageStyle.Render(s) }
-	return wrapWithPrefix(message, renderOne, prefix, contPrefix, maxWidth)
-}
-
-func truncateString(s string, maxLen int) string {
-	if maxLen <= 0 {
-		return ""
-	}
-	if len(s) <= maxLen {
-		return s
-	}
-	if maxLen <= 3 {
-		return s[:maxLen]
-	}
-	return s[:maxLen-3] + "..."
+	m.prerenderWindow()
 }
 
 func (m *Model) parseFilters(filterStr string) {
@@ -1512,9 +2759,32 @@ func (m *Model) parseFilters(filterStr string) {
 		}
 
 		var filter Filter
+		// A leading - or ! excludes matching entries instead, e.g. !tag:Choreographer,
+		// while \- and \! match a literal - or ! at the start, e.g. \-1
+		if strings.HasPrefix(part, `\-`) || strings.HasPrefix(part, `\!`) {
+			part = part[1:]
+		} else if (part[0] == '-' || part[0] == '!') && len(part) > 1 {
+			filter.exclude = true
+			part = part[1:]
+		}
 		if strings.HasPrefix(part, "tag:") {
 			filter.isTag = true
 			part = strings.TrimPrefix(part, "tag:")
+		} else if strings.HasPrefix(part, "flag:") {
+			name := strings.ToLower(strings.TrimPrefix(part, "flag:"))
+			if _, ok := flagNames[name]; !ok {
+				continue
+			}
+			filter.flag = name
+			m.filters = append(m.filters, filter)
+			continue
+		} else if strings.HasPrefix(part, "field:") {
+			name, pattern, ok := strings.Cut(strings.TrimPrefix(part, "field:"), "=")
+			if !ok || name == "" {
+				continue
+			}
+			filter.field = name
+			part = pattern
 		}
 
 		// Unescape commas
@@ -1564,15 +2834,30 @@ func splitByUnescapedComma(s string) []string {
 }
 
 func (m *Model) matchesFilters(entry *logcat.Entry) bool {
-	if len(m.filters) == 0 {
+	if len(m.filters) == 0 || m.filtersOff {
 		return true
 	}
 
-	// Separate tag and message filters
-	var tagFilters, messageFilters []Filter
+	// Separate tag, field and message filters
+	var tagFilters, fieldFilters, messageFilters []Filter
 	for _, filter := range m.filters {
-		if filter.isTag {
+		if filter.disabled {
+			continue
+		}
+		if filter.exclude {
+			// Exclusion filters: entry is hidden if it matches ANY of them
+			if m.filterHits(filter, entry) {
+				return false
+			}
+		} else if filter.flag != "" {
+			// Flag filters: entry must carry ALL filtered flags (AND logic)
+			if m.flags[entry.ID]&flagNames[filter.flag] == 0 {
+				return false
+			}
+		} else if filter.isTag {
 			tagFilters = append(tagFilters, filter)
+		} else if filter.field != "" {
+			fieldFilters = append(fieldFilters, filter)
 		} else {
 			messageFilters = append(messageFilters, filter)
 		}
@@ -1592,6 +2877,14 @@ func (m *Model) matchesFilters(entry *logcat.Entry) bool {
 		}
 	}
 
+	// Field filters: extracted field must exist and match ALL field filters (AND logic)
+	for _, filter := range fieldFilters {
+		value, ok := entry.Field(filter.field)
+		if !ok || !filter.regex.MatchString(value) {
+			return false
+		}
+	}
+
 	// Message filters: entry message must match ALL message filters (AND logic)
 	for _, filter := range messageFilters {
 		if !filter.regex.MatchString(entry.Message) {
@@ -1602,16 +2895,138 @@ func (m *Model) matchesFilters(entry *logcat.Entry) bool {
 	return true
 }
 
+// filterHits reports whether a single filter's pattern matches the entry, ignoring exclusion.
+func (m *Model) filterHits(filter Filter, entry *logcat.Entry) bool {
+	switch {
+	case filter.flag != "":
+		return m.flags[entry.ID]&flagNames[filter.flag] != 0
+	case filter.isTag:
+		return filter.regex.MatchString(entry.Tag)
+	case filter.field != "":
+		value, ok := entry.Field(filter.field)
+		return ok && filter.regex.MatchString(value)
+	default:
+		return filter.regex.MatchString(entry.Message)
+	}
+}
+
+// toggleFilters temporarily turns the active filters off and back on. With no
+// active filters it brings back the set that was replaced most recently.
+func (m *Model) toggleFilters() {
+	switch {
+	case len(m.filters) > 0:
+		m.filtersOff = !m.filtersOff
+		if m.filtersOff {
+			m.recordHistory("filters off")
+		} else {
+			m.recordHistory("filters on")
+		}
+	case len(m.lastFilters) > 0:
+		m.filters = m.lastFilters
+		m.filtersOff = false
+		m.syncFilterInput()
+		m.statusMessage = "restored previous filters"
+		m.recordHistory("filters: " + m.historyFilters())
+	default:
+		m.statusMessage = "no filters to toggle"
+		return
+	}
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// toggleSpotlight switches between hiding entries that don't match the
+// filters and showing them dimmed around the matches.
+func (m *Model) toggleSpotlight() {
+	m.spotlight = !m.spotlight
+	if m.spotlight {
+		m.statusMessage = "spotlight: non-matching entries are dimmed"
+		if len(m.filters) == 0 || m.filtersOff {
+			m.statusMessage += " once filters are set"
+		}
+	} else {
+		m.statusMessage = "spotlight off: non-matching entries are hidden"
+	}
+	m.resetRenderCache()
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// syncFilterInput rewrites the filter input to reflect the active filters.
+func (m *Model) syncFilterInput() {
+	parts := make([]string, 0, len(m.filters))
+	for _, filter := range m.filters {
+		parts = append(parts, filter.String())
+	}
+	m.filterInput.SetValue(strings.Join(parts, ", "))
+}
+
+// recoverFrom handles logcat start errors that have a better answer than quitting:
+// several devices open the device selector, and a stopped app is waited for.
+func (m *Model) recoverFrom(err error) (tea.Cmd, bool) {
+	switch {
+	case errors.Is(err, adb.ErrMultipleDevices) && !m.multiSource():
+		devices, devErr := adb.GetDevices()
+		if devErr == nil {
+			devices, devErr = deviceMatch.Filter(devices)
+		}
+		if devErr != nil {
+			return nil, false
+		}
+		// Start over with a fresh manager; the failed one's channels stay unused
+		m.logManager = logcat.NewManager(m.appID, m.tailSize)
+		m.lineChan = make(chan string, 100)
+		m.devices = devices
+		m.deviceList = newDeviceList(devices, m.checkedDevices)
+		m.showDeviceSelect = true
+		return scheduleDeviceRefresh(), true
+	case errors.Is(err, adb.ErrAppNotRunning) && m.appID != "":
+		m.appStatus = logcat.StateWaiting
+		m.appStatusSince = time.Now()
+		return tea.Batch(waitForApp(m.logManager, m.lineChan), m.startStatusClock()), true
+	}
+	return nil, false
+}
+
+// waitForApp starts logcat once the filtered app is running.
+func waitForApp(manager *logcat.Manager, lineChan chan string) tea.Cmd {
+	return func() tea.Msg {
+		if !manager.WaitForApp() {
+			return nil
+		}
+		return startLogcat(manager, lineChan)()
+	}
+}
+
 func startLogcat(manager *logcat.Manager, lineChan chan string) tea.Cmd {
 	return func() tea.Msg {
 		if err := manager.Start(); err != nil {
 			return errMsg{err}
 		}
 		go manager.ReadLines(lineChan)
+		if pid, procs := manager.Duplicates(); pid != 0 || procs > 0 {
+			return duplicateMsg{pid: pid, logcats: procs}
+		}
 		return nil
 	}
 }
 
+// duplicateMsg reports that the stream was already being read on this machine.
+type duplicateMsg struct {
+	pid     int
+	logcats int
+}
+
+// String describes the duplicate streams as a warning.
+func (d duplicateMsg) String() string {
+	if d.pid != 0 {
+		return fmt.Sprintf("another logdog (pid %d) is already streaming this device and app", d.pid)
+	}
+	if d.logcats == 1 {
+		return "another adb logcat is already running for this device"
+	}
+	return fmt.Sprintf("%d other adb logcat processes are already running for this device", d.logcats)
+}
+
 const maxLogBatch = 200
 
 func waitForLogLine(lineChan <-chan string) tea.Cmd {
@@ -1636,7 +3051,7 @@ func waitForLogLine(lineChan <-chan string) tea.Cmd {
 	}
 }
 
-func waitForStatus(statusChan <-chan string) tea.Cmd {
+func waitForStatus(statusChan <-chan logcat.StatusUpdate) tea.Cmd {
 	return func() tea.Msg {
 		status, ok := <-statusChan
 		if !ok {
@@ -1646,7 +3061,46 @@ func waitForStatus(statusChan <-chan string) tea.Cmd {
 	}
 }
 
-func waitForDeviceStatus(statusChan <-chan string) tea.Cmd {
+// statusDegraded reports whether the app or device is in a state whose age the header shows.
+func (m *Model) statusDegraded() bool {
+	switch m.appStatus {
+	case logcat.StateStopped, logcat.StateReconnecting, logcat.StateError, logcat.StateWaiting:
+		return true
+	}
+	return m.deviceStatus == logcat.StateDisconnected
+}
+
+// startStatusClock starts the status clock's tick unless one is already
+// pending, so repeated status changes don't start parallel tick chains.
+func (m *Model) startStatusClock() tea.Cmd {
+	if m.statusClockScheduled {
+		return nil
+	}
+	m.statusClockScheduled = true
+	return scheduleStatusClock()
+}
+
+func scheduleStatusClock() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return statusClockMsg{}
+	})
+}
+
+// formatSince formats the time elapsed since a status change as "mm:ss ago".
+func formatSince(since time.Time) string {
+	if since.IsZero() {
+		return ""
+	}
+	elapsed := time.Since(since).Round(time.Second)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	minutes := int(elapsed.Minutes())
+	seconds := int(elapsed.Seconds()) % 60
+	return fmt.Sprintf(" %02d:%02d ago", minutes, seconds)
+}
+
+func waitForDeviceStatus(statusChan <-chan logcat.StatusUpdate) tea.Cmd {
 	return func() tea.Msg {
 		status, ok := <-statusChan
 		if !ok {
@@ -1656,47 +3110,116 @@ func waitForDeviceStatus(statusChan <-chan string) tea.Cmd {
 	}
 }
 
-const renderDebounce = 200 * time.Millisecond
+// deviceRefreshInterval is how often the device selector polls adb for changes.
+const deviceRefreshInterval = 2 * time.Second
+
+func scheduleDeviceRefresh() tea.Cmd {
+	return tea.Tick(deviceRefreshInterval, func(time.Time) tea.Msg {
+		devices, err := adb.GetDevices()
+		if err == nil {
+			// Narrow the list as at startup; none matching leaves it empty until one returns
+			devices, _ = deviceMatch.Filter(devices)
+		}
+		return devicesMsg{devices: devices, err: err}
+	})
+}
+
+// deviceOnline reports whether the device with the given serial is online.
+func (m *Model) deviceOnline(serial string) bool {
+	for _, device := range m.devices {
+		if device.Serial == serial {
+			return device.Status == "device"
+		}
+	}
+	return false
+}
+
+func measureClockSkew(manager *logcat.Manager) tea.Cmd {
+	return func() tea.Msg {
+		skew, err := manager.ClockSkew()
+		return clockSkewMsg{skew: skew, err: err}
+	}
+}
+
+// formatSkew renders the device clock offset with millisecond precision and an explicit sign.
+func formatSkew(skew time.Duration) string {
+	sign := "+"
+	if skew < 0 {
+		sign = "-"
+		skew = -skew
+	}
+	return sign + skew.Round(time.Millisecond).String()
+}
+
+const (
+	// busyRenderInterval paces viewport updates while lines keep arriving, or
+	// while an overlay hides the log, so a burst is coalesced into at most one
+	// update per interval.
+	busyRenderInterval = 200 * time.Millisecond
+	// idleRenderInterval is used for the first lines after a quiet period, so
+	// a single line shows up promptly.
+	idleRenderInterval = 50 * time.Millisecond
+	// streamIdleAfter is how long the stream must be quiet to count as idle.
+	streamIdleAfter = time.Second
+)
+
+// requestRender marks the viewport stale and schedules an update for it.
+// Requests made before the update fires are coalesced into it.
+func (m *Model) requestRender() tea.Cmd {
+	m.needsUpdate = true
+	if m.renderScheduled {
+		return nil
+	}
+	m.renderScheduled = true
+	busy, idle := m.renderIntervals()
+	interval := idle
+	if m.streamActive || m.logHidden() {
+		interval = busy
+	}
+	return scheduleViewportUpdate(interval)
+}
+
+// logHidden reports whether an overlay replaces the log view, so updating the
+// viewport can wait until it closes.
+func (m *Model) logHidden() bool {
+	return m.showDeviceSelect || m.showLogLevel || m.showSettings || m.showSources || m.showDetail || m.showHistory || m.showParseErrors || m.showPower || m.showIntents || m.showJobs || m.showStats || m.showTests || m.showExportPicker
+}
 
-func scheduleViewportUpdate() tea.Cmd {
-	return tea.Tick(renderDebounce, func(time.Time) tea.Msg {
+func scheduleViewportUpdate(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
 		return updateViewportMsg{}
 	})
 }
 
+// isVisible reports whether an entry passes the level, source and filter checks.
+func (m *Model) isVisible(entry *logcat.Entry) bool {
+	if entry.Priority == logcat.Unknown && m.hideUnparsed {
+		return false
+	}
+	// Spotlight mode shows entries that don't match the filters too, dimmed
+	return m.levels.has(entry.Priority) && m.sourceVisible(entry) && (m.spotlight || m.matchesFilters(entry))
+}
+
 // getVisibleEntries returns the list of entries currently visible after filtering
 func (m *Model) getVisibleEntries() []*logcat.Entry {
-	visible := make([]*logcat.Entry, 0)
+	if m.contextEntry != nil {
+		return m.contextEntries()
+	}
+	visible := make([]*logcat.Entry, 0, len(m.parsedEntries))
 	for _, entry := range m.parsedEntries {
-		if entry.Priority >= m.minLogLevel && m.matchesFilters(entry) {
+		if m.isVisible(entry) {
 			visible = append(visible, entry)
 		}
 	}
+	sortEntries(visible, m.sortMode)
 	return visible
 }
 
 // handleMouseClick handles clicking on a row
 func (m *Model) handleMouseClick(y int) {
-	// Calculate which entry was clicked
-	// Mouse Y is 1-indexed, and viewport is rendered first (before header)
-	// So viewport starts at Y=1
-
-	// Calculate line within viewport (0-indexed)
-	lineInViewport := y
-
-	// If click is beyond viewport height, ignore (in footer area)
-	if lineInViewport < 0 || lineInViewport >= m.viewport.Height {
-		return
-	}
-
-	// Add viewport scroll offset to get actual line in content
-	clickedLine := lineInViewport + m.viewport.YOffset
-
-	if clickedLine < 0 || clickedLine >= len(m.lineEntries) {
-		return
-	}
-
-	clickedEntry := m.lineEntries[clickedLine]
+	// The viewport is rendered first (before header), so Y maps directly to a
+	// viewport row; wrapped entries span several rows
+	clickedEntry := m.entryAtRow(y)
 	if clickedEntry == nil {
 		return
 	}
@@ -1722,10 +3245,10 @@ func (m *Model) extendSelectionTo(target *logcat.Entry, visible []*logcat.Entry)
 	targetIdx := -1
 
 	for i, entry := range visible {
-		if entry == m.selectionAnchor {
+		if entry.ID == m.selectionAnchor.ID {
 			anchorIdx = i
 		}
-		if entry == target {
+		if entry.ID == target.ID {
 			targetIdx = i
 		}
 	}
@@ -1735,7 +3258,7 @@ func (m *Model) extendSelectionTo(target *logcat.Entry, visible []*logcat.Entry)
 	}
 
 	// Clear and rebuild selection
-	m.selectedEntries = make(map[*logcat.Entry]bool)
+	m.selectedEntries = make(map[uint64]bool)
 
 	start := anchorIdx
 	end := targetIdx
@@ -1744,7 +3267,7 @@ func (m *Model) extendSelectionTo(target *logcat.Entry, visible []*logcat.Entry)
 	}
 
 	for i := start; i <= end; i++ {
-		m.selectedEntries[visible[i]] = true
+		m.selectedEntries[visible[i].ID] = true
 	}
 }
 
@@ -1753,7 +3276,7 @@ func (m *Model) entryLineRange(entry *logcat.Entry) (int, int, bool) {
 		return 0, 0, false
 	}
 	if m.entryLineRanges != nil {
-		if r, ok := m.entryLineRanges[entry]; ok {
+		if r, ok := m.entryLineRanges[entry.ID]; ok {
 			return r.start, r.end, true
 		}
 	}
@@ -1761,7 +3284,7 @@ func (m *Model) entryLineRange(entry *logcat.Entry) (int, int, bool) {
 	start := -1
 	end := -1
 	for i, e := range m.lineEntries {
-		if e == entry {
+		if e.ID == entry.ID {
 			if start == -1 {
 				start = i
 			}
@@ -1791,9 +3314,10 @@ func (m *Model) ensureLineVisible(entryIndex int) {
 	viewportTop := m.viewport.YOffset
 	viewportBottom := m.viewport.YOffset + m.viewport.Height - 1
 
-	// If line is above viewport, scroll up to show it
-	if startLine < viewportTop {
-		m.viewport.SetYOffset(startLine)
+	// If line is above viewport, or the entry is taller than the viewport, scroll to its first line
+	if startLine < viewportTop || endLine-startLine >= m.viewport.Height {
+		m.setYOffset(startLine)
+		return
 	}
 
 	// If line is below viewport, scroll down to show it at the bottom
@@ -1802,8 +3326,22 @@ func (m *Model) ensureLineVisible(entryIndex int) {
 		if newOffset < 0 {
 			newOffset = 0
 		}
-		m.viewport.SetYOffset(newOffset)
+		m.setYOffset(newOffset)
+	}
+}
+
+// jumpToEntry highlights an entry and scrolls to it, e.g. from a panel
+// listing entries. It reports false when the entry is filtered out.
+func (m *Model) jumpToEntry(entry *logcat.Entry) bool {
+	if !m.isVisible(entry) {
+		m.statusMessage = "entry is hidden by the current levels or filters"
+		return false
 	}
+	m.autoScroll = false
+	m.highlightedEntry = entry
+	m.updateViewportWithScroll(false)
+	m.ensureEntryVisible(entry)
+	return true
 }
 
 // ensureEntryVisible scrolls the viewport to ensure the given entry is visible,
@@ -1825,6 +3363,10 @@ func (m *Model) ensureEntryVisible(entry *logcat.Entry) {
 	// If the line is not visible, center it in the viewport
 	if startLine < viewportTop || endLine > viewportBottom {
 		centerLine := startLine + (endLine-startLine)/2
+		if endLine-startLine >= m.viewport.Height {
+			// Too tall to center; show its first line at the top
+			centerLine = startLine + m.viewport.Height/2
+		}
 		// Calculate offset to center the line in the viewport
 		centerOffset := centerLine - m.viewport.Height/2
 
@@ -1842,7 +3384,7 @@ func (m *Model) ensureEntryVisible(entry *logcat.Entry) {
 			centerOffset = maxOffset
 		}
 
-		m.viewport.SetYOffset(centerOffset)
+		m.setYOffset(centerOffset)
 	}
 }
 
@@ -1857,8 +3399,8 @@ func (m *Model) enterSelectionMode() {
 
 	// If there's a highlighted entry, use it as the anchor
 	if m.highlightedEntry != nil {
-		m.selectedEntries = make(map[*logcat.Entry]bool)
-		m.selectedEntries[m.highlightedEntry] = true
+		m.selectedEntries = make(map[uint64]bool)
+		m.selectedEntries[m.highlightedEntry.ID] = true
 		m.selectionAnchor = m.highlightedEntry
 		// Ensure the highlighted entry is visible
 		m.ensureEntryVisible(m.highlightedEntry)
@@ -1867,8 +3409,8 @@ func (m *Model) enterSelectionMode() {
 		visible := m.getVisibleEntries()
 		if len(visible) > 0 {
 			lastEntry := visible[len(visible)-1]
-			m.selectedEntries = make(map[*logcat.Entry]bool)
-			m.selectedEntries[lastEntry] = true
+			m.selectedEntries = make(map[uint64]bool)
+			m.selectedEntries[lastEntry.ID] = true
 			m.selectionAnchor = lastEntry
 			m.highlightedEntry = lastEntry
 			// Ensure the selected entry is visible
@@ -1893,7 +3435,7 @@ func (m *Model) moveHighlightDown() {
 
 	// Find current highlight and move down
 	for i, entry := range visible {
-		if entry == m.highlightedEntry && i < len(visible)-1 {
+		if m.isHighlighted(entry) && i < len(visible)-1 {
 			m.highlightedEntry = visible[i+1]
 			m.ensureLineVisible(i + 1)
 			return
@@ -1917,7 +3459,7 @@ func (m *Model) moveHighlightUp() {
 
 	// Find current highlight and move up
 	for i, entry := range visible {
-		if entry == m.highlightedEntry && i > 0 {
+		if m.isHighlighted(entry) && i > 0 {
 			m.highlightedEntry = visible[i-1]
 			m.ensureLineVisible(i - 1)
 			return
@@ -1937,10 +3479,10 @@ func (m *Model) extendSelectionDown() {
 	lowestIdx := -1
 
 	for i, entry := range visible {
-		if entry == m.selectionAnchor {
+		if entry.ID == m.selectionAnchor.ID {
 			anchorIdx = i
 		}
-		if m.selectedEntries[entry] {
+		if m.selectedEntries[entry.ID] {
 			if highestIdx == -1 || i < highestIdx {
 				highestIdx = i
 			}
@@ -1956,11 +3498,11 @@ func (m *Model) extendSelectionDown() {
 
 	// If we have selection above the anchor, shrink from top first
 	if highestIdx < anchorIdx {
-		delete(m.selectedEntries, visible[highestIdx])
+		delete(m.selectedEntries, visible[highestIdx].ID)
 	} else if lowestIdx < len(visible)-1 {
 		// Otherwise extend downward
 		newEntry := visible[lowestIdx+1]
-		m.selectedEntries[newEntry] = true
+		m.selectedEntries[newEntry.ID] = true
 		// Scroll to ensure the new entry is visible
 		m.ensureLineVisible(lowestIdx + 1)
 	}
@@ -1978,10 +3520,10 @@ func (m *Model) extendSelectionUp() {
 	lowestIdx := -1
 
 	for i, entry := range visible {
-		if entry == m.selectionAnchor {
+		if entry.ID == m.selectionAnchor.ID {
 			anchorIdx = i
 		}
-		if m.selectedEntries[entry] {
+		if m.selectedEntries[entry.ID] {
 			if highestIdx == -1 || i < highestIdx {
 				highestIdx = i
 			}
@@ -1997,11 +3539,11 @@ func (m *Model) extendSelectionUp() {
 
 	// If we have selection below the anchor, shrink from bottom first
 	if lowestIdx > anchorIdx {
-		delete(m.selectedEntries, visible[lowestIdx])
+		delete(m.selectedEntries, visible[lowestIdx].ID)
 	} else if highestIdx > 0 {
 		// Otherwise extend upward
 		newEntry := visible[highestIdx-1]
-		m.selectedEntries[newEntry] = true
+		m.selectedEntries[newEntry.ID] = true
 		// Scroll to ensure the new entry is visible
 		m.ensureLineVisible(highestIdx - 1)
 	}
@@ -2009,27 +3551,38 @@ func (m *Model) extendSelectionUp() {
 
 // clearSelection clears the selection
 func (m *Model) clearSelection() {
-	m.selectedEntries = make(map[*logcat.Entry]bool)
+	m.selectedEntries = make(map[uint64]bool)
 	m.selectionAnchor = nil
 }
 
+// selection returns the selected entries in view order.
+func (m *Model) selection() []*logcat.Entry {
+	visible := m.getVisibleEntries()
+	selected := make([]*logcat.Entry, 0, len(m.selectedEntries))
+	for _, entry := range visible {
+		if m.selectedEntries[entry.ID] {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
+
+// exportEntries returns the selection, or the whole filtered view when nothing is selected.
+func (m *Model) exportEntries() []*logcat.Entry {
+	if m.selectionMode && len(m.selectedEntries) > 0 {
+		return m.selection()
+	}
+	return m.getVisibleEntries()
+}
+
 // copySelectedLines copies selected lines (whole entries) to clipboard
 func (m *Model) copySelectedLines() {
 	if len(m.selectedEntries) == 0 {
 		return
 	}
 
-	// Get selected entries in order
-	visible := m.getVisibleEntries()
-	var lines []string
-	for _, entry := range visible {
-		if m.selectedEntries[entry] {
-			// Copy the whole line without any styling or ANSI codes
-			lines = append(lines, entry.FormatPlain())
-		}
-	}
-
-	clipboard := strings.Join(lines, "\n")
+	// Copy the whole lines without any styling or ANSI codes
+	clipboard := strings.TrimSuffix(m.export(m.selection()), "\n")
 	_ = copyToClipboard(clipboard)
 }
 
@@ -2039,50 +3592,119 @@ func (m *Model) copySelectedMessagesOnly() {
 		return
 	}
 
-	// Get selected entries in order
-	visible := m.getVisibleEntries()
-	var lines []string
-	for _, entry := range visible {
-		if m.selectedEntries[entry] {
-			lines = append(lines, entry.Message)
-		}
+	selected := m.selection()
+	messages := make([]string, len(selected))
+	for i, entry := range selected {
+		messages[i] = entry.Message
 	}
+	_ = copyToClipboard(m.redactText(strings.Join(messages, "\n")))
+}
 
-	clipboard := strings.Join(lines, "\n")
-	_ = copyToClipboard(clipboard)
+func shareGist(token, filename, content string) tea.Cmd {
+	return func() tea.Msg {
+		url, err := gist.Create(token, filename, "Shared from logdog", content)
+		return gistMsg{url: url, err: err}
+	}
 }
 
 func (m Model) PersistPreferences() error {
 	filterPrefs := make([]config.FilterPreference, 0, len(m.filters))
 	for _, filter := range m.filters {
+		if filter.flag != "" {
+			// Flags only exist for the current session
+			continue
+		}
 		filterPrefs = append(filterPrefs, config.FilterPreference{
 			IsTag:   filter.isTag,
+			Field:   filter.field,
+			Exclude: filter.exclude,
 			Pattern: filter.pattern,
 		})
 	}
 
 	logLevelBackground := m.logLevelBackground
 	coloredMessages := m.coloredMessages
+	stickyHeader := m.stickyHeader
 	prefs := config.Preferences{
 		Filters:            filterPrefs,
-		MinLogLevel:        m.minLogLevel.String(),
+		Watches:            m.watchSpecs(),
+		MinLogLevel:        m.levels.lowest().String(),
 		ShowTimestamp:      m.showTimestamp,
+		HostTime:           m.hostTime,
+		ZoneTime:           m.zoneTime,
+		Redact:             m.redact,
+		HideUnparsed:       m.hideUnparsed,
+		PauseOnError:       m.pauseOnError,
+		ExportFormat:       m.exportFormat,
+		BookmarkFatal:      m.bookmarkFatal,
+		BookmarkErrors:     m.bookmarkErrors,
+		LowPower:           m.lowPower,
 		TagColumnWidth:     TagColumnWidth(),
+		TimestampFormat:    TimestampFormat(),
 		WrapLines:          m.wrapLines,
 		LogLevelBackground: &logLevelBackground,
 		ColoredMessages:    &coloredMessages,
+		StickyHeader:       &stickyHeader,
+	}
+
+	if !m.levels.threshold() {
+		prefs.Levels = m.levels.letters()
 	}
 
 	existingPrefs, exists, prefsErr := config.Load()
 	if prefsErr == nil && exists {
 		prefs.TailSize = existingPrefs.TailSize
+		prefs.Sinks = existingPrefs.Sinks
+		prefs.Triggers = existingPrefs.Triggers
+		prefs.Extractors = existingPrefs.Extractors
+		prefs.ReorderWindowMs = existingPrefs.ReorderWindowMs
+		prefs.MemoryLimitMB = existingPrefs.MemoryLimitMB
+		prefs.TimeZone = existingPrefs.TimeZone
+		prefs.Redactions = existingPrefs.Redactions
+		prefs.GistToken = existingPrefs.GistToken
+		prefs.SearchURL = existingPrefs.SearchURL
+		prefs.DeviceAliases = existingPrefs.DeviceAliases
+		prefs.TagBudgets = existingPrefs.TagBudgets
+		prefs.MonkeyEvents = existingPrefs.MonkeyEvents
+		prefs.MonkeySeed = existingPrefs.MonkeySeed
+		prefs.AutosaveOnSignal = existingPrefs.AutosaveOnSignal
+		prefs.RenderIntervalMs = existingPrefs.RenderIntervalMs
+		prefs.ReadIntervalMs = existingPrefs.ReadIntervalMs
+		prefs.SynchronizedOutput = existingPrefs.SynchronizedOutput
+		prefs.ColorTheme = existingPrefs.ColorTheme
+		prefs.TagColors = existingPrefs.TagColors
+		prefs.PriorityStyles = existingPrefs.PriorityStyles
+		prefs.NarrowWidth = existingPrefs.NarrowWidth
+		prefs.ContextLines = existingPrefs.ContextLines
+		prefs.FreezeOnError = existingPrefs.FreezeOnError
+		prefs.PIDCheckIntervalMs = existingPrefs.PIDCheckIntervalMs
+		prefs.PIDPollIntervalMs = existingPrefs.PIDPollIntervalMs
+		prefs.MaxLineLength = existingPrefs.MaxLineLength
 	} else {
 		prefs.TailSize = config.DefaultTailSize
 	}
+	if m.tailSizeChanged {
+		prefs.TailSize = m.tailSize
+	}
 
 	return config.Save(prefs)
 }
 
+// Close releases resources held by the model, such as open sinks, and stops
+// logging. Lines still in flight are discarded.
+func (m Model) Close() {
+	m.stopTests()
+	m.stopExec()
+	if m.logManager != nil {
+		m.stopLogging()
+	}
+	drainLines(m.lineChan)
+	for _, src := range m.sources {
+		drainLines(src.lineChan)
+	}
+	m.forwarder.Close()
+}
+
 // ErrorMessage returns any error message from the model
 func (m Model) ErrorMessage() string {
 	return m.errorMessage
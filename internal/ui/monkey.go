@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// monkeyTag is the tag of the start and end markers of a monkey run.
+const monkeyTag = "monkey"
+
+// monkeyDoneMsg reports the end of a monkey run.
+type monkeyDoneMsg struct {
+	summary string
+	err     error
+}
+
+// monkeyStopMsg reports a failure to stop a monkey run, which keeps running.
+type monkeyStopMsg struct{ err error }
+
+// toggleMonkey starts a monkey run against the app, or stops the one running.
+// Runs are bracketed by marker entries in the log, so the lines they caused
+// are easy to find.
+func (m *Model) toggleMonkey() tea.Cmd {
+	manager := m.logManager
+	if m.monkeyRunning {
+		m.statusMessage = "stopping monkey..."
+		return func() tea.Msg {
+			// The run's own monkeyDoneMsg reports the end
+			if err := manager.StopMonkey(); err != nil {
+				return monkeyStopMsg{err}
+			}
+			return nil
+		}
+	}
+	if m.importName != "" || m.multiSource() {
+		m.statusMessage = "monkey runs need a single device"
+		return nil
+	}
+	if m.appID == "" {
+		m.statusMessage = "monkey runs need an app (--app)"
+		return nil
+	}
+
+	events := m.monkeyEvents
+	if events <= 0 {
+		events = logcat.DefaultMonkeyEvents
+	}
+	seed := m.monkeySeed
+	if seed == 0 {
+		// Random, but logged in the marker so a failing run can be repeated
+		seed = rand.Int64N(1 << 31)
+	}
+	m.monkeyRunning = true
+	m.appendMarker(logcat.Info, monkeyTag, fmt.Sprintf("monkey started: %d events, seed %d", events, seed))
+	m.statusMessage = fmt.Sprintf("monkey running (seed %d, M: stop)", seed)
+	appID := m.appID
+	return func() tea.Msg {
+		summary, err := manager.RunMonkey(appID, events, seed)
+		return monkeyDoneMsg{summary: summary, err: err}
+	}
+}
+
+// finishMonkey adds the end marker of a monkey run.
+func (m *Model) finishMonkey(msg monkeyDoneMsg) {
+	m.monkeyRunning = false
+	text := "monkey finished"
+	if msg.err != nil {
+		text = "monkey failed: " + msg.err.Error()
+	} else if msg.summary != "" {
+		text += ": " + msg.summary
+	}
+	m.appendMarker(logcat.Info, monkeyTag, text)
+	m.statusMessage = text
+}
+
+// appendMarker adds an entry logdog wrote itself to the log, timestamped in
+// device time so it sorts among the device's lines.
+func (m *Model) appendMarker(priority logcat.Priority, tag, message string) *logcat.Entry {
+	at := time.Now()
+	if m.clockSkewKnown {
+		at = at.Add(m.clockSkew)
+	}
+	line := fmt.Sprintf("%s %5d %5d %s %s: %s", at.Format("01-02 15:04:05.000"), 0, 0, priority, tag, message)
+	entry, _ := logcat.ParseLine(line)
+	m.appendEntry(entry)
+	if m.autoScroll {
+		m.updateViewportWithScroll(true)
+	}
+	return entry
+}
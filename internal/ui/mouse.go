@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const doubleClickInterval = 400 * time.Millisecond
+
+// columnDrag tracks an alt-drag rectangle in viewport coordinates.
+type columnDrag struct {
+	startX, startY int
+	endX, endY     int
+}
+
+// handleMouse routes mouse events for the main view. It returns true when the
+// event was consumed and the viewport should be re-rendered.
+func (m *Model) handleMouse(msg tea.MouseMsg) bool {
+	if i := m.filterBadgeAt(msg.X, msg.Y); i >= 0 {
+		m.handleBadgeClick(msg, i)
+		return true
+	}
+	switch {
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft && msg.Alt:
+		m.columnDrag = &columnDrag{startX: msg.X, startY: msg.Y, endX: msg.X, endY: msg.Y}
+		return false
+	case msg.Action == tea.MouseActionMotion && m.columnDrag != nil:
+		m.columnDrag.endX = msg.X
+		m.columnDrag.endY = msg.Y
+		return false
+	case msg.Action == tea.MouseActionRelease && m.columnDrag != nil:
+		m.columnDrag.endX = msg.X
+		m.columnDrag.endY = msg.Y
+		m.copyColumnRegion(*m.columnDrag)
+		m.columnDrag = nil
+		return false
+	case msg.Type == tea.MouseRelease && msg.Button == tea.MouseButtonLeft:
+		m.autoScroll = false
+		if msg.Shift {
+			m.handleShiftClick(msg.Y)
+		} else {
+			m.handleMouseClick(msg.Y)
+			if m.isDoubleClick(msg.X, msg.Y) {
+				m.quickToken = m.tokenAt(msg.X, msg.Y)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// handleShiftClick extends the selection from the highlighted entry to the clicked entry,
+// entering selection mode when needed.
+func (m *Model) handleShiftClick(y int) {
+	if !m.selectionMode {
+		if m.highlightedEntry == nil {
+			m.handleMouseClick(y)
+			return
+		}
+		m.enterSelectionMode()
+	}
+	m.handleMouseClick(y)
+}
+
+// copyColumnRegion copies the text inside the dragged rectangle, one row per line.
+func (m *Model) copyColumnRegion(drag columnDrag) {
+	top, bottom := drag.startY, drag.endY
+	if top > bottom {
+		top, bottom = bottom, top
+	}
+	left, right := drag.startX, drag.endX
+	if left > right {
+		left, right = right, left
+	}
+	var rows []string
+	for y := top; y <= bottom; y++ {
+		line, ok := m.contentLine(y)
+		if !ok {
+			continue
+		}
+		cell := ansi.Strip(ansi.Cut(m.renderedLine(line), left, right+1))
+		rows = append(rows, strings.TrimRight(cell, " "))
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	if err := copyToClipboard(m.redactText(strings.Join(rows, "\n"))); err != nil {
+		m.statusMessage = "copy failed: " + err.Error()
+		return
+	}
+	m.statusMessage = fmt.Sprintf("copied %d×%d region", right-left+1, len(rows))
+}
+
+// isWordChar reports whether r belongs to a token selected by double-click.
+// Besides letters and digits this keeps identifiers, hosts, paths and IDs intact.
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_-.:/@#$", r)
+}
+
+// tokenAt returns the word under the given viewport cell.
+func (m *Model) tokenAt(x, y int) string {
+	line, ok := m.contentLine(y)
+	if !ok {
+		return ""
+	}
+	runes := []rune(ansi.Strip(m.renderedLine(line)))
+	if x < 0 || x >= len(runes) || !isWordChar(runes[x]) {
+		return ""
+	}
+	start, end := x, x
+	for start > 0 && isWordChar(runes[start-1]) {
+		start--
+	}
+	for end < len(runes)-1 && isWordChar(runes[end+1]) {
+		end++
+	}
+	// Trailing punctuation such as "id=42:" or "done." is rarely part of the token
+	token := strings.TrimRight(string(runes[start:end+1]), ".:")
+	return token
+}
+
+// isDoubleClick records the click and reports whether it completes a double-click.
+func (m *Model) isDoubleClick(x, y int) bool {
+	now := time.Now()
+	double := x == m.lastClickX && y == m.lastClickY && now.Sub(m.lastClickAt) <= doubleClickInterval
+	m.lastClickX, m.lastClickY, m.lastClickAt = x, y, now
+	if double {
+		m.lastClickAt = time.Time{}
+	}
+	return double
+}
+
+// applyTokenAction runs a quick action on the double-clicked token.
+// It returns false when the key is not a token action.
+func (m *Model) applyTokenAction(key string) bool {
+	token := m.quickToken
+	switch key {
+	case "f", "x":
+		regex, err := regexp.Compile("(?i)" + regexp.QuoteMeta(token))
+		if err != nil {
+			return true
+		}
+		m.filtersOff = false
+		m.filters = append(m.filters, Filter{
+			exclude: key == "x",
+			pattern: regexp.QuoteMeta(token),
+			regex:   regex,
+		})
+		m.syncFilterInput()
+		m.recordHistory("filters: " + m.historyFilters())
+		m.lineCache.clear()
+		m.resetRenderCache()
+		m.updateViewportWithScroll(m.autoScroll)
+	case "n":
+		m.findNext(token)
+		m.renderReset = true
+		m.updateViewportWithScroll(false)
+	case "c":
+		if err := copyToClipboard(m.redactText(token)); err != nil {
+			m.statusMessage = "copy failed: " + err.Error()
+		}
+	case "esc":
+	default:
+		m.quickToken = ""
+		return false
+	}
+	m.quickToken = ""
+	return true
+}
+
+// findNext highlights the next visible entry after the highlight whose message contains text.
+func (m *Model) findNext(text string) {
+	visible := m.getVisibleEntries()
+	start := 0
+	for i, entry := range visible {
+		if m.isHighlighted(entry) {
+			start = i + 1
+			break
+		}
+	}
+	needle := strings.ToLower(text)
+	for n := 0; n < len(visible); n++ {
+		entry := visible[(start+n)%len(visible)]
+		if strings.Contains(strings.ToLower(entry.Message), needle) {
+			m.autoScroll = false
+			m.highlightedEntry = entry
+			m.ensureEntryVisible(entry)
+			return
+		}
+	}
+	m.statusMessage = "no match for " + text
+}
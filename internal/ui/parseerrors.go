@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// maxParseSamples caps the unparsed lines kept for the parse errors panel;
+// the oldest samples are dropped first.
+const maxParseSamples = 100
+
+// recordParseFailure counts an entry that is still unparsed once continuation
+// lines have been attached, and keeps it as a sample.
+func (m *Model) recordParseFailure(entry *logcat.Entry) {
+	// Attached continuation lines take their entry's priority
+	if !entry.Unparsed || entry.Priority != logcat.Unknown || logcat.UnparsedReason(entry.Raw) == "" {
+		return
+	}
+	m.parseFailures++
+	m.parseSamples = append(m.parseSamples, entry)
+	if len(m.parseSamples) > maxParseSamples {
+		m.parseSamples = m.parseSamples[len(m.parseSamples)-maxParseSamples:]
+	}
+}
+
+// scanErrors lists the errors that stopped reading a logcat stream, by source.
+func (m *Model) scanErrors() []string {
+	var errs []string
+	if len(m.sources) == 0 {
+		if m.logManager == nil {
+			return nil
+		}
+		if err := m.logManager.ScanError(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		return errs
+	}
+	for _, src := range m.sources {
+		if err := src.manager.ScanError(); err != nil {
+			errs = append(errs, src.label+": "+err.Error())
+		}
+	}
+	return errs
+}
+
+// jumpToParseFailure shows an unparsed entry in the log, turning unparsed
+// lines back on if they are hidden.
+func (m *Model) jumpToParseFailure(entry *logcat.Entry) {
+	if m.hideUnparsed {
+		m.hideUnparsed = false
+		m.statusMessage = "showing unparsed lines"
+		m.resetRenderCache()
+	}
+	m.jumpToEntry(entry)
+}
+
+// parseErrorsView renders the parse errors panel, newest sample first.
+func (m *Model) parseErrorsView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle := itemStyle.Foreground(GetAccentColor()).Bold(true)
+	detailStyle := lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("245"))
+	errorStyle := lipgloss.NewStyle().PaddingLeft(1).Foreground(GetErrorColor())
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	lines := []string{
+		titleStyle.Render("Parse errors"),
+		itemStyle.Render(fmt.Sprintf("%d of %d lines could not be read as threadtime", m.parseFailures, m.nextEntryID)),
+	}
+	scanErrs := m.scanErrors()
+	for _, err := range scanErrs {
+		lines = append(lines, errorStyle.Render("stream stopped: "+err))
+	}
+	if len(scanErrs) == 0 {
+		lines = append(lines, itemStyle.Render("no stream read errors"))
+	}
+	lines = append(lines, "")
+
+	// Two rows per sample; scroll so the cursor stays inside the panel
+	rows := max(1, (m.height-12-len(scanErrs))/2)
+	pos := len(m.parseSamples) - 1 - m.parseErrorsIndex
+	first := max(0, pos-rows+1)
+	for n := first; n < len(m.parseSamples) && n < first+rows; n++ {
+		i := len(m.parseSamples) - 1 - n
+		entry := m.parseSamples[i]
+		cursor := " "
+		style := itemStyle
+		if i == m.parseErrorsIndex {
+			cursor = "›"
+			style = selectedStyle
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%s %s", cursor, truncate(entry.Raw, max(0, m.width-10)))))
+		lines = append(lines, detailStyle.Render(logcat.UnparsedReason(entry.Raw)))
+	}
+	if len(m.parseSamples) == 0 {
+		lines = append(lines, itemStyle.Render("no unparsed lines"))
+	}
+	lines = append(lines, "", helpStyle.Render("enter: show in log | j/k: move | esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// handleParseErrorsKey handles keys while the parse errors panel is open. The
+// list is shown newest first, so moving down steps back in time.
+func (m *Model) handleParseErrorsKey(key string) {
+	switch key {
+	case "esc", "U":
+		m.showParseErrors = false
+	case "j", "down":
+		if m.parseErrorsIndex > 0 {
+			m.parseErrorsIndex--
+		}
+	case "k", "up":
+		if m.parseErrorsIndex < len(m.parseSamples)-1 {
+			m.parseErrorsIndex++
+		}
+	case "enter":
+		if len(m.parseSamples) > 0 {
+			m.showParseErrors = false
+			m.jumpToParseFailure(m.parseSamples[m.parseErrorsIndex])
+		}
+	}
+}
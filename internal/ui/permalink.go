@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// permalinkPattern matches a session file followed by an entry reference,
+// e.g. "capture.log#entry-48211".
+var permalinkPattern = regexp.MustCompile(`^(.+)#entry-([1-9][0-9]*)$`)
+
+// SplitPermalink splits a permalink into the session file and the entry's
+// number in it. It reports false when ref has no entry reference.
+func SplitPermalink(ref string) (string, int, bool) {
+	match := permalinkPattern.FindStringSubmatch(ref)
+	if match == nil {
+		return "", 0, false
+	}
+	entry, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], entry, true
+}
+
+// SetImportEntry makes NewModel highlight the entry with the given number in
+// the import, as referenced by a permalink.
+func SetImportEntry(entry int) {
+	if imported != nil {
+		imported.entry = entry
+	}
+}
+
+// copyPermalink copies a reference to the highlighted entry that others can
+// open with --import. Entries are numbered in file order, as loading the file
+// numbers them. A live session has no file yet, so its raw log is saved to
+// the working directory first and rewritten on later links, keeping earlier
+// numbers valid.
+func (m *Model) copyPermalink() {
+	if m.highlightedEntry == nil {
+		m.statusMessage = "highlight an entry to link to it"
+		return
+	}
+	name, number := m.importName, int(m.highlightedEntry.ID)
+	if name == "" || name == "stdin" {
+		var err error
+		name, number, err = m.saveSessionFile(m.highlightedEntry)
+		if err != nil {
+			m.statusMessage = "saving session failed: " + err.Error()
+			return
+		}
+	}
+	link := fmt.Sprintf("%s#entry-%d", name, number)
+	if err := copyToClipboard(link); err != nil {
+		m.statusMessage = "copy failed: " + err.Error() + " (" + link + ")"
+		return
+	}
+	m.statusMessage = "copied " + link
+}
+
+// saveSessionFile writes every entry's raw line to the session file and
+// returns its name and the number target gets when the file is loaded.
+// Blank lines are left out since loading skips them.
+func (m *Model) saveSessionFile(target *logcat.Entry) (string, int, error) {
+	if m.sessionPath == "" {
+		m.sessionPath = fmt.Sprintf("logdog-session-%s.log", m.sessionStart.Format("20060102-150405"))
+	}
+	entries := make([]*logcat.Entry, 0, len(m.parsedEntries))
+	number := 0
+	for _, entry := range m.parsedEntries {
+		if strings.TrimSpace(entry.Raw) == "" {
+			continue
+		}
+		entries = append(entries, entry)
+		if entry.ID == target.ID {
+			number = len(entries)
+		}
+	}
+	if number == 0 {
+		return "", 0, errors.New("entry is not in the log buffer")
+	}
+	if err := os.WriteFile(m.sessionPath, []byte(m.exportAs(rawExporter(), entries)), 0o644); err != nil {
+		return "", 0, err
+	}
+	if !slices.Contains(m.exportPaths, m.sessionPath) {
+		m.exportPaths = append(m.exportPaths, m.sessionPath)
+	}
+	return filepath.Base(m.sessionPath), number, nil
+}
+
+// importEntryNamed returns the imported entry with the given permalink number.
+func (m *Model) importEntryNamed(number int) *logcat.Entry {
+	for _, entry := range m.parsedEntries {
+		if entry.ID == uint64(number) {
+			return entry
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// maxWakeLockLeaks caps the leaked wakelocks listed in the power panel.
+const maxWakeLockLeaks = 10
+
+// heldWakeLock is a wakelock acquired and not yet released.
+type heldWakeLock struct {
+	name  string
+	pid   string
+	since time.Time
+}
+
+// batteryReading is a battery level at a point in log time.
+type batteryReading struct {
+	level int
+	at    time.Time
+}
+
+// powerState tracks wakelocks and battery levels seen in the log.
+type powerState struct {
+	held     map[string]heldWakeLock
+	leaks    []heldWakeLock
+	released int
+	first    *batteryReading
+	last     *batteryReading
+	// now is the latest log time seen, which durations are measured against
+	// so they don't depend on the device clock matching the host's
+	now time.Time
+}
+
+// trackPower updates the wakelock and battery state from an entry.
+func (m *Model) trackPower(entry *logcat.Entry) {
+	if !entry.Time.IsZero() && entry.Time.After(m.power.now) {
+		m.power.now = entry.Time
+	}
+	event, ok := logcat.ParsePowerEvent(entry)
+	if !ok {
+		return
+	}
+	switch event.Kind {
+	case logcat.WakeLockAcquired:
+		// Wakelocks are logged by system_server with the holder's PID
+		if m.appPID != "" && !slices.Contains(strings.Split(m.appPID, ","), event.PID) {
+			return
+		}
+		if m.power.held == nil {
+			m.power.held = make(map[string]heldWakeLock)
+		}
+		m.power.held[event.Lock] = heldWakeLock{name: event.Name, pid: event.PID, since: entry.Time}
+	case logcat.WakeLockReleased:
+		if _, ok := m.power.held[event.Lock]; ok {
+			delete(m.power.held, event.Lock)
+			m.power.released++
+		}
+	case logcat.WakeLockLeaked:
+		m.power.leaks = append(m.power.leaks, heldWakeLock{name: event.Name, pid: event.PID, since: entry.Time})
+		if len(m.power.leaks) > maxWakeLockLeaks {
+			m.power.leaks = m.power.leaks[len(m.power.leaks)-maxWakeLockLeaks:]
+		}
+	case logcat.BatteryLevel:
+		reading := &batteryReading{level: event.Level, at: entry.Time}
+		if m.power.first == nil {
+			m.power.first = reading
+		}
+		m.power.last = reading
+	}
+}
+
+// heldFor returns how long a wakelock has been held, in log time.
+func (m *Model) heldFor(lock heldWakeLock) string {
+	if lock.since.IsZero() || m.power.now.IsZero() {
+		return "?"
+	}
+	return m.power.now.Sub(lock.since).Round(time.Second).String()
+}
+
+// batteryText describes the battery level and its change since the first reading.
+func (m *Model) batteryText() string {
+	first, last := m.power.first, m.power.last
+	if last == nil {
+		return "no battery levels logged"
+	}
+	text := fmt.Sprintf("battery %d%%", last.level)
+	if first != last {
+		text += fmt.Sprintf(" (%+d%% since %s", last.level-first.level, first.at.Format("15:04:05"))
+		if !first.at.IsZero() && !last.at.IsZero() {
+			text += ", " + last.at.Sub(first.at).Round(time.Second).String()
+		}
+		text += ")"
+	}
+	return text
+}
+
+// powerView renders the power panel: held wakelocks, longest held first,
+// recent leaks and the battery level.
+func (m *Model) powerView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	sectionStyle := itemStyle.Bold(true)
+	warnStyle := itemStyle.Foreground(GetWarnColor())
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	held := make([]heldWakeLock, 0, len(m.power.held))
+	for _, lock := range m.power.held {
+		held = append(held, lock)
+	}
+	sort.Slice(held, func(i, j int) bool { return held[i].since.Before(held[j].since) })
+
+	rows := max(1, m.height-14-len(m.power.leaks))
+	lines := []string{
+		titleStyle.Render("Power"),
+		itemStyle.Render(m.batteryText()),
+		"",
+		sectionStyle.Render(fmt.Sprintf("Held wakelocks: %d (%d released)", len(held), m.power.released)),
+	}
+	for i, lock := range held {
+		if i == rows {
+			lines = append(lines, itemStyle.Render(fmt.Sprintf("  … %d more", len(held)-rows)))
+			break
+		}
+		line := fmt.Sprintf("  %-10s %s", m.heldFor(lock), lock.name)
+		if lock.pid != "" {
+			line += "  pid " + lock.pid
+		}
+		lines = append(lines, itemStyle.Render(truncate(line, max(0, m.width-8))))
+	}
+	if len(m.power.leaks) > 0 {
+		lines = append(lines, "", sectionStyle.Render("Leaked wakelocks"))
+		for _, lock := range m.power.leaks {
+			line := fmt.Sprintf("  %s  %s  pid %s", lock.since.Format("15:04:05"), lock.name, lock.pid)
+			lines = append(lines, warnStyle.Render(truncate(line, max(0, m.width-8))))
+		}
+	}
+	lines = append(lines, "", helpStyle.Render("Acquire and release lines come from system_server with PowerManager debug logging on, so they only show without --app"))
+	lines = append(lines, helpStyle.Render("esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+const (
+	// lowPowerRefreshInterval paces viewport updates and reads in low-power mode.
+	lowPowerRefreshInterval = 500 * time.Millisecond
+	// lowPowerActivityInterval is how often the foreground activity is polled in low-power mode.
+	lowPowerActivityInterval = 10 * time.Second
+)
+
+// renderIntervals returns how often the viewport updates while lines stream
+// in and after the stream was idle. The idle interval is never the slower one.
+func (m *Model) renderIntervals() (busy, idle time.Duration) {
+	if m.lowPower {
+		return lowPowerRefreshInterval, lowPowerRefreshInterval
+	}
+	busy = busyRenderInterval
+	if m.renderInterval > 0 {
+		busy = m.renderInterval
+	}
+	return busy, min(busy, idleRenderInterval)
+}
+
+// applyReadInterval sets how often logcat readers hand lines to the UI.
+func (m *Model) applyReadInterval() {
+	interval := m.readInterval
+	if m.lowPower {
+		interval = lowPowerRefreshInterval
+	}
+	logcat.SetReadInterval(interval)
+}
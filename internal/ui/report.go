@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+type reportMsg struct {
+	path string
+	err  error
+}
+
+// reportFile is one file in a report bundle.
+type reportFile struct {
+	name string
+	data []byte
+}
+
+// createReport collects the filtered view, the raw buffer and device details
+// into a zip in the working directory, for attaching to a bug report.
+// Device queries run in the returned command since adb can be slow.
+func (m *Model) createReport(screenshot bool) tea.Cmd {
+	files := []reportFile{
+		{"filtered." + m.exporter().Extension(), []byte(m.export(m.exportEntries()))},
+		{"raw.log", []byte(m.exportAs(rawExporter(), m.parsedEntries))},
+		{"history.txt", []byte(strings.Join(m.historyLines(), "\n") + "\n")},
+	}
+	manager := m.logManager
+	appID := m.appID
+	withDevice := m.importName == ""
+
+	return func() tea.Msg {
+		var notes []string
+		if !withDevice {
+			// An import has no device to describe
+		} else if info, err := manager.DeviceInfo(); err != nil {
+			notes = append(notes, err.Error())
+		} else {
+			files = append(files, reportFile{"device.txt", []byte(info)})
+		}
+		if appID != "" {
+			if version, err := manager.PackageVersion(appID); err != nil {
+				notes = append(notes, err.Error())
+			} else {
+				files = append(files, reportFile{"app.txt", []byte(appID + "\n" + version)})
+			}
+		}
+		if screenshot && withDevice {
+			if png, err := manager.Screenshot(); err != nil {
+				notes = append(notes, err.Error())
+			} else {
+				files = append(files, reportFile{"screenshot.png", png})
+			}
+		}
+		if len(notes) > 0 {
+			// Missing pieces are noted rather than failing the whole bundle
+			files = append(files, reportFile{"errors.txt", []byte(strings.Join(notes, "\n") + "\n")})
+		}
+
+		path := fmt.Sprintf("logdog-report-%s.zip", time.Now().Format("20060102-150405"))
+		return reportMsg{path: path, err: writeZip(path, files)}
+	}
+}
+
+// rawLines returns the raw logcat lines of entries, unfiltered.
+func rawLines(entries []*logcat.Entry) []string {
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, entry.Raw)
+	}
+	return lines
+}
+
+func writeZip(path string, files []reportFile) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create report: %w", err)
+	}
+	zw := zip.NewWriter(out)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err == nil {
+			_, err = w.Write(file.data)
+		}
+		if err != nil {
+			zw.Close()
+			out.Close()
+			os.Remove(path)
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(path)
+		return fmt.Errorf("write report: %w", err)
+	}
+	return out.Close()
+}
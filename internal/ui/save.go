@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// startSavePrompt opens the footer prompt for saving the log to a file.
+// Choices from the last save are kept.
+func (m *Model) startSavePrompt() {
+	m.showSavePrompt = true
+}
+
+// handleSaveKey picks what to save and in which format, and saves on enter.
+func (m *Model) handleSaveKey(key string) {
+	switch key {
+	case "esc":
+		m.showSavePrompt = false
+	case "v":
+		m.saveAll = false
+	case "a":
+		m.saveAll = true
+	case "p":
+		m.saveRaw = false
+	case "r":
+		m.saveRaw = true
+	case "enter":
+		m.showSavePrompt = false
+		m.saveSession()
+	}
+}
+
+// saveSession writes the visible entries, or all of them, to a timestamped
+// file in the working directory, as formatted plain lines or raw logcat lines.
+func (m *Model) saveSession() {
+	name := "plain"
+	if m.saveRaw {
+		name = "raw"
+	}
+	exporter, _ := logcat.ExporterNamed(name)
+	m.saveEntries(exporter, m.saveAll)
+}
+
+// saveEntries writes the visible entries, or all of them, to a timestamped
+// file in the working directory in the exporter's format.
+func (m *Model) saveEntries(exporter logcat.Exporter, all bool) {
+	entries, scope := m.getVisibleEntries(), "filtered"
+	if all {
+		entries, scope = m.parsedEntries, "all"
+	}
+	if len(entries) == 0 {
+		m.statusMessage = "nothing to save"
+		return
+	}
+
+	path := fmt.Sprintf("logdog-%s-%s.%s", time.Now().Format("20060102-150405"), scope, exporter.Extension())
+	if err := os.WriteFile(path, []byte(m.exportAs(exporter, entries)), 0o644); err != nil {
+		m.statusMessage = "save failed: " + err.Error()
+		return
+	}
+	m.exportPaths = append(m.exportPaths, path)
+	m.statusMessage = fmt.Sprintf("saved %d entries to %s", len(entries), path)
+}
+
+// savePromptView renders the save prompt's choices, the current ones highlighted.
+func (m *Model) savePromptView() (string, string) {
+	labelStyle := lipgloss.NewStyle().Foreground(GetAccentColor()).Bold(true)
+	chosenStyle := lipgloss.NewStyle().Foreground(GetAccentColor()).Underline(true)
+	otherStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	choice := func(text string, chosen bool) string {
+		if chosen {
+			return chosenStyle.Render(text)
+		}
+		return otherStyle.Render(text)
+	}
+
+	line := labelStyle.Render("save ") +
+		choice(fmt.Sprintf("v: visible (%d)", len(m.getVisibleEntries())), !m.saveAll) + " " +
+		choice(fmt.Sprintf("a: all (%d)", len(m.parsedEntries)), m.saveAll) + otherStyle.Render(" as ") +
+		choice("p: plain", !m.saveRaw) + " " +
+		choice("r: raw lines", m.saveRaw)
+	help := otherStyle.Render("enter: save to a timestamped file | esc: cancel")
+	return line, help
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// searchMatchLineStyle marks entries matching the search.
+var searchMatchLineStyle = lipgloss.NewStyle().Background(lipgloss.AdaptiveColor{Light: "230", Dark: "58"})
+
+// startSearch opens the search prompt on the current search. Matching
+// entries are highlighted and the first one from the highlight is jumped to
+// as the query is typed.
+func (m *Model) startSearch() {
+	if m.searchInput.Placeholder == "" {
+		m.searchInput = textinput.New()
+		m.searchInput.Placeholder = "regex on tag or message, e.g. timeout|refused"
+		m.searchInput.CharLimit = 500
+		m.searchInput.Width = 80
+	}
+	m.searchInput.SetValue(m.searchText)
+	m.searchInput.CursorEnd()
+	m.searchInput.Focus()
+	m.showSearchInput = true
+	m.searchOrigin = m.highlightedEntry
+	m.searchFollowed = m.autoScroll
+}
+
+// updateSearch searches for the prompt's text as it changes, jumping to the
+// first match at or after where the search started.
+func (m *Model) updateSearch() {
+	text := m.searchInput.Value()
+	if text == m.searchText {
+		return
+	}
+	m.setSearch(text)
+	if m.search == nil {
+		m.restoreSearchOrigin()
+		return
+	}
+	visible := m.getVisibleEntries()
+	start := 0
+	if m.searchOrigin != nil {
+		start = max(0, visibleIndex(visible, m.searchOrigin))
+	} else if top := m.entryAtRow(0); top != nil {
+		start = max(0, visibleIndex(visible, top))
+	}
+	if !m.jumpToMatchFrom(visible, start, 1) {
+		m.statusMessage = "no match for " + text
+		m.restoreSearchOrigin()
+	}
+}
+
+// applySearch closes the prompt and keeps the search for n/N.
+func (m *Model) applySearch() {
+	m.showSearchInput = false
+	m.searchInput.Blur()
+	if m.search != nil {
+		m.statusMessage = m.matchPosition()
+	}
+}
+
+// cancelSearch closes the prompt, clears the search and returns to where it started.
+func (m *Model) cancelSearch() {
+	m.showSearchInput = false
+	m.searchInput.Blur()
+	m.setSearch("")
+	m.restoreSearchOrigin()
+}
+
+// restoreSearchOrigin moves the highlight back to where the search started,
+// following again if the view was following then.
+func (m *Model) restoreSearchOrigin() {
+	m.highlightedEntry = m.searchOrigin
+	if m.searchFollowed {
+		m.autoScroll = true
+		m.releaseHeld()
+		return
+	}
+	if m.searchOrigin != nil {
+		m.ensureEntryVisible(m.searchOrigin)
+	}
+}
+
+// setSearch sets the search to a case-insensitive regex. Text that isn't a
+// valid regex is searched for literally, so partial input like "foo(" while
+// typing still finds something.
+func (m *Model) setSearch(text string) {
+	m.searchText = text
+	m.search = nil
+	if text != "" {
+		regex, err := regexp.Compile("(?i)" + text)
+		if err != nil {
+			regex = regexp.MustCompile("(?i)" + regexp.QuoteMeta(text))
+		}
+		m.search = regex
+	}
+	m.renderReset = true
+}
+
+// searchMatches reports whether the entry's tag or message matches the
+// search, or its raw line in raw mode.
+func (m *Model) searchMatches(entry *logcat.Entry) bool {
+	if m.search == nil {
+		return false
+	}
+	if m.rawMode {
+		return m.search.MatchString(entry.Raw)
+	}
+	return m.search.MatchString(entry.Message) || m.search.MatchString(entry.Tag)
+}
+
+// jumpToMatch highlights the next visible match after the highlight, or the
+// previous one when step is negative, wrapping around. Filters are left as
+// they are, so only visible entries are searched.
+func (m *Model) jumpToMatch(step int) {
+	if m.search == nil {
+		m.statusMessage = "no search (/: search)"
+		return
+	}
+	visible := m.getVisibleEntries()
+	start := -1
+	if step < 0 {
+		start = len(visible)
+	}
+	if m.highlightedEntry != nil {
+		if i := visibleIndex(visible, m.highlightedEntry); i >= 0 {
+			start = i
+		}
+	}
+	if !m.jumpToMatchFrom(visible, start+step, step) {
+		m.statusMessage = "no match for " + m.searchText
+		return
+	}
+	m.statusMessage = m.matchPosition()
+}
+
+// jumpToMatchFrom highlights the first match from visible[start] in the
+// direction of step, wrapping around, and reports whether there was one.
+func (m *Model) jumpToMatchFrom(visible []*logcat.Entry, start, step int) bool {
+	for n := 0; n < len(visible); n++ {
+		i := ((start+n*step)%len(visible) + len(visible)) % len(visible)
+		if entry := visible[i]; m.searchMatches(entry) {
+			m.autoScroll = false
+			m.highlightedEntry = entry
+			m.ensureEntryVisible(entry)
+			return true
+		}
+	}
+	return false
+}
+
+// matchPosition describes the highlighted match's position among all
+// visible matches, e.g. "match 3 of 12".
+func (m *Model) matchPosition() string {
+	position, total := 0, 0
+	for _, entry := range m.getVisibleEntries() {
+		if !m.searchMatches(entry) {
+			continue
+		}
+		total++
+		if m.isHighlighted(entry) {
+			position = total
+		}
+	}
+	if position == 0 {
+		return fmt.Sprintf("%d matches", total)
+	}
+	return fmt.Sprintf("match %d of %d", position, total)
+}
+
+// matchHighlights returns the patterns whose matches are highlighted inside
+// messages: the search and the active message filters. Raw lines aren't
+// highlighted.
+func (m *Model) matchHighlights() []*regexp.Regexp {
+	if m.rawMode {
+		return nil
+	}
+	var patterns []*regexp.Regexp
+	if m.search != nil {
+		patterns = append(patterns, m.search)
+	}
+	if !m.filtersOff {
+		for _, filter := range m.filters {
+			if filter.disabled || filter.exclude || filter.isTag || filter.field != "" || filter.flag != "" {
+				continue
+			}
+			patterns = append(patterns, filter.regex)
+		}
+	}
+	return patterns
+}
+
+// patternsKey joins the patterns' sources, so a change drops the line cache.
+func patternsKey(patterns []*regexp.Regexp) string {
+	sources := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		sources[i] = pattern.String()
+	}
+	return strings.Join(sources, "\x00")
+}
+
+// visibleIndex returns the index of entry in visible, or -1.
+func visibleIndex(visible []*logcat.Entry, entry *logcat.Entry) int {
+	for i, e := range visible {
+		if e.ID == entry.ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// searchInfo describes the active search for the header.
+func (m *Model) searchInfo() string {
+	return "search: " + strings.TrimSpace(m.searchText) + " (n/N: next/prev)"
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SignalMsg reports a termination signal, such as SIGHUP when the terminal
+// window is closed.
+type SignalMsg struct{ Signal os.Signal }
+
+// handleSignal stops logging and quits, first saving the log to a temp file
+// when autosaveOnSignal is set.
+func (m *Model) handleSignal() tea.Cmd {
+	m.terminating = true
+	if m.autosaveOnSignal && len(m.parsedEntries) > 0 {
+		path, err := autosave(rawLines(m.parsedEntries))
+		if err != nil {
+			m.errorMessage = "autosave failed: " + err.Error()
+		} else {
+			m.exportPaths = append(m.exportPaths, path)
+		}
+	}
+	if m.logManager != nil {
+		m.stopLogging()
+	}
+	return tea.Quit
+}
+
+// autosave writes lines to a new temp file and returns its path.
+func autosave(lines []string) (string, error) {
+	file, err := os.CreateTemp("", "logdog-autosave-*.log")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		file.Close()
+		return "", err
+	}
+	return file.Name(), file.Close()
+}
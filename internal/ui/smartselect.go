@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// startUntilPrompt opens the footer prompt for extending the selection to the
+// next entry matching a regex.
+func (m *Model) startUntilPrompt() {
+	if m.untilInput.Placeholder == "" {
+		m.untilInput = textinput.New()
+		m.untilInput.Placeholder = "regex, e.g. ^Caused by"
+		m.untilInput.CharLimit = 500
+		m.untilInput.Width = 80
+	}
+	m.untilInput.SetValue("")
+	m.untilInput.Focus()
+	m.showUntilInput = true
+}
+
+// applyUntilPrompt extends the selection to the match entered in the prompt.
+func (m *Model) applyUntilPrompt() {
+	m.showUntilInput = false
+	m.untilInput.Blur()
+	pattern := strings.TrimSpace(m.untilInput.Value())
+	if pattern == "" {
+		return
+	}
+	m.selectUntil(pattern)
+}
+
+// selectUntil extends the selection from its anchor to the first entry below
+// it whose tag or message matches pattern, case-insensitively.
+func (m *Model) selectUntil(pattern string) {
+	regex, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		m.statusMessage = "invalid regex: " + err.Error()
+		return
+	}
+	visible := m.getVisibleEntries()
+	lowest := -1
+	for i, entry := range visible {
+		if m.selectedEntries[entry.ID] {
+			lowest = i
+		}
+	}
+	if lowest < 0 {
+		return
+	}
+	for _, entry := range visible[lowest+1:] {
+		if regex.MatchString(entry.Message) || regex.MatchString(entry.Tag) {
+			m.extendSelectionTo(entry, visible)
+			m.ensureEntryVisible(entry)
+			m.statusMessage = fmt.Sprintf("selected %d entries", len(m.selectedEntries))
+			return
+		}
+	}
+	m.statusMessage = "no match for " + pattern + " below the selection"
+}
+
+// selectStackTrace selects the stack trace around the selection anchor: the
+// run of entries logged by the same call, which logcat prints with the same
+// timestamp, tag, level, PID and TID, e.g. a whole FATAL EXCEPTION block.
+func (m *Model) selectStackTrace() {
+	if m.selectionAnchor == nil {
+		return
+	}
+	visible := m.getVisibleEntries()
+	index := -1
+	for i, entry := range visible {
+		if entry.ID == m.selectionAnchor.ID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return
+	}
+
+	sameCall := func(entry *logcat.Entry) bool {
+		return entry.Source == m.selectionAnchor.Source && sameEntryMeta(entry, m.selectionAnchor)
+	}
+	start, end := index, index
+	for start > 0 && sameCall(visible[start-1]) {
+		start--
+	}
+	for end < len(visible)-1 && sameCall(visible[end+1]) {
+		end++
+	}
+
+	trace := visible[start : end+1]
+	hasFrames := false
+	for _, entry := range trace {
+		if isStackTraceLine(entry.Message) {
+			hasFrames = true
+			break
+		}
+	}
+	if !hasFrames {
+		m.statusMessage = "no stack trace at the selection"
+		return
+	}
+
+	m.selectedEntries = make(map[uint64]bool, len(trace))
+	for _, entry := range trace {
+		m.selectedEntries[entry.ID] = true
+	}
+	m.selectionAnchor = trace[0]
+	m.ensureEntryVisible(trace[len(trace)-1])
+	m.ensureEntryVisible(trace[0])
+	m.statusMessage = fmt.Sprintf("selected %d entries", len(trace))
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxSnapshotLines caps a snapshot, keeping the lines up to the bottom of the view.
+const maxSnapshotLines = 10000
+
+// viewSnapshot is a frozen copy of the log view, shown in a pane below the
+// live one to compare before and after an action.
+type viewSnapshot struct {
+	at     time.Time
+	lines  []string
+	lastID uint64
+	offset int
+}
+
+// snapshotRows returns the rows the snapshot pane and its divider take out of
+// the height available to the log.
+func (m *Model) snapshotRows(available int) int {
+	if !m.showSnapshot {
+		return 0
+	}
+	return available / 2
+}
+
+// snapshotHeight is the number of snapshot lines shown below the divider.
+func (m *Model) snapshotHeight() int {
+	return max(0, m.snapshotPaneRows()-1)
+}
+
+// snapshotPaneRows is the height of the snapshot pane including its divider.
+func (m *Model) snapshotPaneRows() int {
+	if !m.showSnapshot {
+		return 0
+	}
+	headerHeight, footerHeight := m.layoutHeights()
+	return m.snapshotRows(max(0, m.height-headerHeight-footerHeight-m.stickyRows()))
+}
+
+// toggleSnapshot freezes the lines of the current view into the snapshot
+// pane, or closes the pane when it is open.
+func (m *Model) toggleSnapshot() {
+	if m.showSnapshot {
+		m.showSnapshot = false
+		m.snapshot = viewSnapshot{}
+		m.resizeViewport()
+		m.updateViewportWithScroll(m.autoScroll)
+		return
+	}
+	end := min(len(m.lineEntries), m.viewport.YOffset+m.viewport.Height)
+	start := max(0, end-maxSnapshotLines)
+	m.snapshot = viewSnapshot{
+		at:     time.Now(),
+		lines:  m.windowLines(start, end),
+		lastID: m.nextEntryID,
+	}
+	m.showSnapshot = true
+	m.resizeViewport()
+	// Line up the bottom of the snapshot with what the view showed
+	m.snapshot.offset = max(0, len(m.snapshot.lines)-m.snapshotHeight())
+	m.statusMessage = "snapshot taken (S: close)"
+	m.updateViewportWithScroll(m.autoScroll)
+}
+
+// scrollSnapshot moves the snapshot pane by delta lines.
+func (m *Model) scrollSnapshot(delta int) {
+	limit := max(0, len(m.snapshot.lines)-m.snapshotHeight())
+	m.snapshot.offset = min(max(0, m.snapshot.offset+delta), limit)
+}
+
+// snapshotView renders the divider and the visible part of the snapshot.
+func (m *Model) snapshotView() string {
+	height := m.snapshotHeight()
+	dividerStyle := lipgloss.NewStyle().Foreground(GetAccentColor())
+	label := fmt.Sprintf("── snapshot %s · %d entries received since (S: close, shift+↑/↓: scroll) ",
+		m.snapshot.at.Format("15:04:05"), m.nextEntryID-m.snapshot.lastID)
+	divider := label + strings.Repeat("─", max(0, m.width-lipgloss.Width(label)))
+
+	end := min(len(m.snapshot.lines), m.snapshot.offset+height)
+	lines := make([]string, 0, height)
+	for _, line := range m.snapshot.lines[min(m.snapshot.offset, end):end] {
+		lines = append(lines, ansi.Truncate(line, m.width, ""))
+	}
+	body := lipgloss.NewStyle().
+		Width(m.width).
+		Height(height).
+		MaxHeight(height).
+		Render(strings.Join(lines, "\n"))
+	return dividerStyle.Render(ansi.Truncate(divider, m.width, "")) + "\n" + body
+}
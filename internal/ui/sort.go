@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+const (
+	sortArrival = iota
+	sortTimestamp
+	sortPriority
+	sortTag
+	sortField
+)
+
+// sortMode describes how the filtered view is ordered.
+type sortMode struct {
+	kind  int
+	field string
+}
+
+func (s sortMode) label() string {
+	switch s.kind {
+	case sortTimestamp:
+		return "time"
+	case sortPriority:
+		return "priority"
+	case sortTag:
+		return "tag"
+	case sortField:
+		return s.field
+	default:
+		return "live"
+	}
+}
+
+// sortModes lists the available modes in cycling order, including one per extracted column.
+func sortModes() []sortMode {
+	modes := []sortMode{
+		{kind: sortArrival},
+		{kind: sortTimestamp},
+		{kind: sortPriority},
+		{kind: sortTag},
+	}
+	for _, name := range ExtraColumns() {
+		modes = append(modes, sortMode{kind: sortField, field: name})
+	}
+	return modes
+}
+
+// nextSortMode returns the mode following current in cycling order.
+func nextSortMode(current sortMode) sortMode {
+	modes := sortModes()
+	for i, mode := range modes {
+		if mode == current {
+			return modes[(i+1)%len(modes)]
+		}
+	}
+	return modes[0]
+}
+
+// sortEntries orders entries, given in arrival order, in place; ties keep
+// arrival order.
+func sortEntries(entries []*logcat.Entry, mode sortMode) {
+	var less func(a, b *logcat.Entry) bool
+	switch mode.kind {
+	case sortTimestamp:
+		sortByTime(entries)
+		return
+	case sortPriority:
+		less = func(a, b *logcat.Entry) bool { return severityRank(a.Priority) > severityRank(b.Priority) }
+	case sortTag:
+		less = func(a, b *logcat.Entry) bool { return a.Tag < b.Tag }
+	case sortField:
+		less = func(a, b *logcat.Entry) bool { return lessField(a, b, mode.field) }
+	default:
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i], entries[j])
+	})
+}
+
+// sortByTime orders entries by their parsed time, which unlike the timestamp
+// text has a year and doesn't depend on the log format. Entries without a time,
+// such as unparsed lines, sort with the entry they follow.
+func sortByTime(entries []*logcat.Entry) {
+	type timed struct {
+		entry *logcat.Entry
+		at    time.Time
+	}
+	keyed := make([]timed, len(entries))
+	var last time.Time
+	for i, entry := range entries {
+		if !entry.Time.IsZero() {
+			last = entry.Time
+		}
+		keyed[i] = timed{entry, last}
+	}
+	sort.SliceStable(keyed, func(i, j int) bool {
+		return keyed[i].at.Before(keyed[j].at)
+	})
+	for i, k := range keyed {
+		entries[i] = k.entry
+	}
+}
+
+// severityRank orders priorities by severity. Unknown, for continuations and
+// unparsed lines, ranks below Verbose rather than above Assert, where it sits
+// in the enum.
+func severityRank(p logcat.Priority) int {
+	if p == logcat.Unknown {
+		return -1
+	}
+	return int(p)
+}
+
+// lessField compares extracted values numerically when possible; entries without the field sort last.
+func lessField(a, b *logcat.Entry, field string) bool {
+	av, aok := a.Field(field)
+	bv, bok := b.Field(field)
+	if !aok || !bok {
+		return aok && !bok
+	}
+	af, aErr := strconv.ParseFloat(av, 64)
+	bf, bErr := strconv.ParseFloat(bv, 64)
+	if aErr == nil && bErr == nil {
+		return af < bf
+	}
+	return av < bv
+}
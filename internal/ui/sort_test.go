@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+func TestSortByTimeAcrossYearsKeepsUnparsedLinesInPlace(t *testing.T) {
+	newYear := &logcat.Entry{Message: "new year", Timestamp: "01-01 00:00:01.000", Time: time.Date(2026, 1, 1, 0, 0, 1, 0, time.Local)}
+	trace := &logcat.Entry{Message: "trace", Unparsed: true}
+	oldYear := &logcat.Entry{Message: "old year", Timestamp: "12-31 23:59:59.000", Time: time.Date(2025, 12, 31, 23, 59, 59, 0, time.Local)}
+	entries := []*logcat.Entry{newYear, trace, oldYear}
+
+	sortEntries(entries, sortMode{kind: sortTimestamp})
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Message)
+	}
+	want := []string{"old year", "new year", "trace"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/adb"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+const sourceLabelWidth = 10
+
+// source is one log stream in multi-source mode. The primary source uses the
+// model's log manager; additional sources own their manager and line channel.
+type source struct {
+	serial   string
+	label    string
+	manager  *logcat.Manager
+	lineChan chan string
+	hidden   bool
+}
+
+type sourceErrMsg struct {
+	serial string
+	err    error
+}
+
+// multiSource reports whether entries are labelled with their source.
+func (m *Model) multiSource() bool {
+	return len(m.sources) > 1
+}
+
+// sourceLabel returns the configured alias for a serial, falling back to the device model.
+func (m *Model) sourceLabel(device adb.Device) string {
+	if alias, ok := m.deviceAliases[device.Serial]; ok && alias != "" {
+		return alias
+	}
+	if name := device.DisplayName(); name != "" && name != "Unknown" {
+		return name
+	}
+	return device.Serial
+}
+
+// addPrimarySource registers the model's own manager as the first source.
+func (m *Model) addPrimarySource(device adb.Device) {
+	m.sources = append(m.sources, &source{
+		serial:  device.Serial,
+		label:   m.sourceLabel(device),
+		manager: m.logManager,
+	})
+}
+
+// addSource starts an additional logcat stream for the device.
+func (m *Model) addSource(device adb.Device) tea.Cmd {
+	manager := logcat.NewManager(m.appID, m.tailSize)
+	manager.SetDevice(device.Serial)
+	src := &source{
+		serial:   device.Serial,
+		label:    m.sourceLabel(device),
+		manager:  manager,
+		lineChan: make(chan string, 100),
+	}
+	m.sources = append(m.sources, src)
+	return tea.Batch(
+		startSource(src),
+		waitForSourceLine(src.serial, src.lineChan),
+	)
+}
+
+func startSource(src *source) tea.Cmd {
+	return func() tea.Msg {
+		if err := src.manager.Start(); err != nil {
+			return sourceErrMsg{serial: src.serial, err: err}
+		}
+		go src.manager.ReadLines(src.lineChan)
+		return nil
+	}
+}
+
+// waitForSourceLine waits for lines from an additional source and tags them with its serial.
+func waitForSourceLine(serial string, lineChan <-chan string) tea.Cmd {
+	wait := waitForLogLine(lineChan)
+	return func() tea.Msg {
+		msg := wait()
+		if lines, ok := msg.(logLineMsg); ok {
+			lines.source = serial
+			return lines
+		}
+		return msg
+	}
+}
+
+// sourceBySerial returns the source with the given serial.
+func (m *Model) sourceBySerial(serial string) *source {
+	for _, src := range m.sources {
+		if src.serial == serial {
+			return src
+		}
+	}
+	return nil
+}
+
+// sourceVisible reports whether the entry's source is toggled on.
+func (m *Model) sourceVisible(entry *logcat.Entry) bool {
+	if !m.multiSource() {
+		return true
+	}
+	src := m.sourceBySerial(entry.Source)
+	return src == nil || !src.hidden
+}
+
+// stopLogging stops the primary manager and all additional sources. The lines
+// their readers hand on while stopping are added to the log, so the session
+// summary and exports include them.
+func (m *Model) stopLogging() {
+	type stream struct {
+		manager *logcat.Manager
+		lines   chan string
+		source  string
+		last    []string
+	}
+	streams := []*stream{{manager: m.logManager, lines: m.lineChan}}
+	for _, src := range m.sources {
+		if src.manager != m.logManager {
+			streams = append(streams, &stream{manager: src.manager, lines: src.lineChan, source: src.serial})
+		}
+	}
+	var wg sync.WaitGroup
+	for _, s := range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stopped := make(chan struct{})
+			go func() {
+				s.manager.Stop()
+				close(stopped)
+			}()
+			for {
+				select {
+				case line := <-s.lines:
+					s.last = append(s.last, line)
+				case <-stopped:
+					for {
+						select {
+						case line := <-s.lines:
+							s.last = append(s.last, line)
+						default:
+							return
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now()
+	for _, s := range streams {
+		m.ingestLines(s.last, s.source, now)
+	}
+	if m.reorderer != nil {
+		for _, entry := range m.reorderer.Release(now.Add(m.reorderer.Window())) {
+			m.appendEntry(entry)
+		}
+	}
+	m.flushCaptures()
+}
+
+// withSourceLabel prefixes rendered entry lines with a colored source label.
+func (m *Model) withSourceLabel(entry *logcat.Entry, lines []string) []string {
+	if !m.multiSource() {
+		return lines
+	}
+	label := entry.Source
+	if src := m.sourceBySerial(entry.Source); src != nil {
+		label = src.label
+	}
+	labelText := fmt.Sprintf("%-*s ", sourceLabelWidth, truncate(label, sourceLabelWidth))
+	blank := strings.Repeat(" ", sourceLabelWidth+1)
+	labelStyle := lipgloss.NewStyle().Foreground(TagColor(label)).Bold(true)
+	for i := range lines {
+		if i == 0 {
+			lines[i] = labelStyle.Render(labelText) + lines[i]
+		} else {
+			lines[i] = blank + lines[i]
+		}
+	}
+	return lines
+}
+
+// sourcesView renders the sources panel with per-source toggles.
+func (m *Model) sourcesView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle := itemStyle.Foreground(GetAccentColor()).Bold(true)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	lines := []string{titleStyle.Render("Sources")}
+	for i, src := range m.sources {
+		cursor := " "
+		style := itemStyle
+		if i == m.sourcesIndex {
+			cursor = "›"
+			style = selectedStyle
+		}
+		checkbox := "[x]"
+		if src.hidden {
+			checkbox = "[ ]"
+		}
+		label := lipgloss.NewStyle().Foreground(TagColor(src.label)).Render(src.label)
+		lines = append(lines, style.Render(fmt.Sprintf("%s %s %s (%s)", cursor, checkbox, label, src.serial)))
+	}
+	lines = append(lines, "", helpStyle.Render("space: toggle | j/k: move | esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// handleSourcesKey handles keys while the sources panel is open.
+func (m *Model) handleSourcesKey(key string) {
+	switch key {
+	case "esc", "D":
+		m.showSources = false
+	case "j", "down":
+		m.sourcesIndex = (m.sourcesIndex + 1) % len(m.sources)
+	case "k", "up":
+		m.sourcesIndex--
+		if m.sourcesIndex < 0 {
+			m.sourcesIndex = len(m.sources) - 1
+		}
+	case " ", "enter":
+		src := m.sources[m.sourcesIndex]
+		src.hidden = !src.hidden
+		m.resetRenderCache()
+		m.updateViewportWithScroll(m.autoScroll)
+	}
+}
@@ -0,0 +1,214 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// maxStatsTalkers caps the tags and processes listed as top talkers.
+const maxStatsTalkers = 10
+
+// statsPriorities are the levels counted in the statistics, in severity order.
+var statsPriorities = []logcat.Priority{logcat.Verbose, logcat.Debug, logcat.Info, logcat.Warn, logcat.Error, logcat.Fatal, logcat.Assert, logcat.Unknown}
+
+// talker is a tag or process and the number of entries it logged.
+type talker struct {
+	name  string
+	count int
+}
+
+// isJavaCrash reports whether the entry starts a Java crash report.
+func isJavaCrash(entry *logcat.Entry) bool {
+	return strings.TrimSpace(entry.Tag) == "AndroidRuntime" && strings.HasPrefix(entry.Message, "FATAL EXCEPTION")
+}
+
+// isNativeCrash reports whether the entry starts a tombstone for a native crash.
+func isNativeCrash(entry *logcat.Entry) bool {
+	tag := strings.TrimSpace(entry.Tag)
+	return (tag == "DEBUG" || tag == "CRASH") && strings.HasPrefix(entry.Message, "*** *** ***")
+}
+
+// isANR reports whether the entry reports an app not responding.
+func isANR(entry *logcat.Entry) bool {
+	return strings.TrimSpace(entry.Tag) == "ActivityManager" && strings.HasPrefix(entry.Message, "ANR in ")
+}
+
+// topTalkers returns the names with the most entries, most first, and ties by name.
+func topTalkers(counts map[string]int) []talker {
+	talkers := make([]talker, 0, len(counts))
+	for name, count := range counts {
+		talkers = append(talkers, talker{name, count})
+	}
+	sort.Slice(talkers, func(i, j int) bool {
+		if talkers[i].count != talkers[j].count {
+			return talkers[i].count > talkers[j].count
+		}
+		return talkers[i].name < talkers[j].name
+	})
+	return talkers[:min(len(talkers), maxStatsTalkers)]
+}
+
+// sessionStats returns a plain text report on the entries in the log: the
+// time range they cover, entries per level, crashes, app restarts and the
+// tags and processes that logged the most.
+func (m *Model) sessionStats() string {
+	entries := m.parsedEntries
+	levels := make(map[logcat.Priority]int)
+	tags := make(map[string]int)
+	processes := make(map[string]int)
+	var first, last time.Time
+	var firstStamp, lastStamp string
+	var javaCrashes, nativeCrashes, anrs int
+	for _, entry := range entries {
+		levels[entry.Priority]++
+		if tag := strings.TrimSpace(entry.Tag); tag != "" {
+			tags[tag]++
+		}
+		if entry.PID != "" {
+			processes[entry.PID]++
+		}
+		if !entry.Time.IsZero() {
+			if first.IsZero() || entry.Time.Before(first) {
+				first, firstStamp = entry.Time, entry.Timestamp
+			}
+			if entry.Time.After(last) {
+				last, lastStamp = entry.Time, entry.Timestamp
+			}
+		}
+		switch {
+		case isJavaCrash(entry):
+			javaCrashes++
+		case isNativeCrash(entry):
+			nativeCrashes++
+		case isANR(entry):
+			anrs++
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("logdog session statistics\n\n")
+	switch {
+	case m.importName != "":
+		fmt.Fprintf(&b, "Source:     %s\n", m.importName)
+	case m.appID != "":
+		fmt.Fprintf(&b, "App:        %s\n", m.appID)
+	}
+	if m.selectedDevice != "" {
+		fmt.Fprintf(&b, "Device:     %s\n", m.selectedDevice)
+	}
+	if first.IsZero() {
+		b.WriteString("Time range: no timestamps\n")
+	} else {
+		fmt.Fprintf(&b, "Time range: %s to %s (%s)\n", firstStamp, lastStamp, last.Sub(first).Round(time.Second))
+	}
+	fmt.Fprintf(&b, "Entries:    %d", len(entries))
+	if m.evictedEntries > 0 {
+		fmt.Fprintf(&b, " (%d older entries dropped at the memory limit)", m.evictedEntries)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Crashes:    %d (%d Java, %d native), %d ANRs\n", javaCrashes+nativeCrashes, javaCrashes, nativeCrashes, anrs)
+	if m.appID != "" && m.importName == "" {
+		fmt.Fprintf(&b, "Restarts:   %d\n", m.appRestarts)
+	}
+
+	b.WriteString("\nEntries per level\n")
+	for _, p := range statsPriorities {
+		if levels[p] == 0 && p == logcat.Unknown {
+			continue
+		}
+		fmt.Fprintf(&b, "  %-8s %8d %6s\n", p.Name(), levels[p], percentOf(levels[p], len(entries)))
+	}
+
+	for _, section := range []struct {
+		title  string
+		counts map[string]int
+	}{{"Top tags", tags}, {"Top processes (PID)", processes}} {
+		talkers := topTalkers(section.counts)
+		if len(talkers) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", section.title)
+		width := 0
+		for _, t := range talkers {
+			width = max(width, len(t.name))
+		}
+		for _, t := range talkers {
+			fmt.Fprintf(&b, "  %-*s %8d %6s\n", width, t.name, t.count, percentOf(t.count, len(entries)))
+		}
+	}
+	return b.String()
+}
+
+// percentOf formats n as a percentage of total.
+func percentOf(n, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
+
+// openStats opens the statistics pane on a report of the log as it is now.
+// The report is built once, so a busy stream doesn't recount every frame.
+func (m *Model) openStats() {
+	m.statsReport = m.sessionStats()
+	m.showStats = true
+}
+
+// handleStatsKey handles keys while the statistics pane is open.
+func (m *Model) handleStatsKey(key string) {
+	switch key {
+	case "esc", "m":
+		m.showStats = false
+	case "c":
+		if err := copyToClipboard(m.statsReport); err != nil {
+			m.statusMessage = "copy failed: " + err.Error()
+		} else {
+			m.statusMessage = "copied session statistics"
+		}
+		m.showStats = false
+	case "s":
+		path := fmt.Sprintf("logdog-%s-stats.txt", time.Now().Format("20060102-150405"))
+		if err := os.WriteFile(path, []byte(m.statsReport), 0o644); err != nil {
+			m.statusMessage = "save failed: " + err.Error()
+		} else {
+			m.exportPaths = append(m.exportPaths, path)
+			m.statusMessage = "saved session statistics to " + path
+		}
+		m.showStats = false
+	}
+}
+
+// statsView renders the statistics report, cut to the screen height.
+func (m *Model) statsView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	report := strings.Split(strings.TrimSuffix(m.statsReport, "\n"), "\n")
+	// The report's own title line is replaced by the panel title
+	lines := []string{titleStyle.Render("Session statistics")}
+	rows := max(1, m.height-8)
+	for i, line := range report[1:] {
+		if i == rows {
+			lines = append(lines, itemStyle.Render("…"))
+			break
+		}
+		lines = append(lines, itemStyle.Render(truncate(line, max(0, m.width-8))))
+	}
+	lines = append(lines, "", helpStyle.Render("c: copy | s: save to a file | esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
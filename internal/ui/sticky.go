@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// stickyRows returns the number of rows the sticky context line takes above the viewport.
+func (m *Model) stickyRows() int {
+	if m.stickyHeader {
+		return 1
+	}
+	return 0
+}
+
+// stickyView renders the context line above the viewport: the tag, timestamp and
+// PID of the entry at the top of the viewport. Tags are only printed when they
+// change, so without it the lines at the top of a long block have no visible tag.
+func (m *Model) stickyView() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "243", Dark: "245"}).Width(m.width)
+	entry := m.entryAtRow(m.stickyRows())
+	if entry == nil || m.viewport.YOffset == 0 {
+		return style.Render("")
+	}
+
+	tag := lipgloss.NewStyle().Foreground(TagColor(entry.Tag)).Bold(true).Render(entry.Tag)
+	context := fmt.Sprintf("↑ %s %s", tag, style.UnsetWidth().Render(entry.Priority.String()))
+	if ts := timestampText(entry); ts != "" {
+		context += style.UnsetWidth().Render(" · " + ts)
+	}
+	if entry.PID != "" {
+		context += style.UnsetWidth().Render(" · pid " + entry.PID)
+	}
+	return style.Render(context)
+}
+
+// resizeViewport fits the viewport between the sticky line, snapshot and exec panes, header and footer.
+func (m *Model) resizeViewport() {
+	headerHeight, footerHeight := m.layoutHeights()
+	viewportHeight := m.height - headerHeight - footerHeight - m.stickyRows()
+	viewportHeight -= m.snapshotRows(viewportHeight) + m.execRows(viewportHeight)
+	if viewportHeight < 0 {
+		viewportHeight = 0
+	}
+	m.viewport.Height = viewportHeight
+}
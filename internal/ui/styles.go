@@ -1,113 +1,441 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
-
-// Color palette for log levels
-var (
-	colorVerbose = lipgloss.AdaptiveColor{Light: "240", Dark: "247"} // Very subtle gray
-	colorDebug   = lipgloss.AdaptiveColor{Light: "31", Dark: "110"}  // Moderate teal
-	colorInfo    = lipgloss.AdaptiveColor{Light: "28", Dark: "115"}  // Vibrant green
-	colorWarn    = lipgloss.AdaptiveColor{Light: "166", Dark: "215"} // Subtle orange
-	colorError   = lipgloss.AdaptiveColor{Light: "160", Dark: "210"} // Subtle red
-	colorFatal   = lipgloss.AdaptiveColor{Light: "126", Dark: "211"} // Subtle magenta
-	colorDefault = lipgloss.AdaptiveColor{Light: "0", Dark: "255"}   // Black/White
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/config"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+	"github.com/muesli/termenv"
+)
 
+// palette is the set of colors the UI draws with.
+type palette struct {
+	verbose, debug, info, warn, error, fatal, assert lipgloss.AdaptiveColor
 	// Background colors for log levels (kept in sync with foregrounds by default)
-	colorVerboseBg = lipgloss.AdaptiveColor{Light: "240", Dark: "247"}
-	colorDebugBg   = lipgloss.AdaptiveColor{Light: "31", Dark: "67"}
-	colorInfoBg    = lipgloss.AdaptiveColor{Light: "28", Dark: "109"}
-	colorWarnBg    = lipgloss.AdaptiveColor{Light: "166", Dark: "172"}
-	colorErrorBg   = lipgloss.AdaptiveColor{Light: "160", Dark: "1"}
-	colorFatalBg   = lipgloss.AdaptiveColor{Light: "126", Dark: "211"}
-)
+	verboseBg, debugBg, infoBg, warnBg, errorBg, fatalBg, assertBg lipgloss.AdaptiveColor
+	// Black/White for untagged text, dim gray for unparsed lines
+	text, unknown lipgloss.AdaptiveColor
+	// Tag colors don't overlap with log levels; filter badges are more subtle
+	tags, filters []lipgloss.AdaptiveColor
+	// UI accent color used in headers and selected items
+	accent lipgloss.AdaptiveColor
+	// priorityStyles add attributes such as bold or a background to the
+	// messages of a priority
+	priorityStyles map[logcat.Priority]lipgloss.Style
+}
+
+// palette256 uses the 256-color palette every terminal logdog supports.
+var palette256 = palette{
+	verbose: lipgloss.AdaptiveColor{Light: "240", Dark: "247"}, // Very subtle gray
+	debug:   lipgloss.AdaptiveColor{Light: "31", Dark: "110"},  // Moderate teal
+	info:    lipgloss.AdaptiveColor{Light: "28", Dark: "115"},  // Vibrant green
+	warn:    lipgloss.AdaptiveColor{Light: "166", Dark: "215"}, // Subtle orange
+	error:   lipgloss.AdaptiveColor{Light: "160", Dark: "210"}, // Subtle red
+	fatal:   lipgloss.AdaptiveColor{Light: "126", Dark: "211"}, // Subtle magenta
+	assert:  lipgloss.AdaptiveColor{Light: "88", Dark: "199"},  // Deep red-magenta
+	text:    lipgloss.AdaptiveColor{Light: "0", Dark: "255"},
+	unknown: lipgloss.AdaptiveColor{Light: "245", Dark: "243"},
+
+	verboseBg: lipgloss.AdaptiveColor{Light: "240", Dark: "247"},
+	debugBg:   lipgloss.AdaptiveColor{Light: "31", Dark: "67"},
+	infoBg:    lipgloss.AdaptiveColor{Light: "28", Dark: "109"},
+	warnBg:    lipgloss.AdaptiveColor{Light: "166", Dark: "172"},
+	errorBg:   lipgloss.AdaptiveColor{Light: "160", Dark: "1"},
+	fatalBg:   lipgloss.AdaptiveColor{Light: "126", Dark: "211"},
+	assertBg:  lipgloss.AdaptiveColor{Light: "88", Dark: "161"},
+
+	tags: []lipgloss.AdaptiveColor{
+		{Light: "30", Dark: "123"},  // Pastel teal
+		{Light: "91", Dark: "183"},  // Pastel purple
+		{Light: "130", Dark: "222"}, // Pastel peach
+		{Light: "97", Dark: "189"},  // Pastel lavender
+		{Light: "90", Dark: "182"},  // Pastel violet
+		{Light: "131", Dark: "217"}, // Pastel tan
+		{Light: "98", Dark: "193"},  // Pastel mauve
+		{Light: "23", Dark: "159"},  // Pastel cyan
+		{Light: "136", Dark: "229"}, // Pastel yellow
+		{Light: "61", Dark: "147"},  // Pastel slate blue
+		{Light: "94", Dark: "180"},  // Pastel brown
+		{Light: "25", Dark: "153"},  // Pastel sky blue
+		{Light: "101", Dark: "187"}, // Pastel khaki
+		{Light: "133", Dark: "219"}, // Pastel orchid
+	},
+	filters: []lipgloss.AdaptiveColor{
+		{Light: "109", Dark: "102"}, // Muted teal-gray
+		{Light: "146", Dark: "139"}, // Muted purple-gray
+		{Light: "181", Dark: "174"}, // Muted peach-gray
+		{Light: "144", Dark: "108"}, // Muted lime-gray
+		{Light: "182", Dark: "145"}, // Muted lavender-gray
+		{Light: "116", Dark: "109"}, // Muted cyan-gray
+		{Light: "140", Dark: "139"}, // Muted violet-gray
+		{Light: "180", Dark: "144"}, // Muted tan-gray
+		{Light: "151", Dark: "108"}, // Muted mint-gray
+		{Light: "183", Dark: "146"}, // Muted mauve-gray
+	},
+	accent: lipgloss.AdaptiveColor{Light: "33", Dark: "110"},
+}
+
+// truecolorFilters are the badge colors shared by the truecolor themes.
+var truecolorFilters = []lipgloss.AdaptiveColor{
+	{Light: "#8fb3b0", Dark: "#5f7f7c"},
+	{Light: "#b3a8c7", Dark: "#7f728f"},
+	{Light: "#d6b8a8", Dark: "#9a7a6c"},
+	{Light: "#b5c29a", Dark: "#6f7f5a"},
+	{Light: "#c8b6d6", Dark: "#8a7c99"},
+	{Light: "#9fc7cf", Dark: "#5f8a92"},
+	{Light: "#bba6cc", Dark: "#806f91"},
+	{Light: "#d1c29a", Dark: "#8f8462"},
+	{Light: "#a8d1b8", Dark: "#64907a"},
+	{Light: "#d6b3c9", Dark: "#96788a"},
+}
+
+// truecolorThemes are smoother 24-bit palettes, used when the terminal
+// supports them.
+var truecolorThemes = map[string]palette{
+	// soft keeps the hues of the 256-color palette with gentler shades
+	"soft": {
+		verbose: lipgloss.AdaptiveColor{Light: "#5f5f5f", Dark: "#a8a8a8"},
+		debug:   lipgloss.AdaptiveColor{Light: "#1f7a99", Dark: "#7fb4d4"},
+		info:    lipgloss.AdaptiveColor{Light: "#2e7d32", Dark: "#8fd3a7"},
+		warn:    lipgloss.AdaptiveColor{Light: "#c75c00", Dark: "#f5b971"},
+		error:   lipgloss.AdaptiveColor{Light: "#c62828", Dark: "#f28b82"},
+		fatal:   lipgloss.AdaptiveColor{Light: "#ad1457", Dark: "#f48fb1"},
+		assert:  lipgloss.AdaptiveColor{Light: "#880e4f", Dark: "#ec407a"},
+		text:    lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#eeeeee"},
+		unknown: lipgloss.AdaptiveColor{Light: "#8a8a8a", Dark: "#767676"},
+
+		verboseBg: lipgloss.AdaptiveColor{Light: "#5f5f5f", Dark: "#9e9e9e"},
+		debugBg:   lipgloss.AdaptiveColor{Light: "#1f7a99", Dark: "#4f7f9f"},
+		infoBg:    lipgloss.AdaptiveColor{Light: "#2e7d32", Dark: "#6fa58a"},
+		warnBg:    lipgloss.AdaptiveColor{Light: "#c75c00", Dark: "#d08a2c"},
+		errorBg:   lipgloss.AdaptiveColor{Light: "#c62828", Dark: "#b3261e"},
+		fatalBg:   lipgloss.AdaptiveColor{Light: "#ad1457", Dark: "#e57399"},
+		assertBg:  lipgloss.AdaptiveColor{Light: "#880e4f", Dark: "#c2185b"},
+
+		tags: []lipgloss.AdaptiveColor{
+			{Light: "#00838f", Dark: "#80deea"},
+			{Light: "#7b1fa2", Dark: "#d1b3ff"},
+			{Light: "#a65b00", Dark: "#ffd0a8"},
+			{Light: "#5c4db1", Dark: "#c5c8ff"},
+			{Light: "#8e24aa", Dark: "#e2b6e5"},
+			{Light: "#8d5524", Dark: "#e8c4a8"},
+			{Light: "#558b2f", Dark: "#d7f5b0"},
+			{Light: "#00695c", Dark: "#80cbc4"},
+			{Light: "#9e7c00", Dark: "#fff59d"},
+			{Light: "#3949ab", Dark: "#9fa8da"},
+			{Light: "#6d4c41", Dark: "#bcaaa4"},
+			{Light: "#0277bd", Dark: "#b3e5fc"},
+			{Light: "#827717", Dark: "#e6ee9c"},
+			{Light: "#6a1b9a", Dark: "#f3c4fb"},
+		},
+		filters: truecolorFilters,
+		accent:  lipgloss.AdaptiveColor{Light: "#1e6fd9", Dark: "#82b1d9"},
+	},
+	// vivid uses saturated colors for bright or busy terminals
+	"vivid": {
+		verbose: lipgloss.AdaptiveColor{Light: "#616161", Dark: "#bdbdbd"},
+		debug:   lipgloss.AdaptiveColor{Light: "#0277bd", Dark: "#4fc3f7"},
+		info:    lipgloss.AdaptiveColor{Light: "#1b8a3a", Dark: "#69f0ae"},
+		warn:    lipgloss.AdaptiveColor{Light: "#e65100", Dark: "#ffab40"},
+		error:   lipgloss.AdaptiveColor{Light: "#d50000", Dark: "#ff5252"},
+		fatal:   lipgloss.AdaptiveColor{Light: "#c51162", Dark: "#ff4081"},
+		assert:  lipgloss.AdaptiveColor{Light: "#880e4f", Dark: "#f50057"},
+		text:    lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#eeeeee"},
+		unknown: lipgloss.AdaptiveColor{Light: "#8a8a8a", Dark: "#767676"},
 
-// Color palette for tags - pastel colors that don't overlap with log levels
-var tagColors = []lipgloss.AdaptiveColor{
-	{Light: "30", Dark: "123"},  // Pastel teal
-	{Light: "91", Dark: "183"},  // Pastel purple
-	{Light: "130", Dark: "222"}, // Pastel peach
-	{Light: "97", Dark: "189"},  // Pastel lavender
-	{Light: "90", Dark: "182"},  // Pastel violet
-	{Light: "131", Dark: "217"}, // Pastel tan
-	{Light: "98", Dark: "193"},  // Pastel mauve
+		verboseBg: lipgloss.AdaptiveColor{Light: "#616161", Dark: "#757575"},
+		debugBg:   lipgloss.AdaptiveColor{Light: "#0277bd", Dark: "#0288d1"},
+		infoBg:    lipgloss.AdaptiveColor{Light: "#1b8a3a", Dark: "#2e9e5b"},
+		warnBg:    lipgloss.AdaptiveColor{Light: "#e65100", Dark: "#ef6c00"},
+		errorBg:   lipgloss.AdaptiveColor{Light: "#d50000", Dark: "#d32f2f"},
+		fatalBg:   lipgloss.AdaptiveColor{Light: "#c51162", Dark: "#c2185b"},
+		assertBg:  lipgloss.AdaptiveColor{Light: "#880e4f", Dark: "#ad1457"},
+
+		tags: []lipgloss.AdaptiveColor{
+			{Light: "#00838f", Dark: "#18ffff"},
+			{Light: "#6200ea", Dark: "#b388ff"},
+			{Light: "#bf5f00", Dark: "#ffd180"},
+			{Light: "#304ffe", Dark: "#8c9eff"},
+			{Light: "#aa00ff", Dark: "#ea80fc"},
+			{Light: "#bf360c", Dark: "#ffab91"},
+			{Light: "#33691e", Dark: "#ccff90"},
+			{Light: "#00796b", Dark: "#64ffda"},
+			{Light: "#9e9d24", Dark: "#ffff8d"},
+			{Light: "#283593", Dark: "#7c8cff"},
+			{Light: "#4e342e", Dark: "#d7ccc8"},
+			{Light: "#01579b", Dark: "#80d8ff"},
+			{Light: "#827717", Dark: "#eeff41"},
+			{Light: "#4a148c", Dark: "#e040fb"},
+		},
+		filters: truecolorFilters,
+		accent:  lipgloss.AdaptiveColor{Light: "#0091ea", Dark: "#40c4ff"},
+		priorityStyles: map[logcat.Priority]lipgloss.Style{
+			logcat.Fatal:  lipgloss.NewStyle().Bold(true),
+			logcat.Assert: lipgloss.NewStyle().Bold(true),
+		},
+	},
 }
 
-// Color palette for filter badges - very subtle muted colors
-var filterColors = []lipgloss.AdaptiveColor{
-	{Light: "109", Dark: "102"}, // Muted teal-gray
-	{Light: "146", Dark: "139"}, // Muted purple-gray
-	{Light: "181", Dark: "174"}, // Muted peach-gray
-	{Light: "144", Dark: "108"}, // Muted lime-gray
-	{Light: "182", Dark: "145"}, // Muted lavender-gray
-	{Light: "116", Dark: "109"}, // Muted cyan-gray
-	{Light: "140", Dark: "139"}, // Muted violet-gray
-	{Light: "180", Dark: "144"}, // Muted tan-gray
-	{Light: "151", Dark: "108"}, // Muted mint-gray
-	{Light: "183", Dark: "146"}, // Muted mauve-gray
+// defaultTruecolorTheme is used on truecolor terminals unless configured otherwise.
+const defaultTruecolorTheme = "soft"
+
+// colors is the active palette.
+var colors = palette256
+
+// SetColorTheme picks the palette for the terminal's color support. An empty
+// name uses the default truecolor theme when the terminal supports 24-bit
+// color, "256" always uses the 256-color palette, and a truecolor theme name
+// is used when supported. Unsupported terminals and unknown names fall back to
+// the 256-color palette.
+func SetColorTheme(name string) {
+	defer resetTagSlots()
+	colors = palette256
+	if name == "256" || lipgloss.ColorProfile() != termenv.TrueColor {
+		return
+	}
+	if name == "" {
+		name = defaultTruecolorTheme
+	}
+	if theme, ok := truecolorThemes[name]; ok {
+		colors = theme
+	}
 }
 
-// UI accent color used in headers and selected items
-var accentColor = lipgloss.AdaptiveColor{Light: "33", Dark: "110"}
+// priorityOverrides are the configured styles per priority, applied over the theme's.
+var priorityOverrides map[logcat.Priority]lipgloss.Style
 
-// GetVerboseColor returns the color for verbose log level
-func GetVerboseColor() lipgloss.TerminalColor { return colorVerbose }
+// SetPriorityStyles sets the styles of priorities from the configuration,
+// keyed by level name ("fatal", "W", ...). Colors are hex or ANSI values.
+func SetPriorityStyles(styles map[string]config.StylePreference) {
+	priorityOverrides = make(map[logcat.Priority]lipgloss.Style, len(styles))
+	for level, pref := range styles {
+		priority, ok := priorityFromConfig(level)
+		if !ok {
+			continue
+		}
+		style := lipgloss.NewStyle()
+		if pref.Foreground != "" {
+			style = style.Foreground(lipgloss.Color(pref.Foreground))
+		}
+		if pref.Background != "" {
+			style = style.Background(lipgloss.Color(pref.Background))
+		}
+		if pref.Bold {
+			style = style.Bold(true)
+		}
+		if pref.Italic {
+			style = style.Italic(true)
+		}
+		if pref.Underline {
+			style = style.Underline(true)
+		}
+		if pref.Faint {
+			style = style.Faint(true)
+		}
+		priorityOverrides[priority] = style
+	}
+}
 
-// GetDebugColor returns the color for debug log level
-func GetDebugColor() lipgloss.TerminalColor { return colorDebug }
+// GetPriorityStyle returns the style of messages of a priority: its color
+// plus the attributes the theme and configuration give it.
+func GetPriorityStyle(p logcat.Priority) lipgloss.Style {
+	style := lipgloss.NewStyle().Foreground(GetPriorityColor(p))
+	if theme, ok := colors.priorityStyles[p]; ok {
+		style = theme.Inherit(style)
+	}
+	if override, ok := priorityOverrides[p]; ok {
+		style = override.Inherit(style)
+	}
+	return style
+}
+
+// GetPriorityColor returns the foreground color of a priority, including a
+// configured one.
+func GetPriorityColor(p logcat.Priority) lipgloss.TerminalColor {
+	if override, ok := priorityOverrides[p]; ok {
+		if _, unset := override.GetForeground().(lipgloss.NoColor); !unset {
+			return override.GetForeground()
+		}
+	}
+	switch p {
+	case logcat.Verbose:
+		return colors.verbose
+	case logcat.Debug:
+		return colors.debug
+	case logcat.Info:
+		return colors.info
+	case logcat.Warn:
+		return colors.warn
+	case logcat.Error:
+		return colors.error
+	case logcat.Fatal:
+		return colors.fatal
+	case logcat.Assert:
+		return colors.assert
+	}
+	return colors.unknown
+}
+
+// GetPriorityBgColor returns the background color of a priority's column
+// when log levels are shown with a background.
+func GetPriorityBgColor(p logcat.Priority) lipgloss.TerminalColor {
+	switch p {
+	case logcat.Debug:
+		return colors.debugBg
+	case logcat.Info:
+		return colors.infoBg
+	case logcat.Warn:
+		return colors.warnBg
+	case logcat.Error:
+		return colors.errorBg
+	case logcat.Fatal:
+		return colors.fatalBg
+	case logcat.Assert:
+		return colors.assertBg
+	}
+	return colors.verboseBg
+}
 
 // GetInfoColor returns the color for info log level
-func GetInfoColor() lipgloss.TerminalColor { return colorInfo }
+func GetInfoColor() lipgloss.TerminalColor { return colors.info }
 
 // GetWarnColor returns the color for warn log level
-func GetWarnColor() lipgloss.TerminalColor { return colorWarn }
+func GetWarnColor() lipgloss.TerminalColor { return colors.warn }
 
 // GetErrorColor returns the color for error log level
-func GetErrorColor() lipgloss.TerminalColor { return colorError }
+func GetErrorColor() lipgloss.TerminalColor { return colors.error }
 
-// GetFatalColor returns the color for fatal log level
-func GetFatalColor() lipgloss.TerminalColor { return colorFatal }
+// GetUnknownColor returns the color for lines that failed to parse
+func GetUnknownColor() lipgloss.TerminalColor { return colors.unknown }
 
-// GetVerboseBgColor returns the background color for verbose log level
-func GetVerboseBgColor() lipgloss.TerminalColor { return colorVerboseBg }
+// GetAccentColor returns the UI accent color
+func GetAccentColor() lipgloss.TerminalColor { return colors.accent }
 
-// GetDebugBgColor returns the background color for debug log level
-func GetDebugBgColor() lipgloss.TerminalColor { return colorDebugBg }
+// frequentTagLines is how many lines a tag needs before it claims a tag
+// color of its own.
+const frequentTagLines = 20
 
-// GetInfoBgColor returns the background color for info log level
-func GetInfoBgColor() lipgloss.TerminalColor { return colorInfoBg }
+var (
+	// tagColorCache memoizes TagColor. Parsed tags are interned, so lookups for
+	// the same tag compare by pointer. Only the UI goroutine renders, so no lock.
+	tagColorCache = make(map[string]lipgloss.TerminalColor)
+	// customTagColors are the colors configured for specific tags
+	customTagColors map[string]lipgloss.TerminalColor
+	// tagCounts counts the lines per tag this session
+	tagCounts = make(map[string]int)
+	// tagSlots holds the frequent tag that claimed each tag color, and
+	// tagSlotOf the color each of them claimed
+	tagSlots  = make([]string, len(colors.tags))
+	tagSlotOf = make(map[string]int)
+	// tagColorGeneration counts changes to tag colors, so lines formatted
+	// with the old colors are formatted again
+	tagColorGeneration int
+)
 
-// GetWarnBgColor returns the background color for warn log level
-func GetWarnBgColor() lipgloss.TerminalColor { return colorWarnBg }
+// SetTagColors sets colors for specific tags, as hex ("#ff8800") or ANSI
+// ("208") values, overriding the theme's tag colors.
+func SetTagColors(mapping map[string]string) {
+	customTagColors = make(map[string]lipgloss.TerminalColor, len(mapping))
+	for tag, color := range mapping {
+		customTagColors[tag] = lipgloss.Color(color)
+	}
+	clear(tagColorCache)
+	tagColorGeneration++
+}
 
-// GetErrorBgColor returns the background color for error log level
-func GetErrorBgColor() lipgloss.TerminalColor { return colorErrorBg }
+// resetTagSlots releases the claimed tag colors, e.g. when the palette changes.
+// Frequent tags claim them again on their next line.
+func resetTagSlots() {
+	tagSlots = make([]string, len(colors.tags))
+	clear(tagSlotOf)
+	clear(tagColorCache)
+	tagColorGeneration++
+}
 
-// GetFatalBgColor returns the background color for fatal log level
-func GetFatalBgColor() lipgloss.TerminalColor { return colorFatalBg }
+// countTag counts a line for tag. Once a tag is frequent, it claims its
+// preferred tag color, or the next one no other frequent tag has claimed, so
+// the busiest tags of a session don't share colors. It reports whether tag
+// colors changed, in which case rendered lines need rendering again.
+func countTag(tag string) bool {
+	count := tagCounts[tag] + 1
+	tagCounts[tag] = count
+	if count < frequentTagLines || len(tagSlotOf) == len(tagSlots) {
+		return false
+	}
+	if _, ok := tagSlotOf[tag]; ok {
+		return false
+	}
+	if _, ok := customTagColors[tag]; ok {
+		return false
+	}
+	slot, ok := freeTagSlot(tagHash(tag))
+	if !ok {
+		return false
+	}
+	tagSlots[slot] = tag
+	tagSlotOf[tag] = slot
+	// Tags that preferred this color move to another one
+	clear(tagColorCache)
+	tagColorGeneration++
+	return true
+}
 
-// GetAccentColor returns the UI accent color
-func GetAccentColor() lipgloss.TerminalColor { return accentColor }
+// freeTagSlot returns the first unclaimed tag color from preferred on.
+func freeTagSlot(preferred int) (int, bool) {
+	for i := range tagSlots {
+		slot := (preferred + i) % len(tagSlots)
+		if tagSlots[slot] == "" {
+			return slot, true
+		}
+	}
+	return 0, false
+}
 
 // TagColor returns a consistent color for a given tag name
 func TagColor(tag string) lipgloss.TerminalColor {
 	if tag == "" {
-		return colorDefault
+		return colors.text
 	}
+	if color, ok := tagColorCache[tag]; ok {
+		return color
+	}
+	color, ok := customTagColors[tag]
+	if !ok {
+		color = tagColorFor(tag)
+	}
+	tagColorCache[tag] = color
+	return color
+}
 
-	// Simple hash function to map tag to color index
-	var hash uint32
-	for i := 0; i < len(tag); i++ {
-		hash = hash*31 + uint32(tag[i])
+// tagColorFor returns the color a frequent tag claimed, or the tag's
+// preferred color. Preferred colors claimed by another tag are passed over
+// while unclaimed ones remain.
+func tagColorFor(tag string) lipgloss.TerminalColor {
+	if slot, ok := tagSlotOf[tag]; ok {
+		return colors.tags[slot]
+	}
+	slot, ok := freeTagSlot(tagHash(tag))
+	if !ok {
+		slot = tagHash(tag)
 	}
+	return colors.tags[slot]
+}
 
-	colorIndex := int(hash) % len(tagColors)
-	return tagColors[colorIndex]
+// tagHash maps a tag to its preferred tag color with FNV-1a, which spreads
+// similar tags such as "OkHttp" and "OkHttpClient" better than a
+// multiplicative hash.
+func tagHash(tag string) int {
+	hash := uint32(2166136261)
+	for i := 0; i < len(tag); i++ {
+		hash ^= uint32(tag[i])
+		hash *= 16777619
+	}
+	return int(hash % uint32(len(colors.tags)))
 }
 
 // FilterColor returns a consistent color for filter badges (more subtle than tag colors)
 func FilterColor(filterText string) lipgloss.TerminalColor {
 	if filterText == "" {
-		return colorDefault
+		return colors.text
 	}
 
 	// Simple hash function to map filter to color index
@@ -116,6 +444,6 @@ func FilterColor(filterText string) lipgloss.TerminalColor {
 		hash = hash*31 + uint32(filterText[i])
 	}
 
-	colorIndex := int(hash) % len(filterColors)
-	return filterColors[colorIndex]
+	colorIndex := int(hash) % len(colors.filters)
+	return colors.filters[colorIndex]
 }
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+	"github.com/muesli/termenv"
+)
+
+func TestTagColorChangeRerendersCachedLines(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	resetTagSlots()
+	clear(tagCounts)
+	defer func() {
+		resetTagSlots()
+		clear(tagCounts)
+	}()
+
+	// Two tags that prefer the same color: the busy one claims it, so the
+	// other moves to the next color
+	busy, quiet := "Busy", ""
+	for i := 0; quiet == ""; i++ {
+		if tag := fmt.Sprintf("Quiet%d", i); tagHash(tag) == tagHash(busy) {
+			quiet = tag
+		}
+	}
+
+	m := newBenchModel(BenchOptions{Lines: 100, Width: 120, Height: 30})
+	appendLine := func(tag string) *logcat.Entry {
+		entry, _ := logcat.ParseLine("01-02 10:00:00.000  100  100 I " + tag + ": message")
+		m.appendEntry(entry)
+		return entry
+	}
+	entry := appendLine(quiet)
+	m.rebuildViewport(false)
+	before := m.formatLines(entry, true, false, m.contentWidth())
+
+	for range frequentTagLines {
+		appendLine(busy)
+	}
+	m.rebuildViewport(false)
+	got := m.formatLines(entry, true, false, m.contentWidth())
+	m.lineCache.clear()
+	want := m.formatLines(entry, true, false, m.contentWidth())
+
+	if want[0] == before[0] {
+		t.Fatal("expected the quiet tag to move to another color")
+	}
+	if got[0] != want[0] {
+		t.Fatalf("cached line kept the old tag color:\ngot  %q\nwant %q", got[0], want[0])
+	}
+}
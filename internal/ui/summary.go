@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// drainLines discards the lines left in ch once its reader has stopped.
+func drainLines(ch chan string) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
+// Summary returns the session summary printed after logdog quits: how long it
+// ran, the lines and errors it saw and the files it exported.
+func (m Model) Summary() string {
+	if m.sessionStart.IsZero() {
+		return ""
+	}
+	duration := time.Since(m.sessionStart).Round(time.Second)
+	var b strings.Builder
+	fmt.Fprintf(&b, "logdog session: %s, %d lines, %d errors\n", duration, m.nextEntryID, m.errorsSeen)
+	for _, path := range m.exportPaths {
+		fmt.Fprintf(&b, "  exported %s\n", path)
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// The renderer draws frames no faster than the viewport updates while lines
+// stream in, within these bounds so keys stay responsive.
+const (
+	minFrameRate = 20
+	maxFrameRate = 60
+)
+
+// syncOutputTerms are TERM and TERM_PROGRAM values of terminals known to
+// support synchronized updates (mode 2026).
+var syncOutputTerms = []string{"kitty", "alacritty", "foot", "ghostty", "wezterm", "contour", "iterm.app", "vscode"}
+
+// ProgramOptions returns renderer options for the terminal on stdout: a frame
+// rate matching the render interval, and synchronized output when the
+// terminal supports it or the config turns it on.
+func (m Model) ProgramOptions() []tea.ProgramOption {
+	opts := []tea.ProgramOption{tea.WithFPS(m.frameRate())}
+	enabled := supportsSyncOutput(os.Getenv)
+	if m.syncOutput != nil {
+		enabled = *m.syncOutput
+	}
+	if enabled {
+		opts = append(opts, tea.WithOutput(syncWriter{os.Stdout}))
+	}
+	return opts
+}
+
+// frameRate returns how many frames per second the renderer may draw. Frames
+// between viewport updates would repaint the same log, so there is no point
+// drawing them.
+func (m *Model) frameRate() int {
+	_, idle := m.renderIntervals()
+	return min(max(int(time.Second/idle), minFrameRate), maxFrameRate)
+}
+
+// supportsSyncOutput guesses from the environment whether the terminal
+// supports synchronized updates. Multiplexers are left out, since what
+// reaches the outer terminal depends on their version and setup. Terminals
+// without support ignore the escapes, so a wrong guess only costs bytes.
+func supportsSyncOutput(getenv func(string) string) bool {
+	if getenv("TMUX") != "" || strings.HasPrefix(getenv("TERM"), "screen") {
+		return false
+	}
+	if getenv("KITTY_WINDOW_ID") != "" || getenv("WEZTERM_EXECUTABLE") != "" || getenv("WT_SESSION") != "" {
+		return true
+	}
+	term := strings.ToLower(getenv("TERM"))
+	program := strings.ToLower(getenv("TERM_PROGRAM"))
+	for _, name := range syncOutputTerms {
+		if strings.Contains(term, name) || program == name {
+			return true
+		}
+	}
+	return false
+}
+
+// syncWriter wraps each write in synchronized update escapes. The renderer
+// writes a frame at once, so the terminal shows whole frames instead of
+// painting them line by line, which flickers on fast streams. It embeds the
+// file so the program still sees a terminal to size and put in raw mode.
+type syncWriter struct {
+	*os.File
+}
+
+func (w syncWriter) Write(p []byte) (int, error) {
+	frame := make([]byte, 0, len(ansi.SetSynchronizedOutputMode)+len(p)+len(ansi.ResetSynchronizedOutputMode))
+	frame = append(frame, ansi.SetSynchronizedOutputMode...)
+	frame = append(frame, p...)
+	frame = append(frame, ansi.ResetSynchronizedOutputMode...)
+	if _, err := w.File.Write(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// testTag is the tag of the markers added for instrumented tests.
+const testTag = "instrument"
+
+// testResult is a test of a run and the marker added when it started.
+type testResult struct {
+	logcat.InstrumentStatus
+	start *logcat.Entry
+}
+
+// testRun is an `am instrument` run and the results of its tests, in run order.
+type testRun struct {
+	runner   string
+	cmd      *exec.Cmd
+	statuses <-chan logcat.InstrumentStatus
+	done     <-chan error
+	results  []*testResult
+	total    int
+	running  bool
+	stopped  bool
+	err      error
+}
+
+type testsStartedMsg struct {
+	runner   string
+	cmd      *exec.Cmd
+	statuses <-chan logcat.InstrumentStatus
+	done     <-chan error
+}
+
+type testStatusMsg logcat.InstrumentStatus
+
+type testsDoneMsg struct{ err error }
+
+// startTests runs the app's instrumented tests with the runner installed for it.
+func (m *Model) startTests() tea.Cmd {
+	if m.tests != nil && m.tests.running {
+		m.statusMessage = "tests are already running (x: stop)"
+		return nil
+	}
+	if m.importName != "" || m.multiSource() {
+		m.statusMessage = "test runs need a single device"
+		return nil
+	}
+	if m.appID == "" {
+		m.statusMessage = "test runs need an app (--app)"
+		return nil
+	}
+	m.tests = &testRun{running: true}
+	m.testsIndex = 0
+	manager, appID := m.logManager, m.appID
+	return func() tea.Msg {
+		runner, err := manager.InstrumentationRunner(appID)
+		if err != nil {
+			return testsDoneMsg{err}
+		}
+		cmd, output, err := manager.StartInstrumentation(runner)
+		if err != nil {
+			return testsDoneMsg{err}
+		}
+		statuses := make(chan logcat.InstrumentStatus)
+		done := make(chan error, 1)
+		go func() {
+			err := logcat.ScanInstrumentation(output, func(status logcat.InstrumentStatus) {
+				statuses <- status
+			})
+			if waitErr := cmd.Wait(); err == nil {
+				err = waitErr
+			}
+			done <- err
+			close(statuses)
+		}()
+		return testsStartedMsg{runner: runner, cmd: cmd, statuses: statuses, done: done}
+	}
+}
+
+func waitForTestStatus(statuses <-chan logcat.InstrumentStatus, done <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		status, ok := <-statuses
+		if !ok {
+			return testsDoneMsg{<-done}
+		}
+		return testStatusMsg(status)
+	}
+}
+
+// testsStarted records the started run and marks its start in the log.
+func (m *Model) testsStarted(msg testsStartedMsg) tea.Cmd {
+	run := m.tests
+	run.runner, run.cmd, run.statuses, run.done = msg.runner, msg.cmd, msg.statuses, msg.done
+	m.appendMarker(logcat.Info, testTag, "test run started: "+msg.runner)
+	m.statusMessage = "running tests (i: results)"
+	return waitForTestStatus(run.statuses, run.done)
+}
+
+// recordTestStatus adds a marker when a test starts and, when it fails,
+// bookmarks its start and failure so J/K steps through failing tests.
+func (m *Model) recordTestStatus(status logcat.InstrumentStatus) {
+	run := m.tests
+	if status.Total > 0 {
+		run.total = status.Total
+	}
+	if status.Code == logcat.TestStarted {
+		start := m.appendMarker(logcat.Info, testTag, "started: "+status.Name())
+		run.results = append(run.results, &testResult{InstrumentStatus: status, start: start})
+		return
+	}
+
+	var result *testResult
+	for i := len(run.results) - 1; i >= 0; i-- {
+		if run.results[i].Name() == status.Name() {
+			result = run.results[i]
+			break
+		}
+	}
+	if result == nil {
+		result = &testResult{}
+		run.results = append(run.results, result)
+	}
+	result.InstrumentStatus = status
+	if status.Code == logcat.TestFailed || status.Code == logcat.TestErrored {
+		reason, _, _ := strings.Cut(status.Stack, "\n")
+		failure := m.appendMarker(logcat.Error, testTag, "failed: "+status.Name()+": "+reason)
+		if result.start != nil {
+			m.markImportant(result.start)
+		}
+		m.markImportant(failure)
+	}
+}
+
+// finishTests marks the end of the run in the log.
+func (m *Model) finishTests(err error) {
+	run := m.tests
+	if run == nil {
+		return
+	}
+	run.running = false
+	if run.stopped {
+		err = nil
+	}
+	run.err = err
+
+	passed, failed := run.counts()
+	text := fmt.Sprintf("test run finished: %d passed, %d failed", passed, failed)
+	switch {
+	case err != nil:
+		text = "test run failed: " + err.Error()
+	case run.stopped:
+		text = fmt.Sprintf("test run stopped: %d passed, %d failed", passed, failed)
+	}
+	priority := logcat.Info
+	if err != nil || failed > 0 {
+		priority = logcat.Error
+	}
+	if run.runner != "" {
+		m.appendMarker(priority, testTag, text)
+	}
+	m.statusMessage = text
+}
+
+// stopTests kills the run; am instrument stops with the adb shell it runs in.
+func (m *Model) stopTests() {
+	run := m.tests
+	if run == nil || !run.running || run.cmd == nil {
+		return
+	}
+	run.stopped = true
+	_ = run.cmd.Process.Kill()
+}
+
+// counts returns the number of passed and failed tests.
+func (r *testRun) counts() (passed, failed int) {
+	for _, result := range r.results {
+		switch result.Code {
+		case logcat.TestPassed:
+			passed++
+		case logcat.TestFailed, logcat.TestErrored:
+			failed++
+		}
+	}
+	return passed, failed
+}
+
+// testSymbol returns the symbol and color for a test's status.
+func testSymbol(code int) (string, lipgloss.TerminalColor) {
+	switch code {
+	case logcat.TestPassed:
+		return "✓", GetInfoColor()
+	case logcat.TestFailed, logcat.TestErrored:
+		return "✗", GetErrorColor()
+	case logcat.TestIgnored, logcat.TestAssumptionFailed:
+		return "-", GetUnknownColor()
+	}
+	return "…", GetAccentColor()
+}
+
+// testsView renders the test run summary and its tests in run order.
+func (m *Model) testsView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(GetAccentColor())
+	itemStyle := lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle := itemStyle.Foreground(GetAccentColor()).Bold(true)
+	detailStyle := lipgloss.NewStyle().PaddingLeft(4).Foreground(lipgloss.Color("245"))
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	lines := []string{titleStyle.Render("Tests")}
+	run := m.tests
+	switch {
+	case run == nil:
+		lines = append(lines, itemStyle.Render("no test run yet"))
+	default:
+		passed, failed := run.counts()
+		summary := fmt.Sprintf("%d/%d run, %d passed, %d failed", len(run.results), run.total, passed, failed)
+		switch {
+		case run.running && run.runner == "":
+			summary = "looking for the test runner..."
+		case run.running:
+			summary += " (running)"
+		case run.err != nil:
+			summary += ", " + run.err.Error()
+		case run.stopped:
+			summary += " (stopped)"
+		}
+		lines = append(lines, itemStyle.Render(truncate(summary, max(0, m.width-8))), "")
+
+		rows := max(1, m.height-12)
+		first := max(0, m.testsIndex-rows+1)
+		for i := first; i < len(run.results) && i < first+rows; i++ {
+			result := run.results[i]
+			symbol, color := testSymbol(result.Code)
+			nameStyle := lipgloss.NewStyle()
+			cursor := " "
+			if i == m.testsIndex {
+				cursor = "›"
+				nameStyle = selectedStyle.UnsetPaddingLeft()
+			}
+			line := fmt.Sprintf("%s %s %s", cursor, lipgloss.NewStyle().Foreground(color).Render(symbol), nameStyle.Render(truncate(result.Name(), max(0, m.width-12))))
+			lines = append(lines, itemStyle.Render(line))
+			if i == m.testsIndex && result.Stack != "" {
+				reason, _, _ := strings.Cut(result.Stack, "\n")
+				lines = append(lines, detailStyle.Render(truncate(reason, max(0, m.width-10))))
+			}
+		}
+	}
+	lines = append(lines, "", helpStyle.Render("r: run tests | x: stop | enter: show in log | j/k: move | J/K in the log: step through failures | esc: back"))
+
+	panelStyle := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		Padding(1, 2).
+		Width(m.width)
+
+	return "\n" + panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// handleTestsKey handles keys while the tests panel is open.
+func (m *Model) handleTestsKey(key string) tea.Cmd {
+	switch key {
+	case "esc", "i":
+		m.showTests = false
+	case "r":
+		return m.startTests()
+	case "x":
+		m.stopTests()
+	case "j", "down":
+		if m.tests != nil && m.testsIndex < len(m.tests.results)-1 {
+			m.testsIndex++
+		}
+	case "k", "up":
+		if m.testsIndex > 0 {
+			m.testsIndex--
+		}
+	case "enter":
+		if m.tests != nil && m.testsIndex < len(m.tests.results) {
+			if start := m.tests.results[m.testsIndex].start; start != nil {
+				m.showTests = false
+				m.jumpToEntry(start)
+			}
+		}
+	}
+	return nil
+}
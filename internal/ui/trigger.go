@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mikaelreiersolmoen/logdog/internal/config"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// Context saved around a trigger match when the config gives no counts.
+const (
+	defaultTriggerBefore = 500
+	defaultTriggerAfter  = 100
+)
+
+// trigger saves the entries around each entry matching a pattern to a file,
+// so unattended runs keep the context of a crash after it leaves the buffer.
+type trigger struct {
+	pattern *regexp.Regexp
+	before  int
+	after   int
+	name    string
+	dir     string
+}
+
+// capture is a trigger match waiting for the entries that follow it.
+type capture struct {
+	trigger *trigger
+	entries []*logcat.Entry
+	// remaining counts the following entries still to be collected
+	remaining int
+}
+
+// newTriggers builds the triggers from config, skipping rules with invalid patterns.
+func newTriggers(prefs []config.TriggerPreference) []*trigger {
+	var triggers []*trigger
+	for _, pref := range prefs {
+		if pref.Pattern == "" {
+			continue
+		}
+		regex, err := regexp.Compile("(?i)" + pref.Pattern)
+		if err != nil {
+			continue
+		}
+		t := &trigger{pattern: regex, before: pref.Before, after: pref.After, name: pref.Name, dir: pref.Dir}
+		if t.before <= 0 {
+			t.before = defaultTriggerBefore
+		}
+		if t.after <= 0 {
+			t.after = defaultTriggerAfter
+		}
+		if t.name == "" {
+			t.name = "trigger"
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers
+}
+
+// checkTriggers adds a new entry to the pending captures, saving those that
+// are complete, and starts a capture when it matches a trigger. A match while
+// the trigger's previous capture is still collecting extends that capture
+// rather than starting another file. Imported logs are left alone, so
+// opening a saved crash doesn't save it again.
+func (m *Model) checkTriggers(entry *logcat.Entry) {
+	if len(m.triggers) == 0 || m.importName != "" {
+		return
+	}
+	pending := m.captures[:0]
+	collecting := make(map[*trigger]*capture, len(m.captures))
+	for _, c := range m.captures {
+		c.entries = append(c.entries, entry)
+		c.remaining--
+		if c.remaining <= 0 {
+			m.saveCapture(c)
+			continue
+		}
+		pending = append(pending, c)
+		collecting[c.trigger] = c
+	}
+	m.captures = pending
+
+	for _, t := range m.triggers {
+		if !t.pattern.MatchString(entry.Message) && !t.pattern.MatchString(entry.Tag) {
+			continue
+		}
+		if c, ok := collecting[t]; ok {
+			c.remaining = t.after
+			continue
+		}
+		c := &capture{trigger: t, entries: m.precedingEntries(t.before), remaining: t.after}
+		c.entries = append(c.entries, entry)
+		m.captures = append(m.captures, c)
+	}
+}
+
+// precedingEntries returns up to n of the newest entries in the log,
+// including those held back while frozen.
+func (m *Model) precedingEntries(n int) []*logcat.Entry {
+	held := m.heldEntries[max(0, len(m.heldEntries)-n):]
+	n -= len(held)
+	parsed := m.parsedEntries[max(0, len(m.parsedEntries)-n):]
+	entries := make([]*logcat.Entry, 0, len(parsed)+len(held)+1)
+	entries = append(entries, parsed...)
+	return append(entries, held...)
+}
+
+// flushCaptures saves the pending captures with the entries collected so
+// far, e.g. when logging stops before a crash's following entries arrive.
+func (m *Model) flushCaptures() {
+	for _, c := range m.captures {
+		m.saveCapture(c)
+	}
+	m.captures = nil
+}
+
+// saveCapture writes a capture's raw lines to a new timestamped file, so it
+// can be opened again with --file.
+func (m *Model) saveCapture(c *capture) {
+	path, err := writeNewFile(c.trigger.dir, c.trigger.name, "log", []byte(m.exportAs(rawExporter(), c.entries)))
+	if err != nil {
+		m.statusMessage = "trigger export failed: " + err.Error()
+		return
+	}
+	m.exportPaths = append(m.exportPaths, path)
+	m.statusMessage = fmt.Sprintf("%s: saved %d entries to %s", c.trigger.name, len(c.entries), path)
+}
+
+// writeNewFile writes data to name-<timestamp>.extension in dir, adding a
+// counter when a file by that name already exists, and returns the path.
+func writeNewFile(dir, name, extension string, data []byte) (string, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+	}
+	base := name + "-" + time.Now().Format("20060102-150405")
+	for n := 1; ; n++ {
+		file := base
+		if n > 1 {
+			file += "-" + strconv.Itoa(n)
+		}
+		path := filepath.Join(dir, file+"."+extension)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
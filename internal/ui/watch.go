@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// watch counts the entries whose message matches a regex, shown in the header.
+type watch struct {
+	label string
+	re    *regexp.Regexp
+	count int
+}
+
+// parseWatch parses "label=regex"; a bare regex is its own label.
+func parseWatch(text string) (label, pattern string) {
+	label, pattern, ok := strings.Cut(text, "=")
+	if !ok {
+		return text, text
+	}
+	return strings.TrimSpace(label), pattern
+}
+
+// setWatches compiles the configured watches, skipping invalid ones.
+func (m *Model) setWatches(specs []string) {
+	m.watches = nil
+	for _, spec := range specs {
+		_ = m.addWatch(spec)
+	}
+}
+
+// addWatch adds a watch from "label=regex", replacing one with the same label,
+// and counts the entries already in the log. "label=" removes the watch.
+func (m *Model) addWatch(spec string) error {
+	label, pattern := parseWatch(spec)
+	if label == "" {
+		return fmt.Errorf("watch needs a label or pattern")
+	}
+	index := -1
+	for i, w := range m.watches {
+		if w.label == label {
+			index = i
+			break
+		}
+	}
+	if pattern == "" {
+		if index >= 0 {
+			m.watches = append(m.watches[:index], m.watches[index+1:]...)
+		}
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	w := &watch{label: label, re: re}
+	for _, entry := range m.parsedEntries {
+		if re.MatchString(entry.Message) {
+			w.count++
+		}
+	}
+	if index >= 0 {
+		m.watches[index] = w
+	} else {
+		m.watches = append(m.watches, w)
+	}
+	return nil
+}
+
+// countWatches counts a new entry against each watch.
+func (m *Model) countWatches(entry *logcat.Entry) {
+	for _, w := range m.watches {
+		if w.re.MatchString(entry.Message) {
+			w.count++
+		}
+	}
+}
+
+// watchSpecs returns the watches as "label=regex" for the config.
+func (m *Model) watchSpecs() []string {
+	specs := make([]string, 0, len(m.watches))
+	for _, w := range m.watches {
+		specs = append(specs, w.label+"="+w.re.String())
+	}
+	return specs
+}
+
+// watchInfo renders the watch counts for the header, e.g. "retries: 14".
+func (m *Model) watchInfo() string {
+	countStyle := lipgloss.NewStyle().Foreground(GetAccentColor())
+	parts := make([]string, 0, len(m.watches))
+	for _, w := range m.watches {
+		parts = append(parts, fmt.Sprintf("%s: %s", w.label, countStyle.Render(fmt.Sprint(w.count))))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// startWatchPrompt opens the footer prompt for adding or removing a watch.
+func (m *Model) startWatchPrompt() {
+	if m.watchInput.Placeholder == "" {
+		m.watchInput = textinput.New()
+		m.watchInput.Placeholder = "label=regex, e.g. retries=retry(ing)?"
+		m.watchInput.CharLimit = 500
+		m.watchInput.Width = 80
+	}
+	m.watchInput.SetValue("")
+	m.watchInput.Focus()
+	m.showWatchInput = true
+}
+
+// applyWatchPrompt adds or removes the watch entered in the prompt.
+func (m *Model) applyWatchPrompt() {
+	m.showWatchInput = false
+	m.watchInput.Blur()
+	spec := strings.TrimSpace(m.watchInput.Value())
+	if spec == "" {
+		return
+	}
+	if err := m.addWatch(spec); err != nil {
+		m.statusMessage = "invalid watch: " + err.Error()
+	}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"net/url"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mikaelreiersolmoen/logdog/internal/logcat"
+)
+
+// defaultSearchURL is used when the config has no searchURL; %s is replaced by the query.
+const defaultSearchURL = "https://www.google.com/search?q=%s"
+
+// maxSearchQuery caps the query length; search engines ignore long queries anyway.
+const maxSearchQuery = 200
+
+// webSearchMsg reports whether the browser could be opened.
+type webSearchMsg struct{ err error }
+
+// volatileTokens matches hex addresses and hash codes that differ between
+// runs and only make a search miss, e.g. "0x7f3a2c" or "@4f1b2a9".
+var volatileTokens = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|@[0-9a-fA-F]{6,}\b`)
+
+// searchQuery returns the search text for an entry: the first line of its
+// message, e.g. the exception and its message, without volatile tokens.
+func searchQuery(entry *logcat.Entry) string {
+	query, _, _ := strings.Cut(entry.Message, "\n")
+	query = volatileTokens.ReplaceAllString(query, "")
+	query = strings.Join(strings.Fields(query), " ")
+	return truncate(query, maxSearchQuery)
+}
+
+// searchURL fills the configured URL template with the query.
+func (m *Model) searchURL(query string) string {
+	template := m.searchURLTemplate
+	if template == "" {
+		template = defaultSearchURL
+	}
+	return strings.Replace(template, "%s", url.QueryEscape(query), 1)
+}
+
+// webSearch opens a browser search for text, redacted when redaction is on.
+func (m *Model) webSearch(text string) tea.Cmd {
+	query := m.redactText(text)
+	if query == "" {
+		m.statusMessage = "nothing to search for"
+		return nil
+	}
+	m.statusMessage = "searching the web for " + query
+	target := m.searchURL(query)
+	return func() tea.Msg {
+		return webSearchMsg{openURL(target)}
+	}
+}
+
+// openURL opens target in the default browser.
+func openURL(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Run()
+}
@@ -1,11 +1,20 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mikaelreiersolmoen/logdog/internal/adb"
@@ -17,51 +26,170 @@ import (
 func main() {
 	var appID string
 	var tailValue string
+	var deviceMatch adb.DeviceMatch
+	var pidCheckInterval, pidPollInterval time.Duration
+	var bench, fresh bool
+	var cpuProfile, memProfile string
+	var importPath, importFormat string
+	var filePath string
+	var execCommand string
+	benchOpts := ui.DefaultBenchOptions()
 	defaultTailValue := resolveDefaultTailValue()
+	defaultCheckInterval, defaultPollInterval := resolveDefaultPIDIntervals()
 	flag.StringVar(&appID, "app", "", "Application ID to filter logcat logs (optional)")
 	flag.StringVar(&appID, "a", "", "Application ID to filter logcat logs (shorthand)")
 	flag.StringVar(&tailValue, "tail", defaultTailValue, "Number of recent log entries to load initially (0 = none, all = all)")
 	flag.StringVar(&tailValue, "t", defaultTailValue, "Number of recent log entries to load initially (shorthand, 0 = none, all = all)")
+	flag.BoolVar(&fresh, "fresh", false, "Skip the log backlog and only show lines from now on (same as --tail 0)")
+	flag.DurationVar(&pidCheckInterval, "pid-check-interval", defaultCheckInterval, "How often to check that the filtered app is still running")
+	flag.DurationVar(&pidPollInterval, "pid-poll-interval", defaultPollInterval, "How often to look for the filtered app after it stops")
+	flag.StringVar(&deviceMatch.Query, "device", "", "Device serial or model/AVD name substring to use without prompting")
+	flag.StringVar(&deviceMatch.Query, "s", "", "Device serial or model/AVD name substring (shorthand)")
+	flag.BoolVar(&deviceMatch.USB, "usb", false, "Use the USB-connected device")
+	flag.BoolVar(&deviceMatch.USB, "d", false, "Use the USB-connected device (shorthand)")
+	flag.BoolVar(&deviceMatch.Emulator, "emulator", false, "Use the running emulator")
+	flag.BoolVar(&deviceMatch.Emulator, "e", false, "Use the running emulator (shorthand)")
+	flag.StringVar(&importPath, "import", "", "Show a stack trace (e.g. from Crashlytics or Play Console) or saved log from this file, or - for stdin, without a device. A permalink (file#entry-N) opens the file at that entry")
+	flag.StringVar(&filePath, "file", "", "Read a saved logcat file (e.g. from adb logcat -d, format detected) instead of a device")
+	flag.StringVar(&importFormat, "format", "", "Log format of --import: "+strings.Join(logcat.FormatNames(), ", ")+" (default: detected)")
+	flag.StringVar(&execCommand, "exec", "", "Run this command (e.g. \"./gradlew installDebug\") alongside the log, showing its output in a pane and marking its start and end in the log")
+	flag.BoolVar(&bench, "bench", false, "Replay a synthetic high-volume stream headlessly and report parse and render performance")
+	flag.IntVar(&benchOpts.Lines, "bench-lines", benchOpts.Lines, "Number of synthetic lines to replay with --bench")
+	flag.IntVar(&benchOpts.Rate, "bench-rate", benchOpts.Rate, "Simulated stream rate in lines per second for --bench")
+	flag.BoolVar(&benchOpts.Wrap, "bench-wrap", false, "Render with line wrapping in --bench")
+	flag.StringVar(&cpuProfile, "cpuprofile", "", "Write a CPU profile to this file")
+	flag.StringVar(&memProfile, "memprofile", "", "Write a heap profile to this file on exit")
 	flag.Parse()
 
+	if bench {
+		os.Exit(runBench(benchOpts, cpuProfile, memProfile))
+	}
+
+	if deviceMatch.USB && deviceMatch.Emulator {
+		fmt.Fprintln(os.Stderr, "Error: --usb and --emulator are mutually exclusive")
+		os.Exit(2)
+	}
+	deviceMatch = deviceMatch.WithEnvDefault()
+
+	if fresh {
+		tailValue = "0"
+	}
 	tailSize, err := parseTailSize(tailValue)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(2)
 	}
 
+	if pidCheckInterval <= 0 || pidPollInterval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: PID monitor intervals must be positive")
+		os.Exit(2)
+	}
+	logcat.SetPIDMonitorIntervals(pidCheckInterval, pidPollInterval)
+
 	if err := config.EnsureExists(); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to initialize preferences: %v\n", err)
 	}
 
-	// Validate connectivity before starting UI (only if app filtering is requested and single device)
-	if appID != "" {
-		// Check device count first
-		devices, err := adb.GetDevices()
+	if importFormat != "" {
+		if importPath == "" {
+			fmt.Fprintln(os.Stderr, "Error: --format only applies to --import")
+			os.Exit(2)
+		}
+		if _, err := logcat.FormatNamed(importFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	if execCommand != "" && importPath != "" {
+		fmt.Fprintln(os.Stderr, "Error: --exec can't be combined with --import")
+		os.Exit(2)
+	}
+
+	if filePath != "" {
+		switch {
+		case importPath != "":
+			fmt.Fprintln(os.Stderr, "Error: --file can't be combined with --import")
+			os.Exit(2)
+		case appID != "":
+			// A saved file has no device to look the app's PIDs up on
+			fmt.Fprintln(os.Stderr, "Error: --app can't be combined with --file")
+			os.Exit(2)
+		case execCommand != "":
+			fmt.Fprintln(os.Stderr, "Error: --exec can't be combined with --file")
+			os.Exit(2)
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		ui.SetFile(filePath)
+	}
+
+	if importPath == "" && filePath == "" {
+		if orphans := logcat.FindOrphans(); len(orphans) > 0 {
+			offerOrphanCleanup(orphans)
+		}
+	}
+
+	programOpts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if importPath != "" {
+		// A permalink such as capture.log#entry-42 opens the file at that entry
+		entry := 0
+		if path, number, ok := ui.SplitPermalink(importPath); ok {
+			if _, err := os.Stat(importPath); err != nil {
+				importPath, entry = path, number
+			}
+		}
+		name, text, err := readImport(importPath)
+		if err == nil && strings.TrimSpace(text) == "" {
+			err = fmt.Errorf("nothing to import from %s", name)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		ui.SetImport(name, text, importFormat)
+		ui.SetImportEntry(entry)
+		if importPath == "-" {
+			// stdin held the trace, so keys come from the terminal
+			programOpts = append(programOpts, tea.WithInputTTY())
+		}
+	}
+
+	// Validate connectivity before starting UI (only if app filtering or device preselection is requested)
+	if importPath == "" && filePath == "" && (appID != "" || !deviceMatch.IsZero()) {
+		// Check device count first
+		devices, err := adb.GetDevices()
+		if err == nil {
+			devices, err = deviceMatch.Filter(devices)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
 
 		// Only validate if single device (multi-device validation happens after selection)
-		if len(devices) == 1 {
+		if appID != "" && len(devices) == 1 {
 			logManager := logcat.NewManager(appID, tailSize)
 			logManager.SetDevice(devices[0].Serial)
-			if err := logManager.Start(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+			// A stopped app is not fatal: the UI waits for it to start
+			if err := logManager.Start(); err != nil && !errors.Is(err, adb.ErrAppNotRunning) {
+				exitWithError(err)
 			}
 			logManager.Stop()
 		}
 	}
 
+	if execCommand != "" {
+		ui.SetExec(execCommand)
+	}
+	ui.SetDeviceMatch(deviceMatch)
 	m := ui.NewModel(appID, tailSize)
 
-	p := tea.NewProgram(
-		m,
-		tea.WithAltScreen(),
-		tea.WithMouseCellMotion(),
-	)
+	programOpts = append(programOpts, m.ProgramOptions()...)
+	programOpts = append(programOpts, tea.WithoutSignalHandler())
+	p := tea.NewProgram(m, programOpts...)
+	go forwardSignals(p)
 
 	finalModel, err := p.Run()
 	if err != nil {
@@ -69,8 +197,9 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Persist preferences and report any final error message
+	// Stop logging, persist preferences and report any final error message or the session summary
 	if finalModel, ok := finalModel.(ui.Model); ok {
+		finalModel.Close()
 		if err := finalModel.PersistPreferences(); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to save preferences: %v\n", err)
 		}
@@ -78,7 +207,115 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", finalModel.ErrorMessage())
 			os.Exit(1)
 		}
+		fmt.Print(finalModel.Summary())
+	}
+}
+
+// forwardSignals lets the UI stop adb and restore the terminal on SIGINT,
+// SIGTERM and SIGHUP, e.g. when the terminal window is closed. SIGINT is left
+// to a pager or editor while it has the terminal, as Bubble Tea's own handler
+// does. A second signal kills the program outright.
+func forwardSignals(p *tea.Program) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	sent := false
+	for sig := range sigs {
+		if sig == os.Interrupt && ui.ExternalRunning() {
+			continue
+		}
+		if sent {
+			p.Kill()
+			return
+		}
+		p.Send(ui.SignalMsg{Signal: sig})
+		sent = true
+	}
+}
+
+// offerOrphanCleanup reports adb logcat processes left running by a logdog
+// that died, and kills them if the user agrees.
+func offerOrphanCleanup(orphans []logcat.Orphan) {
+	pids := make([]string, len(orphans))
+	for i, orphan := range orphans {
+		pids[i] = strconv.Itoa(orphan.PID)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d adb logcat process(es) left running by an earlier logdog: %s\n", len(orphans), strings.Join(pids, " "))
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintf(os.Stderr, "Stop them with: kill %s\n", strings.Join(pids, " "))
+		return
+	}
+	fmt.Fprint(os.Stderr, "Kill them? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+		return
+	}
+	if err := logcat.KillOrphans(orphans); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to kill orphaned logcat: %v\n", err)
+	}
+}
+
+// readImport reads the text to import from a file, or from stdin for "-".
+func readImport(path string) (string, string, error) {
+	if path == "-" {
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprintln(os.Stderr, "Paste the stack trace, then press Ctrl-D:")
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", "", fmt.Errorf("read stdin: %w", err)
+		}
+		return "stdin", string(data), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("read import: %w", err)
+	}
+	return filepath.Base(path), string(data), nil
+}
+
+// runBench runs the headless benchmark with optional pprof profiles and returns the exit code.
+func runBench(opts ui.BenchOptions, cpuProfile, memProfile string) int {
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	if err := ui.RunBench(opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if memProfile != "" {
+		f, err := os.Create(memProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
 	}
+	return 0
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if hint := adb.Hint(err); hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+	os.Exit(1)
 }
 
 func parseTailSize(value string) (int, error) {
@@ -109,3 +346,19 @@ func resolveDefaultTailValue() string {
 
 	return strconv.Itoa(prefs.TailSize)
 }
+
+func resolveDefaultPIDIntervals() (time.Duration, time.Duration) {
+	check, poll := logcat.DefaultPIDCheckInterval, logcat.DefaultPIDPollInterval
+	prefs, exists, err := config.Load()
+	if err != nil || !exists {
+		return check, poll
+	}
+
+	if prefs.PIDCheckIntervalMs > 0 {
+		check = time.Duration(prefs.PIDCheckIntervalMs) * time.Millisecond
+	}
+	if prefs.PIDPollIntervalMs > 0 {
+		poll = time.Duration(prefs.PIDPollIntervalMs) * time.Millisecond
+	}
+	return check, poll
+}